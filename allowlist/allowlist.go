@@ -2,6 +2,7 @@
 package allowlist
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,8 +12,13 @@ import (
 
 // List IP's allowlisted for an app.
 func List(c *drycc.Client, appID string) (api.Allowlist, error) {
+	return ListWithContext(context.Background(), c, appID)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string) (api.Allowlist, error) {
 	u := fmt.Sprintf("/v2/apps/%s/allowlist/", appID)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Allowlist{}, reqErr
 	}
@@ -28,6 +34,11 @@ func List(c *drycc.Client, appID string) (api.Allowlist, error) {
 
 // Add adds addresses to an app's allowlist.
 func Add(c *drycc.Client, appID string, addresses []string) (api.Allowlist, error) {
+	return AddWithContext(context.Background(), c, appID, addresses)
+}
+
+// AddWithContext is like [Add] but cancels the request when ctx is done.
+func AddWithContext(ctx context.Context, c *drycc.Client, appID string, addresses []string) (api.Allowlist, error) {
 	u := fmt.Sprintf("/v2/apps/%s/allowlist/", appID)
 
 	req := api.Allowlist{Addresses: addresses}
@@ -35,7 +46,7 @@ func Add(c *drycc.Client, appID string, addresses []string) (api.Allowlist, erro
 	if err != nil {
 		return api.Allowlist{}, err
 	}
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Allowlist{}, reqErr
 	}
@@ -51,6 +62,11 @@ func Add(c *drycc.Client, appID string, addresses []string) (api.Allowlist, erro
 
 // Delete removes addresses from an app's allowlist.
 func Delete(c *drycc.Client, appID string, addresses []string) error {
+	return DeleteWithContext(context.Background(), c, appID, addresses)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, addresses []string) error {
 	u := fmt.Sprintf("/v2/apps/%s/allowlist/", appID)
 
 	req := api.Allowlist{Addresses: addresses}
@@ -59,7 +75,7 @@ func Delete(c *drycc.Client, appID string, addresses []string) error {
 		return err
 	}
 
-	_, reqErr := c.Request("DELETE", u, body)
+	_, reqErr := c.RequestWithContext(ctx, "DELETE", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -1,6 +1,11 @@
 // Package api provides data structures for the Drycc Controller API.
 package api
 
+import (
+	"strings"
+	"time"
+)
+
 // App is the definition of the app object.
 type App struct {
 	Created string `json:"created"`
@@ -42,3 +47,56 @@ type AppLogsRequest struct {
 	Follow  bool `json:"follow"`
 	Timeout int  `json:"timeout"`
 }
+
+// LogLine is a line of the logs streamed by the controller, in the format
+// "<timestamp> <pod>[<container>]: <message>". The timestamp and the container are
+// optional. Lines that don't match this format have their whole text as Message.
+type LogLine struct {
+	Timestamp time.Time
+	Pod       string
+	Container string
+	Message   string
+}
+
+// ParseLogLine parses a line of logs.
+func ParseLogLine(line string) LogLine {
+	line = strings.TrimSuffix(line, "\r")
+	l := LogLine{}
+	if first, rest, ok := strings.Cut(line, " "); ok {
+		if ts, err := time.Parse(time.RFC3339Nano, first); err == nil {
+			l.Timestamp, line = ts, rest
+		}
+	}
+	source, message, ok := strings.Cut(line, ": ")
+	if !ok {
+		source, ok = strings.CutSuffix(line, ":")
+	}
+	if !ok || source == "" || strings.ContainsAny(source, " \t") {
+		l.Message = line
+		return l
+	}
+	if pod, container, ok := strings.Cut(source, "["); ok && strings.HasSuffix(container, "]") {
+		l.Pod, l.Container = pod, strings.TrimSuffix(container, "]")
+	} else {
+		l.Pod = source
+	}
+	l.Message = message
+	return l
+}
+
+func (l LogLine) String() string {
+	var b strings.Builder
+	if !l.Timestamp.IsZero() {
+		b.WriteString(l.Timestamp.Format(time.RFC3339Nano))
+		b.WriteString(" ")
+	}
+	if l.Pod != "" {
+		b.WriteString(l.Pod)
+		if l.Container != "" {
+			b.WriteString("[" + l.Container + "]")
+		}
+		b.WriteString(": ")
+	}
+	b.WriteString(l.Message)
+	return b.String()
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"sort"
 	"testing"
+	"time"
 )
 
 func TestAppsSorted(t *testing.T) {
@@ -22,3 +23,28 @@ func TestAppsSorted(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLogLine(t *testing.T) {
+	ts := time.Date(2024, 5, 21, 2, 27, 3, 123000000, time.UTC)
+	tests := []struct {
+		line     string
+		expected LogLine
+	}{
+		{"2024-05-21T02:27:03.123Z example-go-web-1[web]: listening on :8000",
+			LogLine{Timestamp: ts, Pod: "example-go-web-1", Container: "web", Message: "listening on :8000"}},
+		{"example-go-web-1: started", LogLine{Pod: "example-go-web-1", Message: "started"}},
+		{"2024-05-21T02:27:03.123Z plain message: with colon",
+			LogLine{Timestamp: ts, Message: "plain message: with colon"}},
+		{"example-go-web-1[web]:\r", LogLine{Pod: "example-go-web-1", Container: "web"}},
+		{"", LogLine{}},
+	}
+	for _, test := range tests {
+		actual := ParseLogLine(test.line)
+		if actual != test.expected {
+			t.Errorf("Expected %v, Got %v", test.expected, actual)
+		}
+	}
+	if expected := "2024-05-21T02:27:03.123Z example-go-web-1[web]: ok"; ParseLogLine(expected).String() != expected {
+		t.Errorf("Expected %v, Got %v", expected, ParseLogLine(expected).String())
+	}
+}
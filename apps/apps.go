@@ -2,8 +2,10 @@
 package apps
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,7 +13,12 @@ import (
 
 // List lists apps on a Drycc controller.
 func List(c *drycc.Client, results int) (api.Apps, int, error) {
-	body, count, reqErr := c.LimitedRequest("/v2/apps/", results)
+	return ListWithContext(context.Background(), c, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, results int) (api.Apps, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, "/v2/apps/", results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.App{}, -1, reqErr
@@ -25,11 +32,26 @@ func List(c *drycc.Client, results int) (api.Apps, int, error) {
 	return apps, count, reqErr
 }
 
+// All returns an iterator over all apps on a Drycc controller, fetching pages lazily.
+func All(c *drycc.Client) iter.Seq2[api.App, error] {
+	return AllWithContext(context.Background(), c)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client) iter.Seq2[api.App, error] {
+	return drycc.Paginate[api.App](ctx, c, "/v2/apps/", drycc.DefaultPageSize)
+}
+
 // New creates a new app with the given appID and workspace.
 // Passing an empty appID will result in a randomized app name.
 //
 // If the app name already exists, the error drycc.ErrDuplicateApp will be returned.
 func New(c *drycc.Client, appID string, workspace string) (api.App, error) {
+	return NewWithContext(context.Background(), c, appID, workspace)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID string, workspace string) (api.App, error) {
 	body := []byte{}
 
 	req := api.AppCreateRequest{ID: appID, Workspace: workspace}
@@ -39,7 +61,7 @@ func New(c *drycc.Client, appID string, workspace string) (api.App, error) {
 	}
 	body = b
 
-	res, reqErr := c.Request("POST", "/v2/apps/", body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", "/v2/apps/", body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.App{}, reqErr
 	}
@@ -55,9 +77,14 @@ func New(c *drycc.Client, appID string, workspace string) (api.App, error) {
 
 // Get app details from a controller.
 func Get(c *drycc.Client, appID string) (api.App, error) {
+	return GetWithContext(context.Background(), c, appID)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string) (api.App, error) {
 	u := fmt.Sprintf("/v2/apps/%s/", appID)
 
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.App{}, reqErr
 	}
@@ -75,6 +102,11 @@ func Get(c *drycc.Client, appID string) (api.App, error) {
 // Run a one-time command in your app. This will start a kubernetes job with the
 // same container image and environment as the rest of the app.
 func Run(c *drycc.Client, appID string, command string, volumes map[string]any, timeout, expires uint32) error {
+	return RunWithContext(context.Background(), c, appID, command, volumes, timeout, expires)
+}
+
+// RunWithContext is like [Run] but cancels the request when ctx is done.
+func RunWithContext(ctx context.Context, c *drycc.Client, appID string, command string, volumes map[string]any, timeout, expires uint32) error {
 	req := api.AppRunRequest{
 		Command: command,
 		Volumes: volumes,
@@ -88,7 +120,7 @@ func Run(c *drycc.Client, appID string, command string, volumes map[string]any,
 
 	u := fmt.Sprintf("/v2/apps/%s/run", appID)
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
@@ -99,9 +131,14 @@ func Run(c *drycc.Client, appID string, command string, volumes map[string]any,
 
 // Delete an app.
 func Delete(c *drycc.Client, appID string) error {
+	return DeleteWithContext(context.Background(), c, appID)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string) error {
 	u := fmt.Sprintf("/v2/apps/%s/", appID)
 
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -110,6 +147,11 @@ func Delete(c *drycc.Client, appID string) error {
 
 // Transfer moves an app to another workspace.
 func Transfer(c *drycc.Client, appID string, workspace string) error {
+	return TransferWithContext(context.Background(), c, appID, workspace)
+}
+
+// TransferWithContext is like [Transfer] but cancels the request when ctx is done.
+func TransferWithContext(ctx context.Context, c *drycc.Client, appID string, workspace string) error {
 	u := fmt.Sprintf("/v2/apps/%s/", appID)
 
 	req := api.AppUpdateRequest{Workspace: workspace}
@@ -118,7 +160,7 @@ func Transfer(c *drycc.Client, appID string, workspace string) error {
 		return err
 	}
 
-	res, err := c.Request("PATCH", u, body)
+	res, err := c.RequestWithContext(ctx, "PATCH", u, body)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -1,6 +1,8 @@
 package apps
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -284,3 +286,52 @@ func TestAppsTransfer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAppsGetWithContext(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	drycc, err := drycc.New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = GetWithContext(context.Background(), drycc, "example-go"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err = GetWithContext(ctx, drycc, "example-go"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+}
+
+func TestAppsAll(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	drycc, err := drycc.New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual api.Apps
+	for app, err := range All(drycc) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual = append(actual, app)
+	}
+
+	if len(actual) != 1 || actual[0].ID != "example-go" {
+		t.Errorf("Expected [example-go], Got %v", actual)
+	}
+}
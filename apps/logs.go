@@ -0,0 +1,125 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/websocket"
+)
+
+// LogStream is a stream of the logs of an app, opened with [Logs].
+// Its methods are safe for concurrent use.
+type LogStream struct {
+	conn *websocket.Conn
+	ctx  context.Context
+	stop func() bool
+
+	closed atomic.Bool
+
+	mu      sync.Mutex
+	pending []string
+	partial string // the start of a line split across messages
+	err     error
+}
+
+// Logs streams the logs of every pod of an app. The number of lines fetched per pod is set
+// by request.Lines; with request.Follow, new lines are streamed as they are written, until
+// request.Timeout seconds have passed or the stream is closed.
+func Logs(c *drycc.Client, appID string, request api.AppLogsRequest) (*LogStream, error) {
+	return LogsWithContext(context.Background(), c, appID, request)
+}
+
+// LogsWithContext is like [Logs] but closes the stream when ctx is done. Unlike the other
+// WithContext functions, ctx bounds the whole stream and not only the websocket handshake.
+func LogsWithContext(ctx context.Context, c *drycc.Client, appID string, request api.AppLogsRequest) (*LogStream, error) {
+	path := fmt.Sprintf("v2/apps/%s/logs/", appID)
+	conn, err := c.DialWebsocket(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err = websocket.JSON.Send(conn, request); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s := &LogStream{conn: conn, ctx: ctx}
+	s.stop = context.AfterFunc(ctx, func() { conn.Close() })
+	return s, nil
+}
+
+// Next returns the next line of logs, waiting for it in follow mode. A line is returned
+// once its newline is received, or once the stream ends. It returns io.EOF once the
+// controller ends the stream or the stream is closed, and the context's error if it is done.
+func (s *LogStream) Next() (api.LogLine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.pending) == 0 {
+		if s.err != nil {
+			if s.partial == "" {
+				return api.LogLine{}, s.err
+			}
+			// The last line of the stream doesn't end with a newline.
+			s.pending, s.partial = []string{s.partial}, ""
+			break
+		}
+		var msg string
+		if err := websocket.Message.Receive(s.conn, &msg); err != nil {
+			s.err = s.streamErr(err)
+			continue
+		}
+		// The text after the last newline is kept until the rest of its line is received.
+		lines := strings.Split(s.partial+msg, "\n")
+		s.pending, s.partial = lines[:len(lines)-1], lines[len(lines)-1]
+	}
+	line := s.pending[0]
+	s.pending = s.pending[1:]
+	return api.ParseLogLine(line), nil
+}
+
+// streamErr maps the error receiving a message to the error returned by Next.
+func (s *LogStream) streamErr(err error) error {
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	if errors.Is(err, io.EOF) || s.closed.Load() {
+		return io.EOF
+	}
+	return err
+}
+
+// Lines returns an iterator over the lines of logs. The iteration ends without error when
+// Next returns io.EOF; any other error is yielded once before it ends. Breaking out of
+// the loop doesn't close the stream.
+func (s *LogStream) Lines() iter.Seq2[api.LogLine, error] {
+	return func(yield func(api.LogLine, error) bool) {
+		for {
+			line, err := s.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(api.LogLine{}, err)
+				return
+			}
+			if !yield(line, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Close closes the stream. A Next call waiting for a line returns io.EOF.
+func (s *LogStream) Close() error {
+	if s.closed.Swap(true) {
+		return nil
+	}
+	s.stop()
+	return s.conn.Close()
+}
@@ -0,0 +1,114 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"golang.org/x/net/websocket"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	received := make(chan api.AppLogsRequest, 1)
+	srv.HandleAppLogs(func(conn *websocket.Conn, appID string, request api.AppLogsRequest) {
+		received <- request
+		websocket.Message.Send(conn, "2024-05-21T02:27:03Z example-go-web-1[web]: started\n")
+		// A line may be split across messages, and the last one may not end with a newline.
+		websocket.Message.Send(conn, "example-go-web-2[web]: a\nexample-go-wor")
+		websocket.Message.Send(conn, "ker-1: b\nexample-go-worker-1: c")
+	})
+
+	request := api.AppLogsRequest{Lines: 10, Timeout: 60}
+	stream, err := Logs(srv.Client(), "example-go", request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	var lines []api.LogLine
+	for line, err := range stream.Lines() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, line)
+	}
+	expected := []api.LogLine{
+		{Timestamp: time.Date(2024, 5, 21, 2, 27, 3, 0, time.UTC), Pod: "example-go-web-1", Container: "web", Message: "started"},
+		{Pod: "example-go-web-2", Container: "web", Message: "a"},
+		{Pod: "example-go-worker-1", Message: "b"},
+		{Pod: "example-go-worker-1", Message: "c"},
+	}
+	if !reflect.DeepEqual(expected, lines) {
+		t.Errorf("Expected %v, Got %v", expected, lines)
+	}
+	if actual := <-received; actual != request {
+		t.Errorf("Expected %v, Got %v", request, actual)
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Expected %v, Got %v", io.EOF, err)
+	}
+}
+
+func TestLogsFollow(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	done := make(chan struct{})
+	srv.HandleAppLogs(func(conn *websocket.Conn, appID string, request api.AppLogsRequest) {
+		websocket.Message.Send(conn, "example-go-web-1: first\n")
+		// Keep following until the client goes away.
+		var msg string
+		websocket.Message.Receive(conn, &msg)
+		close(done)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := LogsWithContext(ctx, srv.Client(), "example-go", api.AppLogsRequest{Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	line, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line.Message != "first" {
+		t.Errorf("Expected %v, Got %v", "first", line.Message)
+	}
+
+	cancel()
+	if _, err := stream.Next(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+	<-done
+
+	// Closing the stream ends a follow cleanly.
+	stream, err = Logs(srv.Client(), "example-go", api.AppLogsRequest{Follow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Next(); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(10*time.Millisecond, func() { stream.Close() })
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Expected %v, Got %v", io.EOF, err)
+	}
+
+	if _, err := Logs(srv.Client(), "unknown", api.AppLogsRequest{}); err == nil {
+		t.Error("Expected an error for an unknown app")
+	}
+}
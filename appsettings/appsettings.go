@@ -2,6 +2,7 @@
 package appsettings
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,9 +12,14 @@ import (
 
 // List lists an app's settings.
 func List(c *drycc.Client, app string) (api.AppSettings, error) {
+	return ListWithContext(context.Background(), c, app)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, app string) (api.AppSettings, error) {
 	u := fmt.Sprintf("/v2/apps/%s/settings/", app)
 
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil {
 		return api.AppSettings{}, reqErr
 	}
@@ -37,6 +43,11 @@ func List(c *drycc.Client, app string) (api.AppSettings, error) {
 //
 // Calling Set() with an empty api.AppSettings will return a drycc.ErrConflict.
 func Set(c *drycc.Client, app string, appSettings api.AppSettings) (api.AppSettings, error) {
+	return SetWithContext(context.Background(), c, app, appSettings)
+}
+
+// SetWithContext is like [Set] but cancels the request when ctx is done.
+func SetWithContext(ctx context.Context, c *drycc.Client, app string, appSettings api.AppSettings) (api.AppSettings, error) {
 	body, err := json.Marshal(appSettings)
 	if err != nil {
 		return api.AppSettings{}, err
@@ -44,7 +55,7 @@ func Set(c *drycc.Client, app string, appSettings api.AppSettings) (api.AppSetti
 
 	u := fmt.Sprintf("/v2/apps/%s/settings/", app)
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil {
 		return api.AppSettings{}, reqErr
 	}
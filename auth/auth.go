@@ -2,9 +2,9 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -12,9 +12,13 @@ import (
 
 // Login to the controller and get a oauth url
 func Login(c *drycc.Client, username, password string) (string, error) {
-	c.HTTPClient.CheckRedirect = func(*http.Request, []*http.Request) error {
-		return http.ErrUseLastResponse
-	}
+	return LoginWithContext(context.Background(), c, username, password)
+}
+
+// LoginWithContext is like [Login] but cancels the request when ctx is done.
+func LoginWithContext(ctx context.Context, c *drycc.Client, username, password string) (string, error) {
+	// The oauth url is returned as a redirect, which must not be followed.
+	ctx = drycc.WithoutRedirect(ctx)
 	var err error
 	var body []byte
 	if username != "" && password != "" {
@@ -26,7 +30,7 @@ func Login(c *drycc.Client, username, password string) (string, error) {
 		body = nil
 	}
 
-	res, err := c.Request("POST", "/v2/auth/login/", body)
+	res, err := c.RequestWithContext(ctx, "POST", "/v2/auth/login/", body)
 	if err != nil && !drycc.IsErrAPIMismatch(err) {
 		return "", err
 	}
@@ -45,8 +49,13 @@ func Login(c *drycc.Client, username, password string) (string, error) {
 
 // Token to the controller and get a token
 func Token(c *drycc.Client, key, alias string) (api.AuthTokenResponse, error) {
+	return TokenWithContext(context.Background(), c, key, alias)
+}
+
+// TokenWithContext is like [Token] but cancels the request when ctx is done.
+func TokenWithContext(ctx context.Context, c *drycc.Client, key, alias string) (api.AuthTokenResponse, error) {
 	path := fmt.Sprintf("/v2/auth/token/%s/?alias=%s", key, alias)
-	res, reqErr := c.Request("GET", path, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", path, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.AuthTokenResponse{}, reqErr
 	}
@@ -60,7 +69,12 @@ func Token(c *drycc.Client, key, alias string) (api.AuthTokenResponse, error) {
 
 // Whoami retrives the user object for the authenticated user.
 func Whoami(c *drycc.Client) (api.User, error) {
-	res, err := c.Request("GET", "/v2/auth/whoami/", nil)
+	return WhoamiWithContext(context.Background(), c)
+}
+
+// WhoamiWithContext is like [Whoami] but cancels the request when ctx is done.
+func WhoamiWithContext(ctx context.Context, c *drycc.Client) (api.User, error) {
+	res, err := c.RequestWithContext(ctx, "GET", "/v2/auth/whoami/", nil)
 	if err != nil {
 		return api.User{}, err
 	}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -76,6 +77,42 @@ func TestLogin(t *testing.T) {
 	}
 }
 
+func TestLoginConcurrent(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	drycc, err := drycc.New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := Login(drycc, "", ""); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := Token(drycc, keyFixture, "test"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Login must not change the redirect policy of the shared client.
+	if drycc.HTTPClient.CheckRedirect != nil {
+		t.Error("Expected CheckRedirect to be left unset")
+	}
+}
+
 func TestToken(t *testing.T) {
 	t.Parallel()
 
@@ -2,6 +2,7 @@
 package builds
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,12 +12,17 @@ import (
 
 // Get a build of an app.
 func Get(c *drycc.Client, appID string, version int) (api.Build, error) {
+	return GetWithContext(context.Background(), c, appID, version)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string, version int) (api.Build, error) {
 	u := fmt.Sprintf("/v2/apps/%s/build/", appID)
 	if version > 0 {
 		u = fmt.Sprintf("%s?version=v%d", u, version)
 	}
 
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Build{}, reqErr
 	}
@@ -32,6 +38,13 @@ func Get(c *drycc.Client, appID string, version int) (api.Build, error) {
 // New a build of an app.
 func New(c *drycc.Client, appID string, image string, stack string,
 	procfile map[string]string, dryccfile map[string]any,
+) (api.Build, error) {
+	return NewWithContext(context.Background(), c, appID, image, stack, procfile, dryccfile)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID string, image string, stack string,
+	procfile map[string]string, dryccfile map[string]any,
 ) (api.Build, error) {
 	u := fmt.Sprintf("/v2/apps/%s/build/", appID)
 
@@ -47,7 +60,7 @@ func New(c *drycc.Client, appID string, image string, stack string,
 		return api.Build{}, err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Build{}, reqErr
 	}
@@ -0,0 +1,381 @@
+// Package canary shifts the traffic of a route from a stable backend to a canary backend
+// in steps, checking gates between the steps and rolling back if one fails.
+//
+// The backends are referenced by the rules of the route, and their weights are set with
+// [routes.SetRule]:
+//
+//	err := canary.Run(ctx, client, canary.Config{
+//	    App:    "example-go",
+//	    Route:  "example-go",
+//	    Stable: canary.Backend{Name: "example-go-web", Ptype: "web"},
+//	    Canary: canary.Backend{Name: "example-go-canary", Ptype: "canary"},
+//	    Steps:  []int{10, 50, 100},
+//	    Health: func(ctx context.Context, step canary.Step) error {
+//	        return checkErrorRate(ctx)
+//	    },
+//	})
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/controller-sdk-go/pts"
+	"github.com/drycc/controller-sdk-go/routes"
+)
+
+// The gates checked after each step.
+const (
+	GateReadiness = "readiness"
+	GateRestarts  = "restarts"
+	GateHealth    = "health"
+)
+
+// DefaultSteps are the canary weights used when [Config] has no steps.
+var DefaultSteps = []int{10, 25, 50, 100}
+
+// rollbackTimeout bounds the restoration of the rules, which is done even if the context
+// of the rollout is done.
+const rollbackTimeout = 30 * time.Second
+
+// Backend is a backend of the route.
+type Backend struct {
+	// Name is the name of the backend in the backendRefs of the rules, usually a service.
+	Name string
+	// Port is the port of the backend, used when it's added to a rule. If zero, the port of
+	// the other backend is used.
+	Port int
+	// Ptype is the process type serving the backend, whose readiness is checked by the
+	// gates. If empty, the backend has no readiness gate.
+	Ptype string
+}
+
+// Step is a step of a rollout.
+type Step struct {
+	// Index is the index of the step in the steps of the rollout.
+	Index int
+	// Weight is the weight of the canary backend, in percent. The stable backend has the
+	// remaining weight.
+	Weight int
+}
+
+// EventType is the type of an [Event].
+type EventType int
+
+const (
+	// StepStarted is emitted when the weights of a step are set.
+	StepStarted EventType = iota
+	// GatePassed is emitted for each gate passed after a step.
+	GatePassed
+	// GateFailed is emitted when a gate fails, before rolling back.
+	GateFailed
+	// RolledBack is emitted once the original rules are restored. Err is set if they
+	// couldn't be restored.
+	RolledBack
+	// Completed is emitted once every step passed its gates.
+	Completed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case StepStarted:
+		return "StepStarted"
+	case GatePassed:
+		return "GatePassed"
+	case GateFailed:
+		return "GateFailed"
+	case RolledBack:
+		return "RolledBack"
+	case Completed:
+		return "Completed"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event reports the progress of a rollout.
+type Event struct {
+	Type EventType
+	Step Step
+	// Gate is the gate passed or failed.
+	Gate string
+	// Err is the error of a failed gate or rollback.
+	Err error
+}
+
+// Config configures a rollout.
+type Config struct {
+	App   string
+	Route string
+	// Stable is the backend serving the traffic initially, and Canary the backend the
+	// traffic is shifted to. The canary backend is added next to the stable backend in
+	// the rules that don't reference it.
+	Stable Backend
+	Canary Backend
+	// Steps are the increasing weights of the canary backend, in percent. Defaults to
+	// DefaultSteps.
+	Steps []int
+	// Interval is the time traffic is served with the weights of a step before checking
+	// the gates. Defaults to 30s.
+	Interval time.Duration
+	// ReadyTimeout bounds the wait for the process types of the backends to be ready,
+	// at each step. Defaults to 2m.
+	ReadyTimeout time.Duration
+	// MaxRestarts is the number of restarts of the pods of the canary process type
+	// tolerated during the rollout. A negative value disables the gate.
+	MaxRestarts int
+	// Health, if not nil, is called after each step. The rollout fails if it returns an
+	// error.
+	Health func(ctx context.Context, step Step) error
+	// OnEvent, if not nil, is called with the events of the rollout, in order.
+	OnEvent func(Event)
+}
+
+func (cfg *Config) defaults() {
+	if len(cfg.Steps) == 0 {
+		cfg.Steps = DefaultSteps
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.ReadyTimeout <= 0 {
+		cfg.ReadyTimeout = 2 * time.Minute
+	}
+}
+
+// GateError is returned by [Run] when a gate fails.
+type GateError struct {
+	Step Step
+	Gate string
+	Err  error
+}
+
+func (e *GateError) Error() string {
+	return fmt.Sprintf("canary gate %s failed at %d%%: %v", e.Gate, e.Step.Weight, e.Err)
+}
+
+func (e *GateError) Unwrap() error {
+	return e.Err
+}
+
+// Run shifts the traffic of the route to the canary backend, step by step. After the
+// weights of a step are set and Interval elapsed, the gates are checked: the process types
+// of the backends must be ready, the pods of the canary process type must not restart more
+// than MaxRestarts times, and Health must succeed.
+//
+// If a gate fails, or ctx is done, the original rules of the route are restored and a
+// [*GateError] or the error of ctx is returned, joined with the error of the rollback, if
+// any.
+func Run(ctx context.Context, c *drycc.Client, cfg Config) error {
+	cfg.defaults()
+	if err := validate(cfg); err != nil {
+		return err
+	}
+	emit := func(e Event) {
+		if cfg.OnEvent != nil {
+			cfg.OnEvent(e)
+		}
+	}
+
+	original, err := routes.GetRuleWithContext(ctx, c, cfg.App, cfg.Route)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return err
+	}
+	var rules []map[string]any
+	if err := json.Unmarshal([]byte(original), &rules); err != nil {
+		return fmt.Errorf("invalid rules of route %s: %w", cfg.Route, err)
+	}
+	baseline, err := restarts(ctx, c, cfg.App, cfg.Canary.Ptype)
+	if err != nil {
+		return err
+	}
+
+	for i, weight := range cfg.Steps {
+		step := Step{Index: i, Weight: weight}
+		err := setWeights(ctx, c, cfg, rules, weight)
+		if err == nil {
+			emit(Event{Type: StepStarted, Step: step})
+			err = sleep(ctx, cfg.Interval)
+		}
+		if err == nil {
+			err = checkGates(ctx, c, cfg, step, baseline, emit)
+		}
+		if err != nil {
+			rollbackErr := rollback(ctx, c, cfg, original)
+			emit(Event{Type: RolledBack, Step: step, Err: rollbackErr})
+			return errors.Join(err, rollbackErr)
+		}
+	}
+	emit(Event{Type: Completed, Step: Step{Index: len(cfg.Steps) - 1, Weight: cfg.Steps[len(cfg.Steps)-1]}})
+	return nil
+}
+
+func validate(cfg Config) error {
+	if cfg.Stable.Name == "" || cfg.Canary.Name == "" || cfg.Stable.Name == cfg.Canary.Name {
+		return errors.New("canary: the stable and canary backends must have distinct names")
+	}
+	last := 0
+	for _, weight := range cfg.Steps {
+		if weight <= last || weight > 100 {
+			return fmt.Errorf("canary: steps must increase from 1 to 100, got %v", cfg.Steps)
+		}
+		last = weight
+	}
+	return nil
+}
+
+// checkGates checks the gates of a step, returning a *GateError if one fails.
+func checkGates(ctx context.Context, c *drycc.Client, cfg Config, step Step, baseline map[string]int, emit func(Event)) error {
+	gates := []struct {
+		name  string
+		check func() error
+	}{
+		{GateReadiness, func() error {
+			var ptypes []string
+			for _, b := range []Backend{cfg.Stable, cfg.Canary} {
+				if b.Ptype != "" {
+					ptypes = append(ptypes, b.Ptype)
+				}
+			}
+			if len(ptypes) == 0 {
+				return nil
+			}
+			return pts.WaitReady(ctx, c, cfg.App, ptypes, pts.WaitOptions{
+				Interval: min(cfg.Interval, 2*time.Second),
+				Timeout:  cfg.ReadyTimeout,
+			})
+		}},
+		{GateRestarts, func() error {
+			if cfg.MaxRestarts < 0 || cfg.Canary.Ptype == "" {
+				return nil
+			}
+			current, err := restarts(ctx, c, cfg.App, cfg.Canary.Ptype)
+			if err != nil {
+				return err
+			}
+			total := 0
+			for pod, n := range current {
+				total += max(0, n-baseline[pod])
+			}
+			if total > cfg.MaxRestarts {
+				return fmt.Errorf("pods of %s restarted %d times", cfg.Canary.Ptype, total)
+			}
+			return nil
+		}},
+		{GateHealth, func() error {
+			if cfg.Health == nil {
+				return nil
+			}
+			return cfg.Health(ctx, step)
+		}},
+	}
+	for _, gate := range gates {
+		if err := gate.check(); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			emit(Event{Type: GateFailed, Step: step, Gate: gate.name, Err: err})
+			return &GateError{Step: step, Gate: gate.name, Err: err}
+		}
+		emit(Event{Type: GatePassed, Step: step, Gate: gate.name})
+	}
+	return nil
+}
+
+// restarts returns the restarts of the pods of a process type, by pod.
+func restarts(ctx context.Context, c *drycc.Client, appID, ptype string) (map[string]int, error) {
+	counts := map[string]int{}
+	if ptype == "" {
+		return counts, nil
+	}
+	for pod, err := range ps.AllWithContext(ctx, c, appID) {
+		if err != nil {
+			return nil, err
+		}
+		if pod.Type == ptype {
+			counts[pod.Name] = pod.Restarts
+		}
+	}
+	return counts, nil
+}
+
+// setWeights sets the weights of the backends in the rules referencing the stable or the
+// canary backend, and saves the rules.
+func setWeights(ctx context.Context, c *drycc.Client, cfg Config, rules []map[string]any, weight int) error {
+	found := false
+	for _, rule := range rules {
+		refs, _ := rule["backendRefs"].([]any)
+		var stable, canary map[string]any
+		for _, ref := range refs {
+			ref, _ := ref.(map[string]any)
+			switch ref["name"] {
+			case cfg.Stable.Name:
+				stable = ref
+			case cfg.Canary.Name:
+				canary = ref
+			}
+		}
+		if stable == nil && canary == nil {
+			continue
+		}
+		found = true
+		if stable == nil {
+			stable = addBackend(rule, canary, cfg.Stable)
+		}
+		if canary == nil {
+			canary = addBackend(rule, stable, cfg.Canary)
+		}
+		stable["weight"], canary["weight"] = 100-weight, weight
+	}
+	if !found {
+		return fmt.Errorf("canary: no rule of route %s references %s or %s", cfg.Route, cfg.Stable.Name, cfg.Canary.Name)
+	}
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	if err := routes.SetRuleWithContext(ctx, c, cfg.App, cfg.Route, string(data)); err != nil && !drycc.IsErrAPIMismatch(err) {
+		return err
+	}
+	return nil
+}
+
+// addBackend adds a backend to a rule, like the reference of the other backend.
+func addBackend(rule map[string]any, like map[string]any, b Backend) map[string]any {
+	ref := map[string]any{"name": b.Name}
+	for _, key := range []string{"kind", "group", "port"} {
+		if v, ok := like[key]; ok {
+			ref[key] = v
+		}
+	}
+	if b.Port != 0 {
+		ref["port"] = b.Port
+	}
+	rule["backendRefs"] = append(rule["backendRefs"].([]any), ref)
+	return ref
+}
+
+// rollback restores the original rules of the route.
+func rollback(ctx context.Context, c *drycc.Client, cfg Config, original string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+	if err := routes.SetRuleWithContext(ctx, c, cfg.App, cfg.Route, original); err != nil && !drycc.IsErrAPIMismatch(err) {
+		return fmt.Errorf("canary: rolling back route %s: %w", cfg.Route, err)
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,190 @@
+package canary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func newServer() *drycctest.Server {
+	srv := drycctest.NewServer()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPtypes("example-go",
+		api.Ptype{Name: "web", Ready: "1/1", UpToDate: 1, AvailableReplicas: 1},
+		api.Ptype{Name: "canary", Ready: "1/1", UpToDate: 1, AvailableReplicas: 1},
+	)
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web", Ready: "1/1"},
+		api.Pods{Name: "example-go-canary-1", Type: "canary", Ready: "1/1", Restarts: 2},
+	)
+	srv.AddRoute("example-go", api.Route{Name: "example-go", Kind: "HTTPRoute", Rules: []api.RouteRule{
+		{
+			"matches":     []any{map[string]any{"path": map[string]any{"value": "/"}}},
+			"backendRefs": []any{map[string]any{"kind": "Service", "name": "example-go-web", "port": 80}},
+		},
+		{"backendRefs": []any{map[string]any{"kind": "Service", "name": "example-go-other", "port": 80}}},
+	}})
+	return srv
+}
+
+// weights returns the weights of the backends of the first rule of the route.
+func weights(srv *drycctest.Server) map[string]any {
+	refs := srv.Routes("example-go")[0].Rules[0]["backendRefs"].([]any)
+	weights := map[string]any{}
+	for _, ref := range refs {
+		ref := ref.(map[string]any)
+		weights[ref["name"].(string)] = ref["weight"]
+	}
+	return weights
+}
+
+func config(srv *drycctest.Server, events *[]string) Config {
+	return Config{
+		App:      "example-go",
+		Route:    "example-go",
+		Stable:   Backend{Name: "example-go-web", Ptype: "web"},
+		Canary:   Backend{Name: "example-go-canary", Ptype: "canary"},
+		Steps:    []int{20, 100},
+		Interval: time.Millisecond,
+		OnEvent: func(e Event) {
+			s := fmt.Sprintf("%v %d%%", e.Type, e.Step.Weight)
+			if e.Gate != "" {
+				s += " " + e.Gate
+			}
+			if e.Type == StepStarted {
+				s += fmt.Sprint(" ", weights(srv))
+			}
+			*events = append(*events, s)
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer()
+	defer srv.Close()
+	var events []string
+	if err := Run(context.Background(), srv.Client(), config(srv, &events)); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"StepStarted 20% map[example-go-canary:20 example-go-web:80]",
+		"GatePassed 20% readiness",
+		"GatePassed 20% restarts",
+		"GatePassed 20% health",
+		"StepStarted 100% map[example-go-canary:100 example-go-web:0]",
+		"GatePassed 100% readiness",
+		"GatePassed 100% restarts",
+		"GatePassed 100% health",
+		"Completed 100%",
+	}
+	if !reflect.DeepEqual(expected, events) {
+		t.Errorf("Expected %v, Got %v", expected, events)
+	}
+	route := srv.Routes("example-go")[0]
+	if len(route.Rules[1]["backendRefs"].([]any)) != 1 || route.Rules[0]["matches"] == nil {
+		t.Errorf("Expected the other rules and matches to be kept, Got %v", route.Rules)
+	}
+	canary := route.Rules[0]["backendRefs"].([]any)[1].(map[string]any)
+	if canary["kind"] != "Service" || canary["port"] != float64(80) {
+		t.Errorf("Expected the canary backend to be added like the stable one, Got %v", canary)
+	}
+}
+
+func TestRunAPIMismatch(t *testing.T) {
+	t.Parallel()
+
+	// The controller is a minor version behind.
+	srv := newServer()
+	defer srv.Close()
+	srv.SetAPIVersion("2.2")
+	var events []string
+	if err := Run(context.Background(), srv.Client(), config(srv, &events)); err != nil {
+		t.Fatal(err)
+	}
+	if last := events[len(events)-1]; last != "Completed 100%" {
+		t.Errorf("Expected the rollout to complete, Got %v", events)
+	}
+	if w := weights(srv); w["example-go-canary"] != float64(100) {
+		t.Errorf("Expected the traffic to be shifted, Got %v", w)
+	}
+
+	// The rollback succeeds too.
+	events = nil
+	cfg := config(srv, &events)
+	cfg.Health = func(ctx context.Context, step Step) error { return errors.New("unhealthy") }
+	err := Run(context.Background(), srv.Client(), cfg)
+	var gateErr *GateError
+	if !errors.As(err, &gateErr) || drycc.IsErrAPIMismatch(err) {
+		t.Errorf("Expected only the error of the gate, Got %v", err)
+	}
+	if last := events[len(events)-1]; last != "RolledBack 20%" {
+		t.Errorf("Expected the rollout to be rolled back, Got %v", events)
+	}
+}
+
+func TestRunRollback(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer()
+	defer srv.Close()
+	rules := func() string {
+		data, _ := json.Marshal(srv.Routes("example-go")[0].Rules)
+		return string(data)
+	}
+	original := rules()
+
+	var events []string
+	cfg := config(srv, &events)
+	unhealthy := errors.New("error rate above 5%")
+	cfg.Health = func(ctx context.Context, step Step) error {
+		if step.Weight == 100 {
+			return unhealthy
+		}
+		return nil
+	}
+	err := Run(context.Background(), srv.Client(), cfg)
+	var gateErr *GateError
+	if !errors.As(err, &gateErr) || gateErr.Gate != GateHealth || !errors.Is(err, unhealthy) {
+		t.Fatalf("Expected the health gate to fail, Got %v", err)
+	}
+	if last := events[len(events)-2:]; !reflect.DeepEqual(last, []string{"GateFailed 100% health", "RolledBack 100%"}) {
+		t.Errorf("Expected the rollout to be rolled back, Got %v", events)
+	}
+	if got := rules(); original != got {
+		t.Errorf("Expected %v, Got %v", original, got)
+	}
+
+	// The canary pod restarts during the first step.
+	events = nil
+	cfg = config(srv, &events)
+	onEvent := cfg.OnEvent
+	cfg.OnEvent = func(e Event) {
+		if e.Type == StepStarted {
+			srv.UpdatePod("example-go", "example-go-canary-1", func(p *api.Pods) { p.Restarts++ })
+		}
+		onEvent(e)
+	}
+	err = Run(context.Background(), srv.Client(), cfg)
+	if !errors.As(err, &gateErr) || gateErr.Gate != GateRestarts || gateErr.Step.Weight != 20 {
+		t.Errorf("Expected the restarts gate to fail, Got %v", err)
+	}
+	if got := rules(); original != got {
+		t.Errorf("Expected %v, Got %v", original, got)
+	}
+
+	cfg = config(srv, &events)
+	cfg.Steps = []int{50, 20}
+	if err := Run(context.Background(), srv.Client(), cfg); err == nil {
+		t.Error("Expected an error for decreasing steps")
+	}
+}
@@ -0,0 +1,127 @@
+// Package cassette records the interactions of a client with a controller and replays
+// them, so integration tests can run against a real controller once and then offline.
+//
+// A [Recorder] is installed as the transport of a [drycc.Client]. In [Record] mode it
+// forwards requests to the controller and captures them into a cassette file, along with
+// the frames of the websockets opened by ps.Exec and ps.Logs. In [Replay] mode it serves
+// the recorded responses without any network access:
+//
+//	mode := cassette.Replay
+//	if os.Getenv("RECORD") != "" {
+//	    mode = cassette.Record
+//	}
+//	rec, err := cassette.New("testdata/apps.json", mode)
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	defer rec.Save()
+//	rec.Install(client)
+//
+// The Authorization and X-Drycc-Service-Key headers are scrubbed from the recording.
+// Bodies are recorded as is, so cassettes of requests carrying credentials, such as
+// auth.Login, should not be shared.
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// Mode is the mode of a [Recorder].
+type Mode int
+
+const (
+	// Record sends requests to the controller and records the interactions.
+	Record Mode = iota
+	// Replay serves the recorded interactions without sending requests.
+	Replay
+)
+
+// ErrNoInteraction is returned in Replay mode for a request that was not recorded.
+var ErrNoInteraction = errors.New("cassette: no recorded interaction matches the request")
+
+// scrubbedHeaders are removed from the recorded requests.
+var scrubbedHeaders = []string{"Authorization", "X-Drycc-Service-Key"}
+
+// Cassette is a list of recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a request along with the response of the controller or, for
+// websockets, the frames exchanged over the connection.
+type Interaction struct {
+	Request  Request   `json:"request"`
+	Response *Response `json:"response,omitempty"`
+	Frames   []Frame   `json:"frames,omitempty"`
+	// ClientClosed is true if the client closed the websocket before the controller.
+	ClientClosed bool `json:"client_closed,omitempty"`
+}
+
+// Request is a recorded request. Query is encoded with its keys sorted.
+type Request struct {
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Query     string      `json:"query,omitempty"`
+	Header    http.Header `json:"header,omitempty"`
+	Body      string      `json:"body,omitempty"`
+	Websocket bool        `json:"websocket,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Frame is a websocket message. Text frames have Text set, binary frames have Binary set.
+type Frame struct {
+	// FromServer is true for the frames sent by the controller.
+	FromServer bool   `json:"from_server"`
+	Text       string `json:"text,omitempty"`
+	Binary     []byte `json:"binary,omitempty"`
+}
+
+// Load reads a cassette file.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cassette to a file, replacing it if it exists.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// matches returns true if the interaction was recorded for the request.
+func (i *Interaction) matches(req Request) bool {
+	return i.Request.Method == req.Method && i.Request.Path == req.Path &&
+		i.Request.Query == req.Query && i.Request.Body == req.Body &&
+		i.Request.Websocket == req.Websocket
+}
+
+// scrub returns a copy of header without the scrubbed headers.
+func scrub(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range scrubbedHeaders {
+		header.Del(key)
+	}
+	if len(header) == 0 {
+		return nil
+	}
+	return header
+}
@@ -0,0 +1,114 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/apps"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"github.com/drycc/controller-sdk-go/ps"
+	"golang.org/x/net/websocket"
+)
+
+// session runs the calls recorded and replayed by the tests.
+func session(t *testing.T, client *drycc.Client) ([]string, string) {
+	t.Helper()
+
+	if _, err := apps.New(client, "example-new", "test"); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for app, err := range apps.All(client) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, app.ID)
+	}
+
+	conn, err := ps.Exec(client, "example-go", "example-go-web-1", api.Command{Command: []string{"echo", "hello"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var output string
+	if err := websocket.Message.Receive(conn, &output); err != nil {
+		t.Fatal(err)
+	}
+	var eof string
+	if err := websocket.Message.Receive(conn, &eof); err != io.EOF {
+		t.Errorf("Expected %v, Got %v", io.EOF, err)
+	}
+	return ids, output
+}
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := drycctest.NewServer()
+	srv.Token = "secret"
+	srv.ServiceKey = "service"
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go", api.Pods{Name: "example-go-web-1", Type: "web"})
+	srv.HandleExec(func(conn *websocket.Conn, appID, pod string, command api.Command) {
+		websocket.Message.Send(conn, command.Command[1])
+	})
+	client := srv.Client()
+
+	rec, err := New(path, Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Install(client)
+	recordedIDs, recordedOutput := session(t, client)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range c.Interactions {
+		if i.Request.Header.Get("Authorization") != "" || i.Request.Header.Get("X-Drycc-Service-Key") != "" {
+			t.Errorf("Expected the credentials to be scrubbed, Got %v", i.Request.Header)
+		}
+	}
+	last := c.Interactions[len(c.Interactions)-1]
+	if !last.Request.Websocket || len(last.Frames) != 2 || last.Frames[1].Text != "hello" || last.ClientClosed {
+		t.Errorf("Expected the exec frames to be recorded, Got %v", last)
+	}
+
+	// The controller is gone, so the session can only succeed by replaying the cassette.
+	rec, err = New(path, Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := drycc.New(false, "drycc.invalid", "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Install(replayed)
+	ids, output := session(t, replayed)
+	if !reflect.DeepEqual(recordedIDs, ids) {
+		t.Errorf("Expected %v, Got %v", recordedIDs, ids)
+	}
+	if recordedOutput != output {
+		t.Errorf("Expected %v, Got %v", recordedOutput, output)
+	}
+
+	// Every interaction is replayed once.
+	if _, err := apps.New(replayed, "example-new", "test"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Expected %v, Got %v", ErrNoInteraction, err)
+	}
+	if _, err := apps.Get(replayed, "unknown"); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Expected %v, Got %v", ErrNoInteraction, err)
+	}
+}
@@ -0,0 +1,165 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	drycc "github.com/drycc/controller-sdk-go"
+)
+
+// Recorder is an [http.RoundTripper] recording or replaying interactions with the
+// controller. It is also a [drycc.WebsocketDialer], so the websockets opened by a client
+// using it are recorded or replayed as well. A Recorder is safe for concurrent use.
+type Recorder struct {
+	// Transport sends the requests in Record mode. If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	mode Mode
+	path string
+
+	mu       sync.Mutex
+	cassette *Cassette
+	// used marks the interactions already replayed.
+	used []bool
+}
+
+// New creates a recorder of the cassette file at path. In Replay mode, the file is
+// loaded and must exist. In Record mode, the file is written by [Recorder.Save].
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, cassette: &Cassette{}}
+	if mode == Replay {
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette, r.used = c, make([]bool, len(c.Interactions))
+	}
+	return r, nil
+}
+
+// Install makes the client send its requests through the recorder. The client's current
+// transport is used to send the requests in Record mode, unless Transport is already set.
+func (r *Recorder) Install(c *drycc.Client) {
+	if c.HTTPClient == nil {
+		c.HTTPClient = &http.Client{}
+	}
+	if r.Transport == nil {
+		r.Transport = c.HTTPClient.Transport
+	}
+	c.HTTPClient.Transport = r
+}
+
+// Save writes the recorded interactions to the cassette file. It does nothing in Replay mode.
+func (r *Recorder) Save() error {
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// Cassette returns the interactions recorded or loaded so far. The returned cassette
+// must not be modified while the recorder is in use.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := newRequest(req, body)
+
+	if r.mode == Replay {
+		i, err := r.replay(recorded)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        i.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	r.record(&Interaction{Request: recorded, Response: &Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       string(resBody),
+	}})
+	return res, nil
+}
+
+// record appends an interaction to the cassette.
+func (r *Recorder) record(i *Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+}
+
+// replay returns the first interaction matching the request that wasn't replayed yet.
+func (r *Recorder) replay(req Request) (*Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.cassette.Interactions {
+		if !r.used[n] && i.matches(req) {
+			r.used[n] = true
+			return i, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.Path)
+}
+
+// readBody reads the body of req and replaces it so it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func newRequest(req *http.Request, body []byte) Request {
+	return Request{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.Query().Encode(),
+		Header: scrub(req.Header),
+		Body:   string(body),
+	}
+}
@@ -0,0 +1,232 @@
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// frameCodec sends and receives frames, keeping their payload type.
+var frameCodec = websocket.Codec{
+	Marshal: func(v any) ([]byte, byte, error) {
+		f := v.(Frame)
+		if f.Binary != nil {
+			return f.Binary, websocket.BinaryFrame, nil
+		}
+		return []byte(f.Text), websocket.TextFrame, nil
+	},
+	Unmarshal: func(data []byte, payloadType byte, v any) error {
+		f := v.(*Frame)
+		if payloadType == websocket.BinaryFrame {
+			f.Binary = bytes.Clone(data)
+		} else {
+			f.Text = string(data)
+		}
+		return nil
+	},
+}
+
+// DialWebsocket implements [drycc.WebsocketDialer]. The returned connection is served in
+// process: in Record mode, its frames are relayed to and from the controller and recorded;
+// in Replay mode, the recorded frames of the controller are sent back in order, after the
+// client has sent the frames that preceded them. A replayed connection is closed when the
+// client sends a frame differing from the recording.
+func (r *Recorder) DialWebsocket(ctx context.Context, config *websocket.Config) (*websocket.Conn, error) {
+	req := Request{
+		Method:    http.MethodGet,
+		Path:      config.Location.Path,
+		Query:     config.Location.Query().Encode(),
+		Header:    scrub(config.Header),
+		Websocket: true,
+	}
+
+	if r.mode == Replay {
+		i, err := r.replay(req)
+		if err != nil {
+			return nil, err
+		}
+		return serve(config, func(conn *websocket.Conn) {
+			replayFrames(conn, i)
+		})
+	}
+
+	remoteConfig := *config
+	if remoteConfig.TlsConfig == nil {
+		if transport, ok := r.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			remoteConfig.TlsConfig = transport.TLSClientConfig.Clone()
+		}
+	}
+	remote, err := remoteConfig.DialContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := &Interaction{Request: req}
+	r.record(i)
+	return serve(config, func(conn *websocket.Conn) {
+		r.relay(conn, remote, i)
+	})
+}
+
+// relay forwards frames between the client and the controller until either closes the
+// connection, recording them in i.
+func (r *Recorder) relay(conn, remote *websocket.Conn, i *Interaction) {
+	var once sync.Once
+	closed := func(byClient bool) {
+		once.Do(func() {
+			r.mu.Lock()
+			i.ClientClosed = byClient
+			r.mu.Unlock()
+		})
+	}
+	forward := func(from, to *websocket.Conn, fromServer bool) {
+		for {
+			f := Frame{FromServer: fromServer}
+			if err := frameCodec.Receive(from, &f); err != nil {
+				closed(!fromServer)
+				return
+			}
+			r.mu.Lock()
+			i.Frames = append(i.Frames, f)
+			r.mu.Unlock()
+			if err := frameCodec.Send(to, f); err != nil {
+				return
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forward(conn, remote, false)
+		remote.Close()
+	}()
+	forward(remote, conn, true)
+	remote.Close()
+	conn.Close()
+	<-done
+}
+
+// replayFrames sends the recorded frames of the controller to the client.
+func replayFrames(conn *websocket.Conn, i *Interaction) {
+	frames := receiveAll(conn)
+	for _, f := range i.Frames {
+		if f.FromServer {
+			if err := frameCodec.Send(conn, f); err != nil {
+				return
+			}
+			continue
+		}
+		received, ok := frames.next()
+		if !ok || received.Text != f.Text || !bytes.Equal(received.Binary, f.Binary) {
+			return
+		}
+	}
+	if i.ClientClosed {
+		for _, ok := frames.next(); ok; _, ok = frames.next() {
+		}
+	}
+}
+
+// frameQueue holds the frames received from a client. Frames are always read from the
+// client as soon as they are sent, so it never blocks writing to the synchronous pipe.
+type frameQueue struct {
+	mu     sync.Mutex
+	frames []Frame
+	done   bool
+	ready  chan struct{}
+}
+
+func receiveAll(conn *websocket.Conn) *frameQueue {
+	q := &frameQueue{ready: make(chan struct{}, 1)}
+	go func() {
+		for {
+			var f Frame
+			err := frameCodec.Receive(conn, &f)
+			q.mu.Lock()
+			if err != nil {
+				q.done = true
+			} else {
+				q.frames = append(q.frames, f)
+			}
+			q.mu.Unlock()
+			select {
+			case q.ready <- struct{}{}:
+			default:
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return q
+}
+
+// next returns the next received frame, or false once the client closed the connection.
+func (q *frameQueue) next() (Frame, bool) {
+	for {
+		q.mu.Lock()
+		if len(q.frames) > 0 {
+			f := q.frames[0]
+			q.frames = q.frames[1:]
+			q.mu.Unlock()
+			return f, true
+		}
+		done := q.done
+		q.mu.Unlock()
+		if done {
+			return Frame{}, false
+		}
+		<-q.ready
+	}
+}
+
+// serve opens a websocket connection served in process by handler.
+func serve(config *websocket.Config, handler websocket.Handler) (*websocket.Conn, error) {
+	client, server := net.Pipe()
+	go func() {
+		br := bufio.NewReader(server)
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			server.Close()
+			return
+		}
+		w := &hijacker{conn: server, rw: bufio.NewReadWriter(br, bufio.NewWriter(server))}
+		websocket.Server{Handler: handler}.ServeHTTP(w, req)
+	}()
+	conn, err := websocket.NewClient(config, client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// hijacker is the response writer of the websocket handshakes served in process.
+type hijacker struct {
+	conn   net.Conn
+	rw     *bufio.ReadWriter
+	header http.Header
+}
+
+func (h *hijacker) Header() http.Header {
+	if h.header == nil {
+		h.header = http.Header{}
+	}
+	return h.header
+}
+
+func (h *hijacker) Write(data []byte) (int, error) {
+	return 0, errors.New("cassette: write to a hijacked connection")
+}
+
+func (h *hijacker) WriteHeader(int) {}
+
+func (h *hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return h.conn, h.rw, nil
+}
@@ -2,8 +2,10 @@
 package certs
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // List lists certificates added to drycc.
 func List(c *drycc.Client, appID string, results int) ([]api.Cert, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) ([]api.Cert, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/certs/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Cert{}, -1, reqErr
@@ -26,18 +33,33 @@ func List(c *drycc.Client, appID string, results int) ([]api.Cert, int, error) {
 	return res, count, reqErr
 }
 
+// All returns an iterator over all certs of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Cert, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Cert, error] {
+	return drycc.Paginate[api.Cert](ctx, c, fmt.Sprintf("/v2/apps/%s/certs/", appID), drycc.DefaultPageSize)
+}
+
 // New creates a new certificate.
 // Certificates are created independently from apps and are applied on a per domain basis.
 // So to enable SSL for an app with the domain test.com, you would first create the certificate,
 // then use the attach method to attach test.com to the certificate.
 func New(c *drycc.Client, appID string, cert string, key string, name string) (api.Cert, error) {
+	return NewWithContext(context.Background(), c, appID, cert, key, name)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID string, cert string, key string, name string) (api.Cert, error) {
 	req := api.CertCreateRequest{Certificate: cert, Key: key, Name: name}
 	reqBody, err := json.Marshal(req)
 	if err != nil {
 		return api.Cert{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/certs/", appID)
-	res, reqErr := c.Request("POST", u, reqBody)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, reqBody)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Cert{}, reqErr
 	}
@@ -53,8 +75,13 @@ func New(c *drycc.Client, appID string, cert string, key string, name string) (a
 
 // Get retrieves information about a certificate
 func Get(c *drycc.Client, appID string, name string) (api.Cert, error) {
+	return GetWithContext(context.Background(), c, appID, name)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string, name string) (api.Cert, error) {
 	url := fmt.Sprintf("/v2/apps/%s/certs/%s", appID, name)
-	res, reqErr := c.Request("GET", url, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", url, nil)
 	if reqErr != nil {
 		return api.Cert{}, reqErr
 	}
@@ -70,8 +97,13 @@ func Get(c *drycc.Client, appID string, name string) (api.Cert, error) {
 
 // Delete removes a certificate.
 func Delete(c *drycc.Client, appID string, name string) error {
+	return DeleteWithContext(context.Background(), c, appID, name)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, name string) error {
 	url := fmt.Sprintf("/v2/apps/%s/certs/%s", appID, name)
-	res, err := c.Request("DELETE", url, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", url, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -80,6 +112,11 @@ func Delete(c *drycc.Client, appID string, name string) error {
 
 // Attach adds a domain to a certificate.
 func Attach(c *drycc.Client, appID string, name string, domain string) error {
+	return AttachWithContext(context.Background(), c, appID, name, domain)
+}
+
+// AttachWithContext is like [Attach] but cancels the request when ctx is done.
+func AttachWithContext(ctx context.Context, c *drycc.Client, appID string, name string, domain string) error {
 	req := api.CertAttachRequest{Domain: domain}
 	reqBody, err := json.Marshal(req)
 	if err != nil {
@@ -87,7 +124,7 @@ func Attach(c *drycc.Client, appID string, name string, domain string) error {
 	}
 
 	url := fmt.Sprintf("/v2/apps/%s/certs/%s/domain/", appID, name)
-	res, err := c.Request("POST", url, reqBody)
+	res, err := c.RequestWithContext(ctx, "POST", url, reqBody)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -96,8 +133,13 @@ func Attach(c *drycc.Client, appID string, name string, domain string) error {
 
 // Detach removes a domain from a certificate.
 func Detach(c *drycc.Client, appID string, name string, domain string) error {
+	return DetachWithContext(context.Background(), c, appID, name, domain)
+}
+
+// DetachWithContext is like [Detach] but cancels the request when ctx is done.
+func DetachWithContext(ctx context.Context, c *drycc.Client, appID string, name string, domain string) error {
 	url := fmt.Sprintf("/v2/apps/%s/certs/%s/domain/%s", appID, name, domain)
-	res, err := c.Request("DELETE", url, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", url, nil)
 	if err == nil {
 		res.Body.Close()
 	}
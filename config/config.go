@@ -2,6 +2,7 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,13 +12,18 @@ import (
 
 // List lists an app's config.
 func List(c *drycc.Client, app string, version int) (api.Config, error) {
+	return ListWithContext(context.Background(), c, app, version)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, app string, version int) (api.Config, error) {
 	u := fmt.Sprintf("/v2/apps/%s/config/", app)
 	if version > 0 {
 		u = fmt.Sprintf("%s?version=v%d", u, version)
 	}
 
-	res, reqErr := c.Request("GET", u, nil)
-	if reqErr != nil {
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
+	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Config{}, reqErr
 	}
 	defer res.Body.Close()
@@ -42,14 +48,19 @@ func List(c *drycc.Client, app string, version int) (api.Config, error) {
 // Trying to unset a key that does not exist returns a drycc.ErrUnprocessable.
 // Trying to set a tag that is not a label in the kubernetes cluster will return a drycc.ErrTagNotFound.
 func Set(c *drycc.Client, app string, config api.Config, merge bool) (api.Config, error) {
+	return SetWithContext(context.Background(), c, app, config, merge)
+}
+
+// SetWithContext is like [Set] but cancels the request when ctx is done.
+func SetWithContext(ctx context.Context, c *drycc.Client, app string, config api.Config, merge bool) (api.Config, error) {
 	body, err := json.Marshal(config)
 	if err != nil {
 		return api.Config{}, err
 	}
 
 	u := fmt.Sprintf("/v2/apps/%s/config/?merge=%v", app, merge)
-	res, reqErr := c.Request("POST", u, body)
-	if reqErr != nil {
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
+	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Config{}, reqErr
 	}
 	defer res.Body.Close()
@@ -64,6 +75,11 @@ func Set(c *drycc.Client, app string, config api.Config, merge bool) (api.Config
 
 // Detach config groups from app ptype.
 func Detach(c *drycc.Client, app string, config api.Config) error {
+	return DetachWithContext(context.Background(), c, app, config)
+}
+
+// DetachWithContext is like [Detach] but cancels the request when ctx is done.
+func DetachWithContext(ctx context.Context, c *drycc.Client, app string, config api.Config) error {
 	body, err := json.Marshal(config)
 	if err != nil {
 		return err
@@ -71,7 +87,7 @@ func Detach(c *drycc.Client, app string, config api.Config) error {
 
 	u := fmt.Sprintf("/v2/apps/%s/config/", app)
 
-	res, reqErr := c.Request("DELETE", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "DELETE", u, body)
 	if reqErr != nil {
 		return reqErr
 	}
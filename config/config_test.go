@@ -130,10 +130,17 @@ const (
 	configSetRefsExpected string = `{"values_refs":{"web":["myconfig1"]}}`
 )
 
-type fakeHTTPServer struct{}
+type fakeHTTPServer struct {
+	// version is the API version of the controller. Defaults to drycc.APIVersion.
+	version string
+}
 
 func (f *fakeHTTPServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
-	res.Header().Add("DRYCC_API_VERSION", drycc.APIVersion)
+	version := f.version
+	if version == "" {
+		version = drycc.APIVersion
+	}
+	res.Header().Add("DRYCC_API_VERSION", version)
 
 	if req.URL.Path == "/v2/apps/example-go/config/" && req.Method == "POST" {
 		body, err := io.ReadAll(req.Body)
@@ -509,3 +516,34 @@ func TestConfigRefs(t *testing.T) {
 		t.Errorf("Expected %v, Got %v", expected, actual)
 	}
 }
+
+func TestConfigAPIMismatch(t *testing.T) {
+	t.Parallel()
+
+	// The controller is a minor version behind: the results are returned with the error.
+	handler := fakeHTTPServer{version: "2.2"}
+	server := httptest.NewServer(&handler)
+	defer server.Close()
+
+	client, err := drycc.New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := List(client, "example-go", -1)
+	if !drycc.IsErrAPIMismatch(err) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrAPIMismatch, err)
+	}
+	if actual.App != "example-go" || len(actual.Values) != 2 {
+		t.Errorf("Expected the config to be decoded, Got %v", actual)
+	}
+
+	configVars := api.Config{ValuesRefs: map[string][]string{"web": {"myconfig1"}}}
+	actual, err = Set(client, "setrefs-test", configVars, true)
+	if !drycc.IsErrAPIMismatch(err) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrAPIMismatch, err)
+	}
+	if actual.App != "setrefs-test" || !reflect.DeepEqual(configVars.ValuesRefs, actual.ValuesRefs) {
+		t.Errorf("Expected the config to be decoded, Got %v", actual)
+	}
+}
@@ -2,8 +2,10 @@
 package domains
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // List domains registered with an app.
 func List(c *drycc.Client, appID string, results int) (api.Domains, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Domains, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/domains/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Domain{}, -1, reqErr
@@ -26,8 +33,23 @@ func List(c *drycc.Client, appID string, results int) (api.Domains, int, error)
 	return domains, count, reqErr
 }
 
+// All returns an iterator over all domains of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Domain, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Domain, error] {
+	return drycc.Paginate[api.Domain](ctx, c, fmt.Sprintf("/v2/apps/%s/domains/", appID), drycc.DefaultPageSize)
+}
+
 // New adds a domain to an app.
 func New(c *drycc.Client, appID, domain, Ptype string) (api.Domain, error) {
+	return NewWithContext(context.Background(), c, appID, domain, Ptype)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID, domain, Ptype string) (api.Domain, error) {
 	u := fmt.Sprintf("/v2/apps/%s/domains/", appID)
 
 	req := api.DomainCreateRequest{Domain: domain, Ptype: Ptype}
@@ -37,7 +59,7 @@ func New(c *drycc.Client, appID, domain, Ptype string) (api.Domain, error) {
 		return api.Domain{}, err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Domain{}, reqErr
 	}
@@ -53,8 +75,13 @@ func New(c *drycc.Client, appID, domain, Ptype string) (api.Domain, error) {
 
 // Delete removes a domain from an app.
 func Delete(c *drycc.Client, appID string, domain string) error {
+	return DeleteWithContext(context.Background(), c, appID, domain)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, domain string) error {
 	u := fmt.Sprintf("/v2/apps/%s/domains/%s", appID, domain)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
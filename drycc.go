@@ -45,20 +45,47 @@
 //	// Set the client to use the retrieved token
 //	client.Token = token
 //
+// # Contexts
+//
+// Every SDK function has a WithContext variant that takes a [context.Context] as its
+// first argument. The context is attached to the outgoing HTTP request or websocket
+// handshake, so it can be used to set deadlines or cancel calls in flight.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	apps, _, err := apps.ListWithContext(ctx, client, 100)
+//
+// # Pagination
+//
+// List functions only return the first page of results. To walk every result, use the All
+// iterator of a subpackage, which follows the controller's next links lazily:
+//
+//	for app, err := range apps.All(client) {
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    fmt.Println(app.ID)
+//	}
+//
 // # Learning More
 //
 // See the godoc for the SDK's subpackages to learn more about specific SDK actions.
 package drycc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 )
 
-// Client oversees the interaction between the drycc and controller
+// Client oversees the interaction between the drycc and controller.
+//
+// A Client is safe for concurrent use by multiple goroutines once configured;
+// its exported fields should not be modified while requests are in flight.
 type Client struct {
 	// HTTPClient is the transport that is used to communicate with the API.
 	HTTPClient *http.Client
@@ -74,9 +101,13 @@ type Client struct {
 	UserAgent string
 
 	// API Version used by the controller, set after a http request.
+	//
+	// Deprecated: reading this field races with requests in flight; use [Client.ServerAPIVersion].
 	ControllerAPIVersion string
 
 	// Version of the drycc controller in use, set after a http request.
+	//
+	// Deprecated: reading this field races with requests in flight; use [Client.ServerVersion].
 	ControllerVersion string
 
 	// Token is used to authenticate the request against the API.
@@ -86,6 +117,27 @@ type Client struct {
 	// The hooks resource isn't intended to be used by users, so it requires
 	// a service token rather than a user token.
 	ServiceKey string
+
+	// Retry is the policy used to retry requests that fail with a transient error.
+	// If nil, requests are sent exactly once.
+	Retry *RetryPolicy
+
+	// Interceptors wrap every request sent by the client, in order. See [Interceptor].
+	Interceptors []Interceptor
+
+	// APIMismatchPolicy determines what happens when the controller API version
+	// is not compatible with the SDK. See [MismatchPolicy].
+	APIMismatchPolicy MismatchPolicy
+
+	// OnAPIMismatch is called by the MismatchWarn policy the first time an incompatible
+	// controller API version is received.
+	OnAPIMismatch func(serverAPIVersion, clientAPIVersion string)
+
+	// mu guards the controller versions, which are updated by every response.
+	mu sync.RWMutex
+
+	mismatchWarned   bool
+	warnedAPIVersion string
 }
 
 // APIVersion is the api version compatible with the SDK.
@@ -95,7 +147,8 @@ type Client struct {
 // compatible. However, using a SDK that is newer or a major version different than the
 // controller is unsafe.
 //
-// If the SDK detects an API version mismatch, it will return ErrAPIMismatch.
+// If the SDK detects an API version mismatch, it will return ErrAPIMismatch, unless the
+// client's APIMismatchPolicy says otherwise.
 const APIVersion = "2.3"
 
 var (
@@ -106,9 +159,18 @@ var (
 	DefaultUserAgent = fmt.Sprintf("Drycc Go SDK V%s", APIVersion)
 )
 
+type noRedirectKey struct{}
+
+// WithoutRedirect returns a copy of ctx that makes requests sent with it return
+// redirect responses to the caller instead of following them. Unlike setting
+// CheckRedirect on the client's HTTPClient, this only affects the requests using ctx.
+func WithoutRedirect(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRedirectKey{}, true)
+}
+
 // IsErrAPIMismatch returns true if err is an ErrAPIMismatch, false otherwise
 func IsErrAPIMismatch(err error) bool {
-	return err == ErrAPIMismatch
+	return errors.Is(err, ErrAPIMismatch)
 }
 
 // New creates a new client to communicate with the api.
@@ -116,11 +178,7 @@ func IsErrAPIMismatch(err error) bool {
 // verifySSL determines whether or not to verify SSL connections.
 // This should be true unless you know the controller is using untrusted SSL keys.
 func New(verifySSL bool, controllerURL string, token string) (*Client, error) {
-	// preventing issues like missing schemes.
-	if !strings.HasPrefix(controllerURL, "http://") && !strings.HasPrefix(controllerURL, "https://") {
-		controllerURL = "http://" + controllerURL
-	}
-	u, err := url.Parse(controllerURL)
+	u, err := parseControllerURL(controllerURL)
 	if err != nil {
 		return nil, err
 	}
@@ -133,3 +191,11 @@ func New(verifySSL bool, controllerURL string, token string) (*Client, error) {
 		UserAgent:     DefaultUserAgent,
 	}, nil
 }
+
+func parseControllerURL(controllerURL string) (*url.URL, error) {
+	// preventing issues like missing schemes.
+	if !strings.HasPrefix(controllerURL, "http://") && !strings.HasPrefix(controllerURL, "https://") {
+		controllerURL = "http://" + controllerURL
+	}
+	return url.Parse(controllerURL)
+}
@@ -0,0 +1,792 @@
+package drycctest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/webdav"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// app holds the state of an application.
+type app struct {
+	api.App
+
+	releases    []*release
+	ptypes      collection[api.Ptype]
+	pods        collection[api.Pods]
+	podStates   map[string]api.PodState
+	ptypeStates map[string]api.PtypeStates
+	podEvents   map[string]api.AppEvents
+	ptypeEvents map[string]api.AppEvents
+	domains     collection[api.Domain]
+	certs       collection[api.Cert]
+	routes      collection[api.Route]
+	gateways    collection[api.Gateway]
+	services    collection[api.Service]
+	volumes     collection[api.Volume]
+	filers      map[string]webdav.FileSystem
+	bound       map[string]bool
+	resources   collection[api.Resource]
+}
+
+// release is a release with the build and config it was created from.
+type release struct {
+	api.Release
+	build  *api.Build
+	config api.Config
+}
+
+func (a *app) latest() *release {
+	return a.releases[len(a.releases)-1]
+}
+
+// findRelease returns the release with the given version, which is either a number or
+// a number prefixed with "v". An empty version returns the latest release.
+func (a *app) findRelease(version string) (*release, bool) {
+	if version == "" {
+		return a.latest(), true
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || n < 1 || n > len(a.releases) {
+		return nil, false
+	}
+	return a.releases[n-1], true
+}
+
+// AddApp adds an application, with an initial release. Its UUID and timestamps are
+// generated if they are empty.
+func (s *Server) AddApp(a api.App) {
+	defer s.lock()()
+	s.addApp(a)
+}
+
+func (s *Server) addApp(a api.App) *app {
+	now := s.now()
+	if a.UUID == "" {
+		a.UUID = newUUID()
+	}
+	if a.Created == "" {
+		a.Created = now
+	}
+	if a.Updated == "" {
+		a.Updated = now
+	}
+	st := &app{
+		App:         a,
+		podStates:   make(map[string]api.PodState),
+		ptypeStates: make(map[string]api.PtypeStates),
+		podEvents:   make(map[string]api.AppEvents),
+		ptypeEvents: make(map[string]api.AppEvents),
+		filers:      make(map[string]webdav.FileSystem),
+		bound:       make(map[string]bool),
+	}
+	s.apps.put(a.ID, st)
+	s.newRelease(st, fmt.Sprintf("%s created initial release", s.Username), nil, api.Config{App: a.ID})
+	return st
+}
+
+// App returns the application with the given ID.
+func (s *Server) App(appID string) (api.App, bool) {
+	defer s.lock()()
+	a, ok := s.apps.get(appID)
+	if !ok {
+		return api.App{}, false
+	}
+	return a.App, true
+}
+
+// Releases returns the releases of an application, oldest first.
+func (s *Server) Releases(appID string) []api.Release {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	releases := make([]api.Release, 0, len(a.releases))
+	for _, r := range a.releases {
+		releases = append(releases, r.Release)
+	}
+	return releases
+}
+
+// AddRelease adds a release to an application, with the build and config of the latest
+// release. Its version is set to the next version of the application, and its UUID and
+// timestamps are generated if they are empty.
+func (s *Server) AddRelease(appID string, r api.Release) api.Release {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	latest := a.latest()
+	rel := &release{Release: r, build: latest.build, config: latest.config}
+	rel.Version = len(a.releases) + 1
+	s.fillRelease(a, rel)
+	a.releases = append(a.releases, rel)
+	return rel.Release
+}
+
+// UpdateRelease calls update with a release of an application, to change its state or
+// conditions. It returns false if the release does not exist.
+func (s *Server) UpdateRelease(appID string, version int, update func(*api.Release)) bool {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	r, ok := a.findRelease(strconv.Itoa(version))
+	if !ok {
+		return false
+	}
+	update(&r.Release)
+	r.Updated = s.now()
+	return true
+}
+
+// SetBuild creates a build of an application and its release, as [builds.New] does.
+// It returns the new release.
+func (s *Server) SetBuild(appID string, b api.Build) api.Release {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	return s.createBuild(a, b, fmt.Sprintf("%s deployed %s", s.Username, b.Image)).Release
+}
+
+// SetConfig replaces the config of an application and creates a release.
+// It returns the new release.
+func (s *Server) SetConfig(appID string, c api.Config) api.Release {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	c.App = appID
+	return s.newRelease(a, fmt.Sprintf("%s changed config", s.Username), a.latest().build, c).Release
+}
+
+// Config returns the config of the latest release of an application.
+func (s *Server) Config(appID string) api.Config {
+	defer s.lock()()
+	return s.mustApp(appID).latest().config
+}
+
+// AddPtypes adds process types to an application.
+func (s *Server) AddPtypes(appID string, ptypes ...api.Ptype) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	for _, p := range ptypes {
+		a.ptypes.put(p.Name, p)
+	}
+}
+
+// UpdatePtype calls update with a process type of an application, to change its status.
+// It returns false if the process type does not exist.
+func (s *Server) UpdatePtype(appID, ptype string, update func(*api.Ptype)) bool {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	p, ok := a.ptypes.get(ptype)
+	if !ok {
+		return false
+	}
+	update(&p)
+	a.ptypes.put(ptype, p)
+	return true
+}
+
+// Ptypes returns the process types of an application.
+func (s *Server) Ptypes(appID string) api.Ptypes {
+	defer s.lock()()
+	return s.mustApp(appID).ptypes.list()
+}
+
+// SetPtypeStates sets the container states returned when describing a process type.
+func (s *Server) SetPtypeStates(appID, ptype string, states api.PtypeStates) {
+	defer s.lock()()
+	s.mustApp(appID).ptypeStates[ptype] = states
+}
+
+// AddPods adds pods to an application.
+func (s *Server) AddPods(appID string, pods ...api.Pods) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	for _, p := range pods {
+		a.pods.put(p.Name, p)
+	}
+}
+
+// UpdatePod calls update with a pod of an application, to change its status.
+// It returns false if the pod does not exist.
+func (s *Server) UpdatePod(appID, name string, update func(*api.Pods)) bool {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	p, ok := a.pods.get(name)
+	if !ok {
+		return false
+	}
+	update(&p)
+	a.pods.put(name, p)
+	return true
+}
+
+// RemovePod removes a pod from an application, without replacing it.
+func (s *Server) RemovePod(appID, name string) bool {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	delete(a.podStates, name)
+	return a.pods.delete(name)
+}
+
+// Pods returns the pods of an application.
+func (s *Server) Pods(appID string) api.PodsList {
+	defer s.lock()()
+	return s.mustApp(appID).pods.list()
+}
+
+// SetPodState sets the container states returned when describing a pod.
+func (s *Server) SetPodState(appID, pod string, state api.PodState) {
+	defer s.lock()()
+	s.mustApp(appID).podStates[pod] = state
+}
+
+// AddPodEvents adds events of a pod, returned when listing the events of the pod.
+func (s *Server) AddPodEvents(appID, pod string, events ...api.AppEvent) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	a.podEvents[pod] = append(a.podEvents[pod], events...)
+}
+
+// AddPtypeEvents adds events of a process type, returned when listing the events of the
+// process type.
+func (s *Server) AddPtypeEvents(appID, ptype string, events ...api.AppEvent) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	a.ptypeEvents[ptype] = append(a.ptypeEvents[ptype], events...)
+}
+
+func (s *Server) mustApp(appID string) *app {
+	a, ok := s.apps.get(appID)
+	if !ok {
+		panic(fmt.Sprintf("drycctest: app %s does not exist", appID))
+	}
+	return a
+}
+
+// newRelease creates a release of an application and rolls its pods out.
+func (s *Server) newRelease(a *app, summary string, build *api.Build, config api.Config) *release {
+	r := &release{
+		Release: api.Release{
+			App:     a.ID,
+			State:   "succeed",
+			Summary: summary,
+			Version: len(a.releases) + 1,
+		},
+		build:  build,
+		config: config,
+	}
+	s.fillRelease(a, r)
+	a.releases = append(a.releases, r)
+	if len(a.releases) > 1 {
+		s.rollout(a, nil)
+	}
+	return r
+}
+
+func (s *Server) fillRelease(a *app, r *release) {
+	now := s.now()
+	r.App = a.ID
+	if r.UUID == "" {
+		r.UUID = newUUID()
+	}
+	if r.Created == "" {
+		r.Created = now
+	}
+	if r.Updated == "" {
+		r.Updated = now
+	}
+	if r.Config == "" {
+		if r.config.UUID == "" {
+			r.config.UUID = newUUID()
+		}
+		r.Config = r.config.UUID
+	}
+	if r.Build == "" && r.build != nil {
+		r.Build = r.build.UUID
+	}
+	if r.Conditions == nil {
+		r.Conditions = []api.Condition{}
+	}
+}
+
+func (s *Server) createBuild(a *app, b api.Build, summary string) *release {
+	now := s.now()
+	b.App = a.ID
+	b.UUID = newUUID()
+	b.Created, b.Updated = now, now
+	first := a.latest().build == nil
+
+	r := s.newRelease(a, summary, &b, a.latest().config)
+	// The first build scales the web process to one replica, as the controller does.
+	if _, ok := a.ptypes.get("web"); first && !ok && (len(b.Procfile) == 0 || b.Procfile["web"] != "") {
+		s.scale(a, "web", 1)
+	}
+	return r
+}
+
+// scale sets the number of replicas of a process type, creating or removing pods.
+func (s *Server) scale(a *app, ptype string, replicas int) {
+	version := fmt.Sprintf("v%d", a.latest().Version)
+	p, ok := a.ptypes.get(ptype)
+	if !ok {
+		p = api.Ptype{Name: ptype, Started: s.now()}
+	}
+	p.Release = version
+	p.Ready = fmt.Sprintf("%d/%d", replicas, replicas)
+	p.UpToDate, p.AvailableReplicas = replicas, replicas
+	a.ptypes.put(ptype, p)
+
+	var current []string
+	for _, pod := range a.pods.list() {
+		if pod.Type == ptype {
+			current = append(current, pod.Name)
+		}
+	}
+	for _, name := range current[min(replicas, len(current)):] {
+		a.pods.delete(name)
+		delete(a.podStates, name)
+	}
+	for range replicas - len(current) {
+		s.addPod(a, ptype)
+	}
+}
+
+func (s *Server) addPod(a *app, ptype string) {
+	name := fmt.Sprintf("%s-%s-%d", a.ID, ptype, s.nextSerial())
+	a.pods.put(name, api.Pods{
+		Release: fmt.Sprintf("v%d", a.latest().Version),
+		Type:    ptype,
+		Name:    name,
+		State:   "up",
+		Ready:   "1/1",
+		Started: s.now(),
+	})
+}
+
+// rollout replaces the pods of the given process types, or of all of them if ptypes
+// is empty, with pods of the latest release.
+func (s *Server) rollout(a *app, ptypes []string) {
+	version := fmt.Sprintf("v%d", a.latest().Version)
+	for _, pod := range a.pods.list() {
+		if len(ptypes) > 0 && !slices.Contains(ptypes, pod.Type) {
+			continue
+		}
+		a.pods.delete(pod.Name)
+		delete(a.podStates, pod.Name)
+		s.addPod(a, pod.Type)
+	}
+	for _, p := range a.ptypes.list() {
+		if len(ptypes) == 0 || slices.Contains(ptypes, p.Name) {
+			p.Release = version
+			a.ptypes.put(p.Name, p)
+		}
+	}
+}
+
+// appFor returns the application named in the request path, or writes a 404 response.
+func (s *Server) appFor(w http.ResponseWriter, r *http.Request) (*app, bool) {
+	a, ok := s.apps.get(r.PathValue("id"))
+	if !ok {
+		notFound(w)
+	}
+	return a, ok
+}
+
+func (s *Server) listApps(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	apps := make(api.Apps, 0, s.apps.len())
+	for _, a := range s.apps.list() {
+		apps = append(apps, a.App)
+	}
+	s.writePage(w, r, apps)
+}
+
+func (s *Server) createApp(w http.ResponseWriter, r *http.Request) {
+	var req api.AppCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("app-%d", s.nextSerial())
+	}
+	if !validName.MatchString(req.ID) {
+		writeError(w, http.StatusBadRequest, FieldError("id", "App name can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if _, ok := s.apps.get(req.ID); ok {
+		writeError(w, http.StatusBadRequest, FieldError("id", "Application with this id already exists."))
+		return
+	}
+	a := s.addApp(api.App{ID: req.ID, Workspace: req.Workspace})
+	writeJSON(w, http.StatusCreated, a.App)
+}
+
+func (s *Server) getApp(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		writeJSON(w, http.StatusOK, a.App)
+	}
+}
+
+func (s *Server) updateApp(w http.ResponseWriter, r *http.Request) {
+	var req api.AppUpdateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		a.Workspace = req.Workspace
+		a.Updated = s.now()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) deleteApp(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if !s.apps.delete(r.PathValue("id")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) runApp(w http.ResponseWriter, r *http.Request) {
+	var req api.AppRunRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	if _, ok := s.appFor(w, r); !ok {
+		return
+	}
+	if req.Command == "" {
+		writeError(w, http.StatusBadRequest, FieldError("command", "This field may not be blank."))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getBuild(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	rel, ok := a.findRelease(r.URL.Query().Get("version"))
+	if !ok || rel.build == nil {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, rel.build)
+}
+
+func (s *Server) createBuildHandler(w http.ResponseWriter, r *http.Request) {
+	var req api.CreateBuildRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Image == "" {
+		writeError(w, http.StatusBadRequest, FieldError("image", "This field may not be blank."))
+		return
+	}
+	build := api.Build{Image: req.Image, Stack: req.Stack, Procfile: req.Procfile, Dryccfile: req.Dryccfile}
+	rel := s.createBuild(a, build, fmt.Sprintf("%s deployed %s", s.Username, req.Image))
+	writeJSON(w, http.StatusCreated, rel.build)
+}
+
+func (s *Server) listReleases(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	var ptypes []string
+	if v := r.URL.Query().Get("ptypes"); v != "" {
+		ptypes = strings.Split(v, ",")
+	}
+	releases := []api.Release{}
+	// The controller lists the newest releases first.
+	for _, rel := range slices.Backward(a.releases) {
+		if len(ptypes) == 0 || len(rel.Conditions) == 0 || slices.ContainsFunc(rel.Conditions, func(c api.Condition) bool {
+			return slices.ContainsFunc(c.Ptypes, func(p string) bool { return slices.Contains(ptypes, p) })
+		}) {
+			releases = append(releases, rel.Release)
+		}
+	}
+	s.writePage(w, r, releases)
+}
+
+func (s *Server) getRelease(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	rel, ok := a.findRelease(r.PathValue("version"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, rel.Release)
+}
+
+func (s *Server) deployRelease(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	ptypes, ok := s.checkPtypes(w, a, req["types"])
+	if !ok {
+		return
+	}
+	latest := a.latest()
+	latest.Conditions = append(latest.Conditions, api.Condition{
+		State: "succeed", Action: "deploy", Ptypes: ptypes, Created: s.now(),
+	})
+	s.rollout(a, ptypes)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) rollbackRelease(w http.ResponseWriter, r *http.Request) {
+	var req api.ReleaseRollback
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Version < 0 {
+		writeError(w, http.StatusBadRequest, DetailError("version cannot be below 0"))
+		return
+	}
+	if req.Version == 0 {
+		req.Version = a.latest().Version - 1
+	}
+	target, ok := a.findRelease(strconv.Itoa(req.Version))
+	if !ok {
+		notFound(w)
+		return
+	}
+	rel := s.newRelease(a, fmt.Sprintf("%s rolled back to v%d", s.Username, target.Version), target.build, target.config)
+	writeJSON(w, http.StatusCreated, api.ReleaseRollback{Version: rel.Version})
+}
+
+func (s *Server) listPtypes(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.ptypes.list())
+	}
+}
+
+func (s *Server) describePtype(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	name := strings.TrimPrefix(r.PathValue("name"), a.ID+"-")
+	if _, ok := a.ptypes.get(name); !ok {
+		notFound(w)
+		return
+	}
+	states, ok := a.ptypeStates[name]
+	if !ok {
+		states = api.PtypeStates{{Container: name, Image: a.image()}}
+	}
+	s.writePage(w, r, states)
+}
+
+func (s *Server) scalePtypes(w http.ResponseWriter, r *http.Request) {
+	var req map[string]int
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if len(req) == 0 {
+		writeError(w, http.StatusBadRequest, DetailError("Invalid scaling format"))
+		return
+	}
+	procfile := map[string]string{}
+	if b := a.latest().build; b != nil {
+		procfile = b.Procfile
+	}
+	for ptype, replicas := range req {
+		if replicas < 0 {
+			writeError(w, http.StatusBadRequest, DetailError("Must scale to a value greater than or equal to zero"))
+			return
+		}
+		if _, ok := procfile[ptype]; len(procfile) > 0 && !ok {
+			writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("Container type %s does not exist in application", ptype)))
+			return
+		}
+	}
+	for _, ptype := range slices.Sorted(maps.Keys(req)) {
+		s.scale(a, ptype, req[ptype])
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) restartPtypes(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if ptypes, ok := s.checkPtypes(w, a, req["types"]); ok {
+		s.rollout(a, ptypes)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) cleanPtypes(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	ptypes, ok := s.checkPtypes(w, a, req["types"])
+	if !ok {
+		return
+	}
+	for _, ptype := range ptypes {
+		a.ptypes.delete(ptype)
+		for _, pod := range a.pods.list() {
+			if pod.Type == ptype {
+				a.pods.delete(pod.Name)
+				delete(a.podStates, pod.Name)
+			}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkPtypes parses a comma separated list of process types sent in a request and checks
+// they exist, or writes a 400 response.
+func (s *Server) checkPtypes(w http.ResponseWriter, a *app, types any) ([]string, bool) {
+	value, _ := types.(string)
+	if value == "" {
+		return nil, true
+	}
+	ptypes := strings.Split(value, ",")
+	for _, ptype := range ptypes {
+		if _, ok := a.ptypes.get(ptype); !ok {
+			writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("Container type %s does not exist in application", ptype)))
+			return nil, false
+		}
+	}
+	return ptypes, true
+}
+
+func (s *Server) listPods(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.pods.list())
+	}
+}
+
+func (s *Server) deletePods(w http.ResponseWriter, r *http.Request) {
+	var req api.PodIDs
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	names := strings.Split(req.PodIDs, ",")
+	for _, name := range names {
+		if _, ok := a.pods.get(name); !ok {
+			writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("Pod %s does not exist in application", name)))
+			return
+		}
+	}
+	// Deleted pods are replaced, as they are by their deployment.
+	for _, name := range names {
+		pod, _ := a.pods.get(name)
+		a.pods.delete(name)
+		delete(a.podStates, name)
+		s.addPod(a, pod.Type)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) describePod(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	pod, ok := a.pods.get(r.PathValue("pod"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	state, ok := a.podStates[pod.Name]
+	if !ok {
+		state = api.PodState{{
+			Container:    pod.Type,
+			Image:        a.image(),
+			State:        map[string]map[string]any{"running": {"startedAt": pod.Started}},
+			Ready:        true,
+			RestartCount: pod.Restarts,
+		}}
+	}
+	s.writePage(w, r, state)
+}
+
+// listEvents lists the events of a pod or of a process type, selected by the pod_name or
+// the ptype query parameter. Process types are named after the application.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	events := api.AppEvents{}
+	switch {
+	case query.Has("pod_name"):
+		events = append(events, a.podEvents[query.Get("pod_name")]...)
+	case query.Has("ptype"):
+		ptype := strings.TrimPrefix(query.Get("ptype"), a.ID+"-")
+		events = append(events, a.ptypeEvents[ptype]...)
+	}
+	s.writePage(w, r, events)
+}
+
+// image returns the image of the latest build of the application.
+func (a *app) image() string {
+	if b := a.latest().build; b != nil {
+		return b.Image
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+package drycctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// configMaps are the fields of a config mapping a process type to a value. A null value
+// unsets the process type.
+var configMaps = []string{"limits", "termination_grace_period", "lifecycle", "healthcheck"}
+
+// configNestedMaps are the fields of a config mapping a process type to a map, in which a
+// null value unsets a key.
+var configNestedMaps = []string{"tags", "registry"}
+
+func (s *Server) getConfig(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	rel, ok := a.findRelease(r.URL.Query().Get("version"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, rel.config)
+}
+
+func (s *Server) setConfig(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, DetailError(err.Error()))
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(body, &patch); err != nil {
+		writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("JSON parse error - %s", err)))
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	config, status, detail := mergeConfig(a.latest().config, patch, r.URL.Query().Get("merge") != "false")
+	if status != 0 {
+		writeError(w, status, DetailError(detail))
+		return
+	}
+	config.App, config.UUID = a.ID, newUUID()
+	config.Created, config.Updated = s.now(), s.now()
+	s.newRelease(a, fmt.Sprintf("%s changed config", s.Username), a.latest().build, config)
+	writeJSON(w, http.StatusCreated, config)
+}
+
+func (s *Server) detachConfig(w http.ResponseWriter, r *http.Request) {
+	var req api.Config
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	config, err := copyConfig(a.latest().config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, DetailError(err.Error()))
+		return
+	}
+	for ptype, groups := range req.ValuesRefs {
+		config.ValuesRefs[ptype] = slices.DeleteFunc(config.ValuesRefs[ptype], func(g string) bool {
+			return slices.Contains(groups, g)
+		})
+		if len(config.ValuesRefs[ptype]) == 0 {
+			delete(config.ValuesRefs, ptype)
+		}
+	}
+	config.UUID, config.Updated = newUUID(), s.now()
+	s.newRelease(a, fmt.Sprintf("%s changed config", s.Username), a.latest().build, config)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mergeConfig applies a config sent by a client to the current config, as the controller
+// does. Values are replaced rather than merged if merge is false. It returns the status code
+// and detail of the error response if the patch is invalid.
+func mergeConfig(current api.Config, patch map[string]json.RawMessage, merge bool) (api.Config, int, string) {
+	var doc map[string]any
+	if err := remarshal(current, &doc); err != nil {
+		return api.Config{}, http.StatusInternalServerError, err.Error()
+	}
+	changed := false
+
+	if raw, ok := patch["values"]; ok {
+		var values []api.ConfigValue
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return api.Config{}, http.StatusBadRequest, err.Error()
+		}
+		merged := slices.Clone(current.Values)
+		if !merge {
+			merged = nil
+		}
+		for _, v := range values {
+			i := slices.IndexFunc(merged, func(c api.ConfigValue) bool {
+				return c.Ptype == v.Ptype && c.Group == v.Group && c.Name == v.Name
+			})
+			switch {
+			case v.Value == nil && i < 0:
+				return api.Config{}, http.StatusUnprocessableEntity, fmt.Sprintf("%s does not exist under values", v.Name)
+			case v.Value == nil:
+				merged = slices.Delete(merged, i, i+1)
+			case i < 0:
+				merged = append(merged, v)
+			default:
+				merged[i] = v
+			}
+		}
+		doc["values"] = merged
+		changed = true
+	}
+
+	if raw, ok := patch["values_refs"]; ok {
+		var refs api.ValuesRefs
+		if err := json.Unmarshal(raw, &refs); err != nil {
+			return api.Config{}, http.StatusBadRequest, err.Error()
+		}
+		merged := api.ValuesRefs{}
+		for ptype, groups := range current.ValuesRefs {
+			merged[ptype] = slices.Clone(groups)
+		}
+		for ptype, groups := range refs {
+			for _, g := range groups {
+				if !slices.Contains(merged[ptype], g) {
+					merged[ptype] = append(merged[ptype], g)
+				}
+			}
+		}
+		doc["values_refs"] = merged
+		changed = true
+	}
+
+	for _, field := range append(slices.Clone(configMaps), configNestedMaps...) {
+		raw, ok := patch[field]
+		if !ok {
+			continue
+		}
+		var values map[string]any
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return api.Config{}, http.StatusBadRequest, err.Error()
+		}
+		target, _ := doc[field].(map[string]any)
+		if target == nil {
+			target = map[string]any{}
+		}
+		nested := slices.Contains(configNestedMaps, field)
+		for key, value := range values {
+			existing, exists := target[key]
+			switch {
+			case value == nil && !exists:
+				return api.Config{}, http.StatusUnprocessableEntity, fmt.Sprintf("%s does not exist under %s", key, field)
+			case value == nil:
+				delete(target, key)
+			case nested:
+				inner, _ := existing.(map[string]any)
+				if inner == nil {
+					inner = map[string]any{}
+				}
+				values, _ := value.(map[string]any)
+				for k, v := range values {
+					if v == nil {
+						delete(inner, k)
+					} else {
+						inner[k] = v
+					}
+				}
+				target[key] = inner
+			default:
+				target[key] = value
+			}
+		}
+		doc[field] = target
+		changed = true
+	}
+
+	if !changed {
+		return api.Config{}, http.StatusConflict, "Config is unchanged."
+	}
+	var config api.Config
+	if err := remarshal(doc, &config); err != nil {
+		return api.Config{}, http.StatusBadRequest, err.Error()
+	}
+	return config, 0, ""
+}
+
+// copyConfig returns a deep copy of a config.
+func copyConfig(c api.Config) (api.Config, error) {
+	var config api.Config
+	if err := remarshal(c, &config); err != nil {
+		return api.Config{}, err
+	}
+	if config.ValuesRefs == nil {
+		config.ValuesRefs = api.ValuesRefs{}
+	}
+	return config, nil
+}
+
+// remarshal converts from to to through JSON.
+func remarshal(from, to any) error {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, to)
+}
@@ -0,0 +1,405 @@
+// Package drycctest provides an in-memory Drycc controller for testing code built on the SDK.
+//
+// A [Server] serves the v2 endpoints used by the SDK packages from state held in memory,
+// so tests can exercise the SDK end to end without hand-rolling a fake HTTP server:
+//
+//	srv := drycctest.NewServer()
+//	defer srv.Close()
+//	srv.AddApp(api.App{ID: "example-go"})
+//
+//	client := srv.Client()
+//	if err := pts.Scale(client, "example-go", map[string]int{"web": 2}); err != nil {
+//	    t.Fatal(err)
+//	}
+//	pods, _, err := ps.List(client, "example-go", 100)
+//
+// List endpoints are paginated with the limit and offset query parameters like the
+// controller, and errors are sent in the shapes the SDK understands, so the predefined
+// SDK errors such as drycc.ErrDuplicateApp are returned. Failures can be injected with
+// [Server.Fail] to exercise error paths and retries.
+package drycctest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// TimeFormat is the format of the created and updated timestamps set by the server.
+const TimeFormat = "2006-01-02T15:04:05MST"
+
+// Server is an in-memory Drycc controller served over HTTP. Its methods are safe for
+// concurrent use; its fields must be set before it's used.
+type Server struct {
+	*httptest.Server
+
+	// Token, if set, is the only token accepted in the Authorization header.
+	Token string
+	// ServiceKey, if set, is the only service key accepted by the hooks endpoints.
+	ServiceKey string
+	// Username is the user the server acts on behalf of, for example as the owner of keys.
+	Username string
+
+	mu       sync.Mutex
+	mux      *http.ServeMux
+	failures []*failure
+	requests []Request
+	serial   int
+
+	apiVersion      string
+	platformVersion string
+
+	execHandler    ExecHandler
+	logsHandler    LogsHandler
+	appLogsHandler AppLogsHandler
+
+	apps         collection[*app]
+	workspaces   collection[*workspace]
+	tokens       collection[api.Token]
+	keys         collection[api.Key]
+	fingerprints map[string]string
+	services     collection[*resourceService]
+}
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+// NewServer starts and returns a new server with no state. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		apiVersion:      drycc.APIVersion,
+		platformVersion: "test",
+		Username:        "test",
+		mux:             http.NewServeMux(),
+		fingerprints:    make(map[string]string),
+	}
+	s.routes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client for the server, authenticated with the server's token
+// and service key.
+func (s *Server) Client() *drycc.Client {
+	token := s.Token
+	if token == "" {
+		token = "drycctest"
+	}
+	c, err := drycc.New(false, s.URL, token)
+	if err != nil {
+		panic(err)
+	}
+	c.ServiceKey = s.ServiceKey
+	return c
+}
+
+// SetAPIVersion sets the version sent in the DRYCC_API_VERSION header of the responses,
+// such as to test a controller older than the SDK. It defaults to drycc.APIVersion.
+func (s *Server) SetAPIVersion(version string) {
+	defer s.lock()()
+	s.apiVersion = version
+}
+
+// SetPlatformVersion sets the version sent in the DRYCC_PLATFORM_VERSION header of the
+// responses. It defaults to "test".
+func (s *Server) SetPlatformVersion(version string) {
+	defer s.lock()()
+	s.platformVersion = version
+}
+
+// Requests returns the requests received by the server, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Failure describes an error response sent instead of serving a request.
+type Failure struct {
+	// Method is the method of the failing requests. An empty method matches all methods.
+	Method string
+	// Path is the path of the failing requests. A path ending with "*" matches every
+	// path starting with the rest of it.
+	Path string
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Body is the body of the response. See [FieldError] and [DetailError] for the
+	// shapes of the controller's errors.
+	Body string
+	// Header holds additional response headers, such as Retry-After.
+	Header http.Header
+	// Times is the number of requests that fail. Zero means all of them.
+	Times int
+}
+
+type failure struct {
+	Failure
+	remaining int
+}
+
+// Fail makes the requests matching f fail. The failures are checked in the order they
+// were added, and a failure is removed once it has been sent f.Times times.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, &failure{Failure: f, remaining: f.Times})
+}
+
+// ClearFailures removes every failure added with [Server.Fail].
+func (s *Server) ClearFailures() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+}
+
+// FieldError returns the body of a validation error on the given field, as sent by the
+// controller with a 400 status code.
+func FieldError(field string, messages ...string) string {
+	body, _ := json.Marshal(map[string][]string{field: messages})
+	return string(body)
+}
+
+// DetailError returns the body of an error with a detail message, as sent by the controller
+// with 400, 403, 404 and 422 status codes.
+func DetailError(detail string) string {
+	body, _ := json.Marshal(map[string]string{"detail": detail})
+	return string(body)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	w.Header().Set("DRYCC_API_VERSION", s.apiVersion)
+	w.Header().Set("DRYCC_PLATFORM_VERSION", s.platformVersion)
+	s.mu.Unlock()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, DetailError(err.Error()))
+		return
+	}
+	r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+	f := s.matchFailure(r)
+	s.mu.Unlock()
+
+	if f != nil {
+		for key, values := range f.Header {
+			w.Header()[key] = values
+		}
+		writeError(w, f.StatusCode, f.Body)
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, DetailError("Invalid token."))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) matchFailure(r *http.Request) *Failure {
+	for i, f := range s.failures {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(f.Path, "*"); ok {
+			if !strings.HasPrefix(r.URL.Path, prefix) {
+				continue
+			}
+		} else if f.Path != r.URL.Path {
+			continue
+		}
+		if f.Times > 0 {
+			f.remaining--
+			if f.remaining == 0 {
+				s.failures = slices.Delete(s.failures, i, i+1)
+			}
+		}
+		return &f.Failure
+	}
+	return nil
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	switch {
+	case r.URL.Path == "/v2/" || r.URL.Path == "/healthz":
+		return true
+	case strings.HasPrefix(r.URL.Path, "/v2/hooks/"):
+		return s.ServiceKey == "" || r.Header.Get("X-Drycc-Service-Key") == s.ServiceKey
+	default:
+		return s.Token == "" || r.Header.Get("Authorization") == "token "+s.Token
+	}
+}
+
+// lock acquires the server lock for the duration of a handler.
+// It returns the function releasing it.
+func (s *Server) lock() func() {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *Server) now() string {
+	return time.Now().UTC().Format(TimeFormat)
+}
+
+// nextSerial returns a number unique to the server, used to name generated objects.
+func (s *Server) nextSerial() int {
+	s.serial++
+	return s.serial
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// collection is an ordered set of objects indexed by key.
+type collection[T any] struct {
+	keys  []string
+	items map[string]T
+}
+
+func (c *collection[T]) get(key string) (T, bool) {
+	item, ok := c.items[key]
+	return item, ok
+}
+
+func (c *collection[T]) put(key string, item T) {
+	if c.items == nil {
+		c.items = make(map[string]T)
+	}
+	if _, ok := c.items[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.items[key] = item
+}
+
+func (c *collection[T]) delete(key string) bool {
+	if _, ok := c.items[key]; !ok {
+		return false
+	}
+	delete(c.items, key)
+	c.keys = slices.DeleteFunc(c.keys, func(k string) bool { return k == key })
+	return true
+}
+
+func (c *collection[T]) len() int {
+	return len(c.keys)
+}
+
+func (c *collection[T]) list() []T {
+	items := make([]T, 0, len(c.keys))
+	for _, key := range c.keys {
+		items = append(items, c.items[key])
+	}
+	return items
+}
+
+// writePage writes a page of items selected by the limit and offset query parameters,
+// with links to the next and previous pages.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items any) {
+	all, err := toSlice(items)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, DetailError(err.Error()))
+		return
+	}
+	query := r.URL.Query()
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = drycc.DefaultPageSize
+	}
+	offset, err := strconv.Atoi(query.Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	offset = min(offset, len(all))
+	end := min(offset+limit, len(all))
+
+	link := func(offset int) *string {
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		if offset > 0 {
+			q.Set("offset", strconv.Itoa(offset))
+		} else {
+			q.Del("offset")
+		}
+		u := s.URL + r.URL.Path + "?" + q.Encode()
+		return &u
+	}
+	page := struct {
+		Count    int     `json:"count"`
+		Next     *string `json:"next"`
+		Previous *string `json:"previous"`
+		Results  []any   `json:"results"`
+	}{Count: len(all), Results: all[offset:end]}
+	if end < len(all) {
+		page.Next = link(end)
+	}
+	if offset > 0 {
+		page.Previous = link(max(offset-limit, 0))
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// toSlice converts a slice of any type to a slice of its JSON values.
+func toSlice(items any) ([]any, error) {
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	all := []any{}
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, body string) {
+	if json.Valid([]byte(body)) {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(statusCode)
+	io.WriteString(w, body)
+}
+
+// readJSON decodes the request body into v, writing a 400 response if it is invalid.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("JSON parse error - %s", err)))
+		return false
+	}
+	return true
+}
+
+func notFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, DetailError("Not found."))
+}
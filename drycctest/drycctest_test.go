@@ -0,0 +1,524 @@
+package drycctest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/apps"
+	"github.com/drycc/controller-sdk-go/builds"
+	"github.com/drycc/controller-sdk-go/certs"
+	"github.com/drycc/controller-sdk-go/config"
+	"github.com/drycc/controller-sdk-go/domains"
+	"github.com/drycc/controller-sdk-go/events"
+	"github.com/drycc/controller-sdk-go/gateways"
+	"github.com/drycc/controller-sdk-go/hooks"
+	"github.com/drycc/controller-sdk-go/keys"
+	"github.com/drycc/controller-sdk-go/ps"
+	"github.com/drycc/controller-sdk-go/pts"
+	"github.com/drycc/controller-sdk-go/releases"
+	"github.com/drycc/controller-sdk-go/resources"
+	"github.com/drycc/controller-sdk-go/routes"
+	"github.com/drycc/controller-sdk-go/services"
+	"github.com/drycc/controller-sdk-go/tokens"
+	"github.com/drycc/controller-sdk-go/volumes"
+	"github.com/drycc/controller-sdk-go/workspaces"
+	"github.com/drycc/controller-sdk-go/workspaces/members"
+	"golang.org/x/net/websocket"
+)
+
+func TestApps(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	app, err := apps.New(client, "example-go", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.ID != "example-go" || app.Workspace != "test" || app.UUID == "" {
+		t.Errorf("Expected a created app, Got %v", app)
+	}
+
+	if _, err := apps.New(client, "example-go", "test"); !errors.Is(err, drycc.ErrDuplicateApp) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrDuplicateApp, err)
+	}
+	if _, err := apps.New(client, "Example_Go", "test"); !errors.Is(err, drycc.ErrInvalidAppName) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrInvalidAppName, err)
+	}
+	if _, err := apps.Get(client, "missing"); !errors.As(err, &drycc.ErrNotFound{}) {
+		t.Errorf("Expected a not found error, Got %v", err)
+	}
+
+	if err := apps.Transfer(client, "example-go", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if app, _ := srv.App("example-go"); app.Workspace != "other" {
+		t.Errorf("Expected %v, Got %v", "other", app.Workspace)
+	}
+
+	if err := apps.Delete(client, "example-go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.App("example-go"); ok {
+		t.Error("Expected the app to be deleted")
+	}
+}
+
+func TestPagination(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	for _, id := range []string{"app-a", "app-b", "app-c", "app-d", "app-e"} {
+		srv.AddApp(api.App{ID: id})
+	}
+	client := srv.Client()
+
+	page, count, err := apps.List(client, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 || len(page) != 2 || page[0].ID != "app-a" {
+		t.Errorf("Expected 2 of 5 apps, Got %d of %d", len(page), count)
+	}
+
+	p, err := drycc.GetPage[api.App](t.Context(), client, "/v2/apps/", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for {
+		for _, app := range p.Results {
+			ids = append(ids, app.ID)
+		}
+		if !p.HasNext() {
+			break
+		}
+		if p, err = drycc.NextPage(t.Context(), client, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"app-a", "app-b", "app-c", "app-d", "app-e"}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Errorf("Expected %v, Got %v", expected, ids)
+	}
+	if !p.HasPrevious() {
+		t.Error("Expected the last page to have a previous page")
+	}
+}
+
+func TestConfigAndReleases(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	client := srv.Client()
+
+	build, err := builds.New(client, "example-go", "drycc/example-go:latest", "container",
+		map[string]string{"web": "./server", "worker": "./worker"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if build.Image != "drycc/example-go:latest" {
+		t.Errorf("Expected %v, Got %v", "drycc/example-go:latest", build.Image)
+	}
+
+	set := api.Config{
+		Values: []api.ConfigValue{{Group: "global", ConfigVar: api.ConfigVar{Name: "FOO", Value: "bar"}}},
+		Limits: map[string]any{"web": "std1.large.c1m1"},
+		Tags:   map[string]api.ConfigTags{"web": {"disk": "ssd"}},
+	}
+	if _, err := config.Set(client, "example-go", set, true); err != nil {
+		t.Fatal(err)
+	}
+	unset := api.Config{
+		Values: []api.ConfigValue{{Group: "global", ConfigVar: api.ConfigVar{Name: "FOO", Value: nil}}},
+		Tags:   map[string]api.ConfigTags{"web": {"disk": nil}},
+	}
+	cfg, err := config.Set(client, "example-go", unset, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Values) != 0 || cfg.Limits["web"] != "std1.large.c1m1" || len(cfg.Tags["web"]) != 0 {
+		t.Errorf("Expected FOO and the disk tag to be unset, Got %v", cfg)
+	}
+	if _, err := config.Set(client, "example-go", unset, true); !errors.As(err, &drycc.ErrUnprocessable{}) {
+		t.Errorf("Expected an unprocessable error, Got %v", err)
+	}
+	if _, err := config.Set(client, "example-go", api.Config{}, true); !errors.Is(err, drycc.ErrConflict) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrConflict, err)
+	}
+
+	// v1 is the initial release, v2 the build and v3 and v4 the config changes.
+	old, err := config.List(client, "example-go", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(old.Values) != 1 || old.Values[0].Value != "bar" {
+		t.Errorf("Expected the config of v3 to set FOO, Got %v", old.Values)
+	}
+	if _, err := builds.Get(client, "example-go", 1); !errors.As(err, &drycc.ErrNotFound{}) {
+		t.Errorf("Expected no build in the initial release, Got %v", err)
+	}
+
+	version, err := releases.Rollback(client, "example-go", "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 5 {
+		t.Errorf("Expected %v, Got %v", 5, version)
+	}
+	latest, err := config.List(client, "example-go", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(old.Values, latest.Values) {
+		t.Errorf("Expected %v, Got %v", old.Values, latest.Values)
+	}
+
+	list, count, err := releases.List(client, "example-go", "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 || list[0].Version != 5 {
+		t.Errorf("Expected the 5 releases newest first, Got %d releases starting at v%d", count, list[0].Version)
+	}
+	if _, err := releases.Rollback(client, "example-go", "", -2); !errors.Is(err, drycc.ErrInvalidVersion) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrInvalidVersion, err)
+	}
+}
+
+func TestPtypesAndPods(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.SetBuild("example-go", api.Build{Image: "example", Procfile: map[string]string{"web": "./server", "worker": "./worker"}})
+	client := srv.Client()
+
+	if err := pts.Scale(client, "example-go", map[string]int{"web": 3, "worker": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pts.Scale(client, "example-go", map[string]int{"task": 1}); !errors.Is(err, drycc.ErrPodNotFound) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrPodNotFound, err)
+	}
+
+	ptypes, _, err := pts.List(client, "example-go", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptypes) != 2 || ptypes[0].Name != "web" || ptypes[0].AvailableReplicas != 3 {
+		t.Errorf("Expected web and worker ptypes, Got %v", ptypes)
+	}
+
+	pods, count, err := ps.List(client, "example-go", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Fatalf("Expected %v, Got %v", 4, count)
+	}
+
+	// Deleted pods are replaced by new ones.
+	if err := ps.Delete(client, "example-go", pods[0].Name); err != nil {
+		t.Fatal(err)
+	}
+	after := srv.Pods("example-go")
+	if len(after) != 4 || after[len(after)-1].Name == pods[0].Name || after[len(after)-1].Type != pods[0].Type {
+		t.Errorf("Expected %s to be replaced, Got %v", pods[0].Name, after)
+	}
+
+	state, _, err := ps.Describe(client, "example-go", after[0].Name, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state) != 1 || state[0].Image != "example" || !state[0].Ready {
+		t.Errorf("Expected a ready container, Got %v", state)
+	}
+
+	if err := pts.Restart(client, "example-go", map[string]string{"types": "worker"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pts.Scale(client, "example-go", map[string]int{"web": 0}); err != nil {
+		t.Fatal(err)
+	}
+	if pods := srv.Pods("example-go"); len(pods) != 1 || pods[0].Type != "worker" {
+		t.Errorf("Expected a single worker pod, Got %v", pods)
+	}
+
+	srv.AddPtypeEvents("example-go", "web", api.AppEvent{Reason: "ScalingReplicaSet", Message: "Scaled down"})
+	srv.AddPodEvents("example-go", after[0].Name, api.AppEvent{Reason: "Killing", Message: "Stopping container"})
+	ptypeEvents, _, err := events.ListPtypeEvents(client, "example-go", "web", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ptypeEvents) != 1 || ptypeEvents[0].Reason != "ScalingReplicaSet" {
+		t.Errorf("Expected the events of the ptype, Got %v", ptypeEvents)
+	}
+	podEvents, _, err := events.ListPodEvents(client, "example-go", after[0].Name, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(podEvents) != 1 || podEvents[0].Reason != "Killing" {
+		t.Errorf("Expected the events of the pod, Got %v", podEvents)
+	}
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go", api.Pods{Name: "example-go-web-1", Type: "web"})
+	srv.HandleExec(func(conn *websocket.Conn, appID, pod string, command api.Command) {
+		websocket.Message.Send(conn, appID+" "+pod+" "+command.Command[0])
+	})
+
+	conn, err := ps.Exec(srv.Client(), "example-go", "example-go-web-1", api.Command{Command: []string{"ls"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var msg string
+	if err := websocket.Message.Receive(conn, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "example-go example-go-web-1 ls"; msg != expected {
+		t.Errorf("Expected %v, Got %v", expected, msg)
+	}
+
+	if _, err := ps.Exec(srv.Client(), "example-go", "missing", api.Command{}); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
+
+func TestNetworking(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddApp(api.App{ID: "other"})
+	client := srv.Client()
+
+	if _, err := domains.New(client, "example-go", "example.com", "web"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := domains.New(client, "other", "example.com", "web"); !errors.Is(err, drycc.ErrDuplicateDomain) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrDuplicateDomain, err)
+	}
+	if _, err := domains.New(client, "other", "-invalid", "web"); !errors.Is(err, drycc.ErrInvalidDomain) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrInvalidDomain, err)
+	}
+
+	if _, err := certs.New(client, "example-go", "cert", "key", "example"); err != nil {
+		t.Fatal(err)
+	}
+	if err := certs.Attach(client, "example-go", "example", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := certs.Get(client, "example-go", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual([]string{"example.com"}, cert.Domains) {
+		t.Errorf("Expected %v, Got %v", []string{"example.com"}, cert.Domains)
+	}
+	if _, err := certs.New(client, "example-go", "", "key", "empty"); !errors.Is(err, drycc.ErrInvalidCertificate) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrInvalidCertificate, err)
+	}
+
+	if err := gateways.New(client, "example-go", "gateway", 80, "HTTP"); err != nil {
+		t.Fatal(err)
+	}
+	if err := routes.New(client, "example-go", "route", "HTTPRoute",
+		api.BackendRefRequest{Kind: "Service", Name: "example-go-web", Port: 80, Weight: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := routes.AttachGateway(client, "example-go", "route", 80, "gateway"); err != nil {
+		t.Fatal(err)
+	}
+	rules := `[{"backendRefs":[{"kind":"Service","name":"example-go-web","port":80,"weight":50}]}]`
+	if err := routes.SetRule(client, "example-go", "route", rules); err != nil {
+		t.Fatal(err)
+	}
+	route := srv.Routes("example-go")[0]
+	if len(route.ParentRefs) != 1 || route.ParentRefs[0].Name != "gateway" {
+		t.Errorf("Expected the route to be attached to gateway, Got %v", route.ParentRefs)
+	}
+	backend := route.Rules[0]["backendRefs"].([]any)[0].(map[string]any)
+	if backend["weight"] != float64(50) {
+		t.Errorf("Expected %v, Got %v", 50, backend["weight"])
+	}
+
+	if err := services.New(client, "example-go", "web", 80, "TCP", 8000); err != nil {
+		t.Fatal(err)
+	}
+	svcs, err := services.List(client, "example-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svcs) != 1 || svcs[0].Ports[0].TargetPort != 8000 {
+		t.Errorf("Expected a web service, Got %v", svcs)
+	}
+	if err := services.Delete(client, "example-go", "web", "TCP", 80); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVolumesAndResources(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddResourceService(api.ResourceService{ID: "1", Name: "redis"}, api.ResourcePlan{ID: "2", Name: "standard-128"})
+	client := srv.Client()
+
+	if _, err := volumes.Create(client, "example-go", api.Volume{Name: "data", Size: "1G", Type: "csi"}); err != nil {
+		t.Fatal(err)
+	}
+	volume, err := volumes.Mount(client, "example-go", "data", api.Volume{Path: map[string]any{"web": "/data"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if volume.Path["web"] != "/data" {
+		t.Errorf("Expected %v, Got %v", "/data", volume.Path["web"])
+	}
+	if _, err := volumes.Mount(client, "example-go", "data", api.Volume{Path: map[string]any{"worker": nil}}); !errors.As(err, &drycc.ErrUnprocessable{}) {
+		t.Errorf("Expected an unprocessable error, Got %v", err)
+	}
+	ctx, filer, err := volumes.Serve(t.Context(), client, "example-go", "data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filer["username"] != "data" || ctx.Err() != nil {
+		t.Errorf("Expected a bound filer, Got %v", filer)
+	}
+
+	plans, _, err := resources.Plans(client, "redis", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != 1 || plans[0].Name != "standard-128" {
+		t.Errorf("Expected the standard-128 plan, Got %v", plans)
+	}
+	if _, err := resources.Create(client, "example-go", api.Resource{Name: "cache", Plan: "redis:standard-128"}); err != nil {
+		t.Fatal(err)
+	}
+	resource, err := resources.Binding(client, "example-go", "cache", api.ResourceBinding{BindAction: "bind"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.Binding != "Ready" {
+		t.Errorf("Expected %v, Got %v", "Ready", resource.Binding)
+	}
+}
+
+func TestUsers(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddToken(api.Token{UUID: "1", Owner: "test", Alias: "ci"})
+	srv.AddFingerprint("ab:cd", "test")
+	client := srv.Client()
+
+	if _, err := workspaces.Create(client, "team", "team@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := workspaces.Create(client, "other", "invalid"); !errors.Is(err, drycc.ErrInvalidEmail) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrInvalidEmail, err)
+	}
+	member, err := members.Update(client, "team", "test", "viewer", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.Role != "viewer" {
+		t.Errorf("Expected %v, Got %v", "viewer", member.Role)
+	}
+
+	if _, err := keys.New(client, "laptop", "ssh-ed25519 AAAA"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.New(client, "desktop", "ssh-ed25519 AAAA"); !errors.Is(err, drycc.ErrDuplicateKey) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrDuplicateKey, err)
+	}
+	if err := keys.Delete(client, "laptop"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tokens.Delete(client, "1"); err != nil {
+		t.Fatal(err)
+	}
+	if list, _, _ := tokens.List(client, 100); len(list) != 0 {
+		t.Errorf("Expected no tokens, Got %v", list)
+	}
+
+	user, err := hooks.UserFromKey(client, "ab:cd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "test" || !reflect.DeepEqual([]string{"example-go"}, user.Apps) {
+		t.Errorf("Expected test with example-go, Got %v", user)
+	}
+	version, err := hooks.CreateBuild(client, "test", "example-go", "example", "container", "abc", nil, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 {
+		t.Errorf("Expected %v, Got %v", 2, version)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	t.Parallel()
+
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	client := srv.Client()
+
+	srv.Fail(Failure{Method: "GET", Path: "/v2/apps/example-go/", StatusCode: http.StatusServiceUnavailable, Times: 2})
+	if _, err := apps.Get(client, "example-go"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	client.Retry = &drycc.RetryPolicy{MaxAttempts: 2, RetryStatusCodes: []int{http.StatusServiceUnavailable}}
+	if _, err := apps.Get(client, "example-go"); err != nil {
+		t.Errorf("Expected the retry to succeed, Got %v", err)
+	}
+
+	srv.Fail(Failure{Path: "/v2/apps/*", StatusCode: http.StatusBadRequest, Body: FieldError("id", "This field may not be blank.")})
+	_, err := apps.New(client, "", "test")
+	var apiErr *drycc.APIError
+	if !errors.Is(err, drycc.ErrMissingID) || !errors.As(err, &apiErr) {
+		t.Fatalf("Expected %v, Got %v", drycc.ErrMissingID, err)
+	}
+	if expected := "/v2/apps/"; apiErr.StatusCode != http.StatusBadRequest || !reflect.DeepEqual([]string{"This field may not be blank."}, apiErr.FieldErrors("id")) {
+		t.Errorf("Expected a 400 on %s, Got %v", expected, apiErr)
+	}
+	srv.ClearFailures()
+
+	srv.Token = "secret"
+	if _, err := apps.Get(client, "example-go"); !errors.Is(err, drycc.ErrUnauthorized) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrUnauthorized, err)
+	}
+	if err := srv.Client().CheckConnection(); err != nil {
+		t.Error(err)
+	}
+
+	requests := srv.Requests()
+	if last := requests[len(requests)-1]; last.Method != "GET" || last.Path != "/v2/" {
+		t.Errorf("Expected the last request to be GET /v2/, Got %v", last)
+	}
+}
@@ -0,0 +1,107 @@
+package drycctest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/net/websocket"
+)
+
+// The channels of the exec websocket, see ps.StdinChannel.
+const (
+	stdinChannel byte = iota
+	stdoutChannel
+	stderrChannel
+	errorChannel
+	resizeChannel
+
+	closeChannel byte = 255
+)
+
+// ExecStream speaks the channel framing of the exec websocket, for ExecHandler
+// implementations.
+type ExecStream struct {
+	Conn *websocket.Conn
+}
+
+// Stdout writes to the standard output of the command.
+func (e ExecStream) Stdout(data []byte) error {
+	return e.send(stdoutChannel, data)
+}
+
+// Stderr writes to the standard error of the command.
+func (e ExecStream) Stderr(data []byte) error {
+	return e.send(stderrChannel, data)
+}
+
+// Exit sends the exit status of the command. The handler should return afterwards.
+func (e ExecStream) Exit(code int) error {
+	status := map[string]any{"status": "Success", "metadata": map[string]any{}}
+	if code != 0 {
+		status = map[string]any{
+			"status":  "Failure",
+			"reason":  "NonZeroExitCode",
+			"message": fmt.Sprintf("command terminated with non-zero exit code: exit status %d", code),
+			"details": map[string]any{"causes": []map[string]string{
+				{"reason": "ExitCode", "message": strconv.Itoa(code)},
+			}},
+		}
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return e.send(errorChannel, data)
+}
+
+// Fail reports that the command couldn't be run, such as when its executable doesn't
+// exist. The handler should return afterwards.
+func (e ExecStream) Fail(message string) error {
+	data, err := json.Marshal(map[string]any{"status": "Failure", "reason": "InternalError", "message": message})
+	if err != nil {
+		return err
+	}
+	return e.send(errorChannel, data)
+}
+
+// Stdin returns a reader of the standard input of the command, which must be read for
+// the messages of the client to be received. Resize messages are passed to resize, if not
+// nil. The reader returns io.EOF once the client closes the standard input or the
+// connection.
+func (e ExecStream) Stdin(resize func(width, height uint16)) io.Reader {
+	r, w := io.Pipe()
+	go func() {
+		for {
+			var msg []byte
+			if err := websocket.Message.Receive(e.Conn, &msg); err != nil {
+				w.Close()
+				return
+			}
+			if len(msg) == 0 {
+				continue
+			}
+			switch msg[0] {
+			case stdinChannel:
+				if _, err := w.Write(msg[1:]); err != nil {
+					return
+				}
+			case closeChannel:
+				if len(msg) > 1 && msg[1] == stdinChannel {
+					w.Close()
+				}
+			case resizeChannel:
+				var size struct{ Width, Height uint16 }
+				if json.Unmarshal(msg[1:], &size) == nil && resize != nil {
+					resize(size.Width, size.Height)
+				}
+			}
+		}
+	}()
+	return r
+}
+
+func (e ExecStream) send(channel byte, data []byte) error {
+	return websocket.Message.Send(e.Conn, append([]byte{channel}, data...))
+}
@@ -0,0 +1,514 @@
+package drycctest
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	dtime "github.com/drycc/controller-sdk-go/pkg/time"
+)
+
+var validDomain = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// AddDomain adds a domain to an application.
+func (s *Server) AddDomain(appID string, d api.Domain) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	d.App = appID
+	a.domains.put(d.Domain, d)
+}
+
+// AddCert adds a certificate to an application.
+func (s *Server) AddCert(appID string, c api.Cert) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	c.App = appID
+	fillCertTimes(&c)
+	a.certs.put(c.Name, c)
+}
+
+// fillCertTimes sets the unset validity times of a certificate to the zero time,
+// which they can't be marshaled without.
+func fillCertTimes(c *api.Cert) {
+	zero := time.Time{}
+	if c.Starts.Time == nil {
+		c.Starts = dtime.Time{Time: &zero}
+	}
+	if c.Expires.Time == nil {
+		c.Expires = dtime.Time{Time: &zero}
+	}
+}
+
+// AddRoute adds a route to an application.
+func (s *Server) AddRoute(appID string, r api.Route) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	r.App = appID
+	a.routes.put(r.Name, r)
+}
+
+// Routes returns the routes of an application.
+func (s *Server) Routes(appID string) api.Routes {
+	defer s.lock()()
+	return s.mustApp(appID).routes.list()
+}
+
+// AddGateway adds a gateway to an application.
+func (s *Server) AddGateway(appID string, g api.Gateway) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	g.App = appID
+	a.gateways.put(g.Name, g)
+}
+
+// AddService adds a service to an application.
+func (s *Server) AddService(appID string, svc api.Service) {
+	defer s.lock()()
+	s.mustApp(appID).services.put(svc.Ptype, svc)
+}
+
+func (s *Server) listDomains(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.domains.list())
+	}
+}
+
+func (s *Server) createDomain(w http.ResponseWriter, r *http.Request) {
+	var req api.DomainCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validDomain.MatchString(req.Domain) {
+		writeError(w, http.StatusBadRequest, FieldError("domain", "Hostname does not look valid."))
+		return
+	}
+	for _, other := range s.apps.list() {
+		if _, ok := other.domains.get(req.Domain); ok {
+			writeError(w, http.StatusBadRequest, FieldError("domain", "Domain is already in use by another application"))
+			return
+		}
+	}
+	now := s.now()
+	d := api.Domain{App: a.ID, Domain: req.Domain, Ptype: req.Ptype, Created: now, Updated: now}
+	a.domains.put(d.Domain, d)
+	writeJSON(w, http.StatusCreated, d)
+}
+
+func (s *Server) deleteDomain(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !a.domains.delete(r.PathValue("domain")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listCerts(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.certs.list())
+	}
+}
+
+func (s *Server) createCert(w http.ResponseWriter, r *http.Request) {
+	var req api.CertCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if req.Certificate == "" {
+		writeError(w, http.StatusBadRequest, FieldError("certificate", "This field may not be blank."))
+		return
+	}
+	if _, ok := a.certs.get(req.Name); ok {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Certificate with this name already exists."))
+		return
+	}
+	now := s.now()
+	cert := api.Cert{App: a.ID, Name: req.Name, Created: now, Updated: now, ID: s.nextSerial()}
+	// Certificates that can be parsed are described like the controller does,
+	// others are stored as is.
+	if block, _ := pem.Decode([]byte(req.Certificate)); block != nil {
+		if x, err := x509.ParseCertificate(block.Bytes); err == nil {
+			starts, expires := x.NotBefore.UTC(), x.NotAfter.UTC()
+			cert.CommonName = x.Subject.CommonName
+			cert.Issuer = x.Issuer.String()
+			cert.Subject = x.Subject.String()
+			cert.SubjectAltName = x.DNSNames
+			cert.Starts = dtime.Time{Time: &starts}
+			cert.Expires = dtime.Time{Time: &expires}
+		}
+	}
+	fillCertTimes(&cert)
+	a.certs.put(cert.Name, cert)
+	writeJSON(w, http.StatusCreated, &cert)
+}
+
+func (s *Server) getCert(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	cert, ok := a.certs.get(r.PathValue("name"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, &cert)
+}
+
+func (s *Server) deleteCert(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !a.certs.delete(r.PathValue("name")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) attachCert(w http.ResponseWriter, r *http.Request) {
+	var req api.CertAttachRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	cert, ok := a.certs.get(r.PathValue("name"))
+	_, domainExists := a.domains.get(req.Domain)
+	if !ok || !domainExists {
+		notFound(w)
+		return
+	}
+	if !slices.Contains(cert.Domains, req.Domain) {
+		cert.Domains = append(cert.Domains, req.Domain)
+	}
+	cert.Updated = s.now()
+	a.certs.put(cert.Name, cert)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) detachCert(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	cert, ok := a.certs.get(r.PathValue("name"))
+	if !ok || !slices.Contains(cert.Domains, r.PathValue("domain")) {
+		notFound(w)
+		return
+	}
+	cert.Domains = slices.DeleteFunc(cert.Domains, func(d string) bool { return d == r.PathValue("domain") })
+	cert.Updated = s.now()
+	a.certs.put(cert.Name, cert)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listRoutes(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.routes.list())
+	}
+}
+
+func (s *Server) createRoute(w http.ResponseWriter, r *http.Request) {
+	var req api.RouteCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if _, ok := a.routes.get(req.Name); ok {
+		writeError(w, http.StatusConflict, DetailError(fmt.Sprintf("Route %s already exists", req.Name)))
+		return
+	}
+	var rules []api.RouteRule
+	if err := remarshal(req.Rules, &rules); err != nil {
+		writeError(w, http.StatusBadRequest, DetailError(err.Error()))
+		return
+	}
+	now := s.now()
+	a.routes.put(req.Name, api.Route{
+		App: a.ID, Name: req.Name, Kind: req.Kind, Rules: rules,
+		Created: now, Updated: now, UUID: newUUID(),
+	})
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) attachRoute(w http.ResponseWriter, r *http.Request) {
+	var req api.RouteAttachRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	route, ok := a.routes.get(r.PathValue("name"))
+	_, gatewayExists := a.gateways.get(req.Gateway)
+	if !ok || !gatewayExists {
+		notFound(w)
+		return
+	}
+	ref := api.ParentRef{Name: req.Gateway, Port: req.Port}
+	if !slices.Contains(route.ParentRefs, ref) {
+		route.ParentRefs = append(route.ParentRefs, ref)
+	}
+	route.Updated = s.now()
+	a.routes.put(route.Name, route)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) detachRoute(w http.ResponseWriter, r *http.Request) {
+	var req api.RouteDetachRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	route, ok := a.routes.get(r.PathValue("name"))
+	ref := api.ParentRef{Name: req.Gateway, Port: req.Port}
+	if !ok || !slices.Contains(route.ParentRefs, ref) {
+		notFound(w)
+		return
+	}
+	route.ParentRefs = slices.DeleteFunc(route.ParentRefs, func(p api.ParentRef) bool { return p == ref })
+	route.Updated = s.now()
+	a.routes.put(route.Name, route)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) getRouteRules(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	route, ok := a.routes.get(r.PathValue("name"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	rules := route.Rules
+	if rules == nil {
+		rules = []api.RouteRule{}
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+func (s *Server) setRouteRules(w http.ResponseWriter, r *http.Request) {
+	// The rules are sent as a JSON document encoded in a JSON string.
+	var doc string
+	if !readJSON(w, r, &doc) {
+		return
+	}
+	var rules []api.RouteRule
+	if err := json.Unmarshal([]byte(doc), &rules); err != nil {
+		writeError(w, http.StatusBadRequest, DetailError(fmt.Sprintf("rules is not valid: %s", err)))
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	route, ok := a.routes.get(r.PathValue("name"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	route.Rules = rules
+	route.Updated = s.now()
+	a.routes.put(route.Name, route)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteRoute(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !a.routes.delete(r.PathValue("name")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listGateways(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.gateways.list())
+	}
+}
+
+func listenerName(port int, protocol string) string {
+	return fmt.Sprintf("%s-%d", strings.ToLower(protocol), port)
+}
+
+func (s *Server) createGateway(w http.ResponseWriter, r *http.Request) {
+	var req api.GatewayCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	now := s.now()
+	g, ok := a.gateways.get(req.Name)
+	if !ok {
+		g = api.Gateway{App: a.ID, Name: req.Name, Created: now, UUID: newUUID()}
+	}
+	name := listenerName(req.Port, req.Protocol)
+	if slices.ContainsFunc(g.Listeners, func(l api.Listener) bool { return l.Name == name }) {
+		writeError(w, http.StatusConflict, DetailError(fmt.Sprintf("Listener %s already exists", name)))
+		return
+	}
+	g.Listeners = append(g.Listeners, api.Listener{Name: name, Port: req.Port, Protocol: req.Protocol})
+	g.Updated = now
+	a.gateways.put(g.Name, g)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteGateway(w http.ResponseWriter, r *http.Request) {
+	var req api.GatewayRemoveRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	g, ok := a.gateways.get(req.Name)
+	name := listenerName(req.Port, req.Protocol)
+	if !ok || !slices.ContainsFunc(g.Listeners, func(l api.Listener) bool { return l.Name == name }) {
+		notFound(w)
+		return
+	}
+	g.Listeners = slices.DeleteFunc(g.Listeners, func(l api.Listener) bool { return l.Name == name })
+	if len(g.Listeners) == 0 {
+		a.gateways.delete(g.Name)
+	} else {
+		g.Updated = s.now()
+		a.gateways.put(g.Name, g)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listServices(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		writeJSON(w, http.StatusOK, map[string]api.Services{"services": a.services.list()})
+	}
+}
+
+func (s *Server) createService(w http.ResponseWriter, r *http.Request) {
+	var req api.ServiceCreateUpdateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Ptype == "" {
+		writeError(w, http.StatusBadRequest, FieldError("ptype", "This field may not be blank."))
+		return
+	}
+	svc, ok := a.services.get(req.Ptype)
+	if !ok {
+		name := fmt.Sprintf("%s-%s", a.ID, req.Ptype)
+		svc = api.Service{Name: name, Domain: fmt.Sprintf("%s.%s.svc.cluster.local", name, a.ID), Ptype: req.Ptype}
+	}
+	port := api.Port{
+		Name:       fmt.Sprintf("%s-%s-%d", a.ID, strings.ToLower(req.Protocol), req.Port),
+		Port:       req.Port,
+		Protocol:   req.Protocol,
+		TargetPort: req.TargetPort,
+	}
+	if i := slices.IndexFunc(svc.Ports, func(p api.Port) bool { return p.Port == port.Port && p.Protocol == port.Protocol }); i >= 0 {
+		svc.Ports[i] = port
+	} else {
+		svc.Ports = append(svc.Ports, port)
+	}
+	a.services.put(svc.Ptype, svc)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteService(w http.ResponseWriter, r *http.Request) {
+	var req api.ServiceDeleteRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	svc, ok := a.services.get(req.Ptype)
+	match := func(p api.Port) bool { return p.Port == req.Port && p.Protocol == req.Protocol }
+	if !ok || !slices.ContainsFunc(svc.Ports, match) {
+		notFound(w)
+		return
+	}
+	svc.Ports = slices.DeleteFunc(svc.Ports, match)
+	if len(svc.Ports) == 0 {
+		a.services.delete(svc.Ptype)
+	} else {
+		a.services.put(svc.Ptype, svc)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
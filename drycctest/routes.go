@@ -0,0 +1,227 @@
+package drycctest
+
+import (
+	"net/http"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/websocket"
+)
+
+// ExecHandler serves a command executed in a pod with ps.Exec. The connection is closed
+// when the handler returns.
+type ExecHandler func(conn *websocket.Conn, appID, pod string, command api.Command)
+
+// LogsHandler serves the logs of a pod requested with ps.Logs. The connection is closed
+// when the handler returns.
+type LogsHandler func(conn *websocket.Conn, appID, pod string, request api.PodLogsRequest)
+
+// AppLogsHandler serves the logs of an application requested with apps.Logs. The
+// connection is closed when the handler returns.
+type AppLogsHandler func(conn *websocket.Conn, appID string, request api.AppLogsRequest)
+
+// HandleExec sets the handler of the commands executed in pods. By default, the
+// connection is closed once the command is received.
+func (s *Server) HandleExec(h ExecHandler) {
+	defer s.lock()()
+	s.execHandler = h
+}
+
+// HandleLogs sets the handler of the pod logs requests. By default, the connection is
+// closed once the request is received.
+func (s *Server) HandleLogs(h LogsHandler) {
+	defer s.lock()()
+	s.logsHandler = h
+}
+
+// HandleAppLogs sets the handler of the application logs requests. By default, the
+// connection is closed once the request is received.
+func (s *Server) HandleAppLogs(h AppLogsHandler) {
+	defer s.lock()()
+	s.appLogsHandler = h
+}
+
+func (s *Server) routes() {
+	handle := s.mux.HandleFunc
+
+	handle("GET /v2/{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusUnauthorized, DetailError("Authentication credentials were not provided."))
+	})
+	handle("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handle("GET /v2/apps/{$}", s.listApps)
+	handle("POST /v2/apps/{$}", s.createApp)
+	handle("GET /v2/apps/{id}/{$}", s.getApp)
+	handle("PATCH /v2/apps/{id}/{$}", s.updateApp)
+	handle("DELETE /v2/apps/{id}/{$}", s.deleteApp)
+	handle("POST /v2/apps/{id}/run", s.runApp)
+	handle("GET /v2/apps/{id}/logs/{$}", s.appLogs)
+
+	handle("GET /v2/apps/{id}/build/{$}", s.getBuild)
+	handle("POST /v2/apps/{id}/build/{$}", s.createBuildHandler)
+	handle("GET /v2/apps/{id}/config/{$}", s.getConfig)
+	handle("POST /v2/apps/{id}/config/{$}", s.setConfig)
+	handle("DELETE /v2/apps/{id}/config/{$}", s.detachConfig)
+
+	handle("GET /v2/apps/{id}/releases/{$}", s.listReleases)
+	handle("GET /v2/apps/{id}/releases/{version}/{$}", s.getRelease)
+	handle("POST /v2/apps/{id}/releases/deploy/{$}", s.deployRelease)
+	handle("POST /v2/apps/{id}/releases/rollback/{$}", s.rollbackRelease)
+
+	handle("GET /v2/apps/{id}/ptypes/{$}", s.listPtypes)
+	handle("GET /v2/apps/{id}/ptypes/{name}/describe/{$}", s.describePtype)
+	handle("POST /v2/apps/{id}/ptypes/scale/{$}", s.scalePtypes)
+	handle("POST /v2/apps/{id}/ptypes/restart/{$}", s.restartPtypes)
+	handle("POST /v2/apps/{id}/ptypes/clean/{$}", s.cleanPtypes)
+
+	handle("GET /v2/apps/{id}/pods/{$}", s.listPods)
+	handle("DELETE /v2/apps/{id}/pods/{$}", s.deletePods)
+	handle("GET /v2/apps/{id}/pods/{pod}/describe/{$}", s.describePod)
+	handle("GET /v2/apps/{id}/pods/{pod}/exec/{$}", s.execPod)
+	handle("GET /v2/apps/{id}/pods/{pod}/logs/{$}", s.podLogs)
+	handle("GET /v2/apps/{id}/events/{$}", s.listEvents)
+
+	handle("GET /v2/apps/{id}/domains/{$}", s.listDomains)
+	handle("POST /v2/apps/{id}/domains/{$}", s.createDomain)
+	handle("DELETE /v2/apps/{id}/domains/{domain}", s.deleteDomain)
+
+	handle("GET /v2/apps/{id}/certs/{$}", s.listCerts)
+	handle("POST /v2/apps/{id}/certs/{$}", s.createCert)
+	handle("GET /v2/apps/{id}/certs/{name}", s.getCert)
+	handle("DELETE /v2/apps/{id}/certs/{name}", s.deleteCert)
+	handle("POST /v2/apps/{id}/certs/{name}/domain/{$}", s.attachCert)
+	handle("DELETE /v2/apps/{id}/certs/{name}/domain/{domain}", s.detachCert)
+
+	handle("GET /v2/apps/{id}/routes/{$}", s.listRoutes)
+	handle("POST /v2/apps/{id}/routes/{$}", s.createRoute)
+	handle("DELETE /v2/apps/{id}/routes/{name}/{$}", s.deleteRoute)
+	handle("PATCH /v2/apps/{id}/routes/{name}/attach/{$}", s.attachRoute)
+	handle("PATCH /v2/apps/{id}/routes/{name}/detach/{$}", s.detachRoute)
+	handle("GET /v2/apps/{id}/routes/{name}/rules/{$}", s.getRouteRules)
+	handle("PUT /v2/apps/{id}/routes/{name}/rules/{$}", s.setRouteRules)
+
+	handle("GET /v2/apps/{id}/gateways/{$}", s.listGateways)
+	handle("POST /v2/apps/{id}/gateways/{$}", s.createGateway)
+	handle("DELETE /v2/apps/{id}/gateways/{$}", s.deleteGateway)
+
+	handle("GET /v2/apps/{id}/services/{$}", s.listServices)
+	handle("POST /v2/apps/{id}/services/{$}", s.createService)
+	handle("DELETE /v2/apps/{id}/services/{$}", s.deleteService)
+
+	handle("GET /v2/apps/{id}/volumes/{$}", s.listVolumes)
+	handle("POST /v2/apps/{id}/volumes/{$}", s.createVolume)
+	handle("GET /v2/apps/{id}/volumes/{name}/{$}", s.getVolume)
+	handle("PATCH /v2/apps/{id}/volumes/{name}/{$}", s.expandVolume)
+	handle("DELETE /v2/apps/{id}/volumes/{name}/{$}", s.deleteVolume)
+	handle("PATCH /v2/apps/{id}/volumes/{name}/path/{$}", s.mountVolume)
+	handle("POST /v2/apps/{id}/volumes/{name}/filer/_/bind", s.bindFiler)
+	handle("POST /v2/apps/{id}/volumes/{name}/filer/_/unbind", s.unbindFiler)
+	handle("GET /v2/apps/{id}/volumes/{name}/filer/_/ping", s.pingFiler)
+	handle("/v2/apps/{id}/volumes/{name}/filer/webdav/", s.serveFiler)
+
+	handle("GET /v2/resources/services/{$}", s.listResourceServices)
+	handle("GET /v2/resources/services/{service}/plans/{$}", s.listResourcePlans)
+	handle("GET /v2/apps/{id}/resources/{$}", s.listResources)
+	handle("POST /v2/apps/{id}/resources/{$}", s.createResource)
+	handle("GET /v2/apps/{id}/resources/{name}/{$}", s.getResource)
+	handle("PUT /v2/apps/{id}/resources/{name}/{$}", s.updateResource)
+	handle("DELETE /v2/apps/{id}/resources/{name}/{$}", s.deleteResource)
+	handle("PATCH /v2/apps/{id}/resources/{name}/binding/{$}", s.bindResource)
+
+	handle("GET /v2/workspaces", s.listWorkspaces)
+	handle("POST /v2/workspaces", s.createWorkspace)
+	handle("GET /v2/workspaces/{workspace}", s.getWorkspace)
+	handle("PATCH /v2/workspaces/{workspace}", s.updateWorkspace)
+	handle("DELETE /v2/workspaces/{workspace}", s.deleteWorkspace)
+	handle("GET /v2/workspaces/{workspace}/members", s.listMembers)
+	handle("GET /v2/workspaces/{workspace}/members/{user}", s.getMember)
+	handle("PATCH /v2/workspaces/{workspace}/members/{user}", s.updateMember)
+	handle("DELETE /v2/workspaces/{workspace}/members/{user}", s.deleteMember)
+	handle("GET /v2/workspaces/{workspace}/invitations", s.listInvitations)
+	handle("POST /v2/workspaces/{workspace}/invitations", s.createInvitation)
+	handle("GET /v2/workspaces/{workspace}/invitations/{token}", s.getInvitation)
+	handle("DELETE /v2/workspaces/{workspace}/invitations/{token}", s.deleteInvitation)
+
+	handle("GET /v2/tokens/{$}", s.listTokens)
+	handle("DELETE /v2/tokens/{token}/{$}", s.deleteToken)
+	handle("GET /v2/keys/{$}", s.listKeys)
+	handle("POST /v2/keys/{$}", s.createKey)
+	handle("DELETE /v2/keys/{key}", s.deleteKey)
+
+	handle("GET /v2/hooks/key/{fingerprint}", s.hookUserFromKey)
+	handle("POST /v2/hooks/config/{$}", s.hookConfig)
+	handle("POST /v2/hooks/build/{$}", s.hookBuild)
+}
+
+// podFor checks that the pod named in the request path exists, or writes a 404 response.
+func (s *Server) podFor(w http.ResponseWriter, r *http.Request) bool {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return false
+	}
+	if _, ok := a.pods.get(r.PathValue("pod")); !ok {
+		notFound(w)
+		return false
+	}
+	return true
+}
+
+func (s *Server) execPod(w http.ResponseWriter, r *http.Request) {
+	if !s.podFor(w, r) {
+		return
+	}
+	s.mu.Lock()
+	handler := s.execHandler
+	s.mu.Unlock()
+
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		var command api.Command
+		if err := websocket.JSON.Receive(conn, &command); err != nil {
+			return
+		}
+		if handler != nil {
+			handler(conn, r.PathValue("id"), r.PathValue("pod"), command)
+		}
+	}}.ServeHTTP(w, r)
+}
+
+func (s *Server) podLogs(w http.ResponseWriter, r *http.Request) {
+	if !s.podFor(w, r) {
+		return
+	}
+	s.mu.Lock()
+	handler := s.logsHandler
+	s.mu.Unlock()
+
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		var request api.PodLogsRequest
+		if err := websocket.JSON.Receive(conn, &request); err != nil {
+			return
+		}
+		if handler != nil {
+			handler(conn, r.PathValue("id"), r.PathValue("pod"), request)
+		}
+	}}.ServeHTTP(w, r)
+}
+
+func (s *Server) appLogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, ok := s.appFor(w, r)
+	handler := s.appLogsHandler
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	websocket.Server{Handler: func(conn *websocket.Conn) {
+		var request api.AppLogsRequest
+		if err := websocket.JSON.Receive(conn, &request); err != nil {
+			return
+		}
+		if handler != nil {
+			handler(conn, r.PathValue("id"), request)
+		}
+	}}.ServeHTTP(w, r)
+}
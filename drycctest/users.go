@@ -0,0 +1,376 @@
+package drycctest
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// workspace holds the state of a workspace.
+type workspace struct {
+	api.Workspace
+	members     collection[api.WorkspaceMember]
+	invitations collection[api.WorkspaceInvitation]
+}
+
+// AddWorkspace adds a workspace.
+func (s *Server) AddWorkspace(ws api.Workspace) {
+	defer s.lock()()
+	s.workspaces.put(ws.Name, &workspace{Workspace: ws})
+}
+
+// AddMember adds a member to a workspace.
+func (s *Server) AddMember(name string, m api.WorkspaceMember) {
+	defer s.lock()()
+	ws := s.mustWorkspace(name)
+	m.Workspace = name
+	ws.members.put(m.User, m)
+}
+
+// AddInvitation adds an invitation to a workspace. Invitations are identified by their token.
+func (s *Server) AddInvitation(name string, inv api.WorkspaceInvitation) {
+	defer s.lock()()
+	ws := s.mustWorkspace(name)
+	inv.Workspace = name
+	ws.invitations.put(inv.Token, inv)
+}
+
+// AddToken adds a token of the user. Tokens are identified by their UUID.
+func (s *Server) AddToken(t api.Token) {
+	defer s.lock()()
+	s.tokens.put(t.UUID, t)
+}
+
+// AddKey adds an SSH key of the user.
+func (s *Server) AddKey(k api.Key) {
+	defer s.lock()()
+	s.keys.put(k.ID, k)
+}
+
+// AddFingerprint associates the fingerprint of an SSH key with a user, for the hooks
+// looking up users by key. The user has access to every application.
+func (s *Server) AddFingerprint(fingerprint, username string) {
+	defer s.lock()()
+	s.fingerprints[fingerprint] = username
+}
+
+func (s *Server) mustWorkspace(name string) *workspace {
+	ws, ok := s.workspaces.get(name)
+	if !ok {
+		panic(fmt.Sprintf("drycctest: workspace %s does not exist", name))
+	}
+	return ws
+}
+
+// workspaceFor returns the workspace named in the request path, or writes a 404 response.
+func (s *Server) workspaceFor(w http.ResponseWriter, r *http.Request) (*workspace, bool) {
+	ws, ok := s.workspaces.get(r.PathValue("workspace"))
+	if !ok {
+		notFound(w)
+	}
+	return ws, ok
+}
+
+func (s *Server) listWorkspaces(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	workspaces := make(api.Workspaces, 0, s.workspaces.len())
+	for _, ws := range s.workspaces.list() {
+		workspaces = append(workspaces, ws.Workspace)
+	}
+	s.writePage(w, r, workspaces)
+}
+
+func (s *Server) createWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req api.WorkspaceCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		writeError(w, http.StatusBadRequest, FieldError("email", "Enter a valid email address."))
+		return
+	}
+	if _, ok := s.workspaces.get(req.Name); ok {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Workspace with this name already exists."))
+		return
+	}
+	now := s.now()
+	ws := &workspace{Workspace: api.Workspace{
+		ID: s.nextSerial(), Name: req.Name, Email: req.Email, Created: now, Updated: now,
+	}}
+	ws.members.put(s.Username, api.WorkspaceMember{
+		ID: s.nextSerial(), User: s.Username, Email: req.Email, Role: "admin", Alerts: true,
+		Workspace: req.Name, Created: now, Updated: now,
+	})
+	s.workspaces.put(req.Name, ws)
+	writeJSON(w, http.StatusCreated, ws.Workspace)
+}
+
+func (s *Server) getWorkspace(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if ws, ok := s.workspaceFor(w, r); ok {
+		writeJSON(w, http.StatusOK, ws.Workspace)
+	}
+}
+
+func (s *Server) updateWorkspace(w http.ResponseWriter, r *http.Request) {
+	var req api.WorkspaceUpdateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Email != "" {
+		if _, err := mail.ParseAddress(req.Email); err != nil {
+			writeError(w, http.StatusBadRequest, FieldError("email", "Enter a valid email address."))
+			return
+		}
+		ws.Email = req.Email
+	}
+	ws.Updated = s.now()
+	writeJSON(w, http.StatusOK, ws.Workspace)
+}
+
+func (s *Server) deleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if !s.workspaces.delete(r.PathValue("workspace")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if ws, ok := s.workspaceFor(w, r); ok {
+		s.writePage(w, r, ws.members.list())
+	}
+}
+
+func (s *Server) getMember(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	m, ok := ws.members.get(r.PathValue("user"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) updateMember(w http.ResponseWriter, r *http.Request) {
+	var req api.WorkspaceMemberUpdateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	m, ok := ws.members.get(r.PathValue("user"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	switch req.Role {
+	case "":
+	case "admin", "member", "viewer":
+		m.Role = req.Role
+	default:
+		writeError(w, http.StatusBadRequest, FieldError("role", fmt.Sprintf("\"%s\" is not a valid choice.", req.Role)))
+		return
+	}
+	if req.Alerts != nil {
+		m.Alerts = *req.Alerts
+	}
+	m.Updated = s.now()
+	ws.members.put(m.User, m)
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) deleteMember(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	if !ws.members.delete(r.PathValue("user")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listInvitations(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if ws, ok := s.workspaceFor(w, r); ok {
+		s.writePage(w, r, ws.invitations.list())
+	}
+}
+
+func (s *Server) createInvitation(w http.ResponseWriter, r *http.Request) {
+	var req api.WorkspaceInvitationCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	if _, err := mail.ParseAddress(req.Email); err != nil {
+		writeError(w, http.StatusBadRequest, FieldError("email", "Enter a valid email address."))
+		return
+	}
+	inv := api.WorkspaceInvitation{
+		ID: s.nextSerial(), Email: req.Email, Token: newUUID(), Inviter: s.Username,
+		Created: s.now(), Workspace: ws.Name,
+	}
+	ws.invitations.put(inv.Token, inv)
+	writeJSON(w, http.StatusCreated, inv)
+}
+
+func (s *Server) getInvitation(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	inv, ok := ws.invitations.get(r.PathValue("token"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
+
+func (s *Server) deleteInvitation(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	ws, ok := s.workspaceFor(w, r)
+	if !ok {
+		return
+	}
+	if !ws.invitations.delete(r.PathValue("token")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listTokens(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	s.writePage(w, r, s.tokens.list())
+}
+
+func (s *Server) deleteToken(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if !s.tokens.delete(r.PathValue("token")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listKeys(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	s.writePage(w, r, s.keys.list())
+}
+
+func (s *Server) createKey(w http.ResponseWriter, r *http.Request) {
+	var req api.KeyCreateRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	if req.ID == "" {
+		writeError(w, http.StatusBadRequest, FieldError("id", "This field may not be blank."))
+		return
+	}
+	if req.Public == "" {
+		writeError(w, http.StatusBadRequest, FieldError("public", "This field may not be blank."))
+		return
+	}
+	for _, k := range s.keys.list() {
+		if k.Public == req.Public {
+			writeError(w, http.StatusBadRequest, FieldError("key", "Public Key is already in use"))
+			return
+		}
+	}
+	now := s.now()
+	key := api.Key{ID: req.ID, Owner: s.Username, Public: req.Public, Created: now, Updated: now, UUID: newUUID()}
+	s.keys.put(key.ID, key)
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (s *Server) deleteKey(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if !s.keys.delete(r.PathValue("key")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) hookUserFromKey(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	username, ok := s.fingerprints[r.PathValue("fingerprint")]
+	if !ok {
+		notFound(w)
+		return
+	}
+	user := api.UserApps{Username: username, Apps: make([]string, 0, s.apps.len())}
+	for _, a := range s.apps.list() {
+		user.Apps = append(user.Apps, a.ID)
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *Server) hookConfig(w http.ResponseWriter, r *http.Request) {
+	var req api.ConfigHookRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.apps.get(req.App)
+	if !ok {
+		notFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, a.latest().config)
+}
+
+func (s *Server) hookBuild(w http.ResponseWriter, r *http.Request) {
+	var req api.BuildHookRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.apps.get(req.App)
+	if !ok {
+		notFound(w)
+		return
+	}
+	if req.Image == "" {
+		writeError(w, http.StatusBadRequest, FieldError("image", "This field may not be blank."))
+		return
+	}
+	build := api.Build{
+		Image: req.Image, Stack: req.Stack, Sha: req.Sha, Procfile: req.Procfile,
+		Dockerfile: req.Dockerfile, Dryccfile: req.Dryccfile,
+	}
+	rel := s.createBuild(a, build, fmt.Sprintf("%s deployed %s", req.User, req.Sha))
+	writeJSON(w, http.StatusOK, map[string]map[string]int{"release": {"version": rel.Version}})
+}
@@ -0,0 +1,387 @@
+package drycctest
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/webdav"
+)
+
+// resourceService is a service of the resource catalog, with its plans.
+type resourceService struct {
+	api.ResourceService
+	plans collection[api.ResourcePlan]
+}
+
+// AddVolume adds a volume to an application.
+func (s *Server) AddVolume(appID string, v api.Volume) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	v.App = appID
+	a.volumes.put(v.Name, v)
+}
+
+// Volumes returns the volumes of an application.
+func (s *Server) Volumes(appID string) api.Volumes {
+	defer s.lock()()
+	return s.mustApp(appID).volumes.list()
+}
+
+// FilerFS returns the in-memory file system served by the WebDAV filer of a volume, to
+// seed or inspect its files. The volume must exist.
+func (s *Server) FilerFS(appID, volume string) webdav.FileSystem {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	if _, ok := a.volumes.get(volume); !ok {
+		panic(fmt.Sprintf("drycctest: volume %s of app %s does not exist", volume, appID))
+	}
+	return a.filer(volume)
+}
+
+// FilerBound reports whether the filer of a volume is bound.
+func (s *Server) FilerBound(appID, volume string) bool {
+	defer s.lock()()
+	return s.mustApp(appID).bound[volume]
+}
+
+// UnbindFiler unbinds the filer of a volume, such as when the binding expires, so it's
+// pinged with a 404 until bound again.
+func (s *Server) UnbindFiler(appID, volume string) {
+	defer s.lock()()
+	delete(s.mustApp(appID).bound, volume)
+}
+
+// filer returns the file system of a volume, creating it if needed.
+func (a *app) filer(volume string) webdav.FileSystem {
+	fs, ok := a.filers[volume]
+	if !ok {
+		fs = webdav.NewMemFS()
+		a.filers[volume] = fs
+	}
+	return fs
+}
+
+// AddResource adds a resource to an application.
+func (s *Server) AddResource(appID string, res api.Resource) {
+	defer s.lock()()
+	a := s.mustApp(appID)
+	res.App = appID
+	a.resources.put(res.Name, res)
+}
+
+// AddResourceService adds a service to the resource catalog, with its plans.
+func (s *Server) AddResourceService(svc api.ResourceService, plans ...api.ResourcePlan) {
+	defer s.lock()()
+	st := &resourceService{ResourceService: svc}
+	for _, plan := range plans {
+		st.plans.put(plan.Name, plan)
+	}
+	s.services.put(svc.Name, st)
+}
+
+func (s *Server) listVolumes(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.volumes.list())
+	}
+}
+
+func (s *Server) createVolume(w http.ResponseWriter, r *http.Request) {
+	var req api.Volume
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if _, ok := a.volumes.get(req.Name); ok {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Volume with this name already exists."))
+		return
+	}
+	now := s.now()
+	req.App, req.Created, req.Updated, req.UUID = a.ID, now, now, newUUID()
+	if req.Path == nil {
+		req.Path = map[string]any{}
+	}
+	a.volumes.put(req.Name, req)
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// volumeFor returns the volume named in the request path, or writes a 404 response.
+func (s *Server) volumeFor(w http.ResponseWriter, r *http.Request) (*app, api.Volume, bool) {
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return nil, api.Volume{}, false
+	}
+	v, ok := a.volumes.get(r.PathValue("name"))
+	if !ok {
+		notFound(w)
+	}
+	return a, v, ok
+}
+
+func (s *Server) getVolume(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if _, v, ok := s.volumeFor(w, r); ok {
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (s *Server) expandVolume(w http.ResponseWriter, r *http.Request) {
+	var req api.Volume
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, v, ok := s.volumeFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Size == "" {
+		writeError(w, http.StatusBadRequest, FieldError("size", "This field may not be blank."))
+		return
+	}
+	v.Size, v.Updated = req.Size, s.now()
+	a.volumes.put(v.Name, v)
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) deleteVolume(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !a.volumes.delete(r.PathValue("name")) {
+		notFound(w)
+		return
+	}
+	delete(a.filers, r.PathValue("name"))
+	delete(a.bound, r.PathValue("name"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) mountVolume(w http.ResponseWriter, r *http.Request) {
+	var req api.Volume
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, v, ok := s.volumeFor(w, r)
+	if !ok {
+		return
+	}
+	if len(req.Path) == 0 {
+		writeError(w, http.StatusConflict, DetailError("Volume path is unchanged."))
+		return
+	}
+	path := map[string]any{}
+	for ptype, p := range v.Path {
+		path[ptype] = p
+	}
+	for ptype, p := range req.Path {
+		_, exists := path[ptype]
+		switch {
+		case p == nil && !exists:
+			writeError(w, http.StatusUnprocessableEntity, DetailError(fmt.Sprintf("%s does not exist under path", ptype)))
+			return
+		case p == nil:
+			delete(path, ptype)
+		default:
+			path[ptype] = p
+		}
+	}
+	v.Path, v.Updated = path, s.now()
+	a.volumes.put(v.Name, v)
+	s.newRelease(a, fmt.Sprintf("%s changed volumes", s.Username), a.latest().build, a.latest().config)
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (s *Server) bindFiler(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, v, ok := s.volumeFor(w, r); ok {
+		a.bound[v.Name] = true
+		writeJSON(w, http.StatusOK, map[string]string{"username": v.Name, "password": v.UUID})
+	}
+}
+
+func (s *Server) unbindFiler(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, v, ok := s.volumeFor(w, r); ok {
+		delete(a.bound, v.Name)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) pingFiler(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, v, ok := s.volumeFor(w, r)
+	switch {
+	case !ok:
+	case !a.bound[v.Name]:
+		notFound(w)
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// serveFiler serves the WebDAV filer of a volume. Requests are served without holding the
+// lock of the server, as their bodies are streamed.
+func (s *Server) serveFiler(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	a, _, ok := s.volumeFor(w, r)
+	var fs webdav.FileSystem
+	if ok {
+		fs = a.filer(r.PathValue("name"))
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	handler := &webdav.Handler{
+		Prefix:     fmt.Sprintf("/v2/apps/%s/volumes/%s/filer/webdav", a.ID, r.PathValue("name")),
+		FileSystem: fs,
+		LockSystem: webdav.NewMemLS(),
+	}
+	handler.ServeHTTP(w, r)
+}
+
+func (s *Server) listResourceServices(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	services := make(api.ResourceServices, 0, s.services.len())
+	for _, svc := range s.services.list() {
+		services = append(services, svc.ResourceService)
+	}
+	s.writePage(w, r, services)
+}
+
+func (s *Server) listResourcePlans(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	svc, ok := s.services.get(r.PathValue("service"))
+	if !ok {
+		notFound(w)
+		return
+	}
+	s.writePage(w, r, svc.plans.list())
+}
+
+func (s *Server) listResources(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if a, ok := s.appFor(w, r); ok {
+		s.writePage(w, r, a.resources.list())
+	}
+}
+
+func (s *Server) createResource(w http.ResponseWriter, r *http.Request) {
+	var req api.Resource
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !validName.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Can only contain a-z (lowercase), 0-9 and hyphens"))
+		return
+	}
+	if _, ok := a.resources.get(req.Name); ok {
+		writeError(w, http.StatusBadRequest, FieldError("name", "Resource with this name already exists."))
+		return
+	}
+	if req.Plan == "" {
+		writeError(w, http.StatusBadRequest, FieldError("plan", "This field may not be blank."))
+		return
+	}
+	now := s.now()
+	req.App, req.Created, req.Updated, req.UUID = a.ID, now, now, newUUID()
+	req.Status = "Ready"
+	a.resources.put(req.Name, req)
+	writeJSON(w, http.StatusCreated, req)
+}
+
+// resourceFor returns the resource named in the request path, or writes a 404 response.
+func (s *Server) resourceFor(w http.ResponseWriter, r *http.Request) (*app, api.Resource, bool) {
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return nil, api.Resource{}, false
+	}
+	res, ok := a.resources.get(r.PathValue("name"))
+	if !ok {
+		notFound(w)
+	}
+	return a, res, ok
+}
+
+func (s *Server) getResource(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	if _, res, ok := s.resourceFor(w, r); ok {
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (s *Server) updateResource(w http.ResponseWriter, r *http.Request) {
+	var req api.Resource
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, res, ok := s.resourceFor(w, r)
+	if !ok {
+		return
+	}
+	if req.Plan != "" {
+		res.Plan = req.Plan
+	}
+	if req.Options != nil {
+		res.Options = req.Options
+	}
+	res.Updated = s.now()
+	a.resources.put(res.Name, res)
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) deleteResource(w http.ResponseWriter, r *http.Request) {
+	defer s.lock()()
+	a, ok := s.appFor(w, r)
+	if !ok {
+		return
+	}
+	if !a.resources.delete(r.PathValue("name")) {
+		notFound(w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) bindResource(w http.ResponseWriter, r *http.Request) {
+	var req api.ResourceBinding
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer s.lock()()
+	a, res, ok := s.resourceFor(w, r)
+	if !ok {
+		return
+	}
+	switch req.BindAction {
+	case "bind":
+		res.Binding = "Ready"
+	case "unbind":
+		res.Binding = ""
+	default:
+		writeError(w, http.StatusBadRequest, FieldError("bind_action", fmt.Sprintf("\"%s\" is not a valid choice.", req.BindAction)))
+		return
+	}
+	res.Updated = s.now()
+	a.resources.put(res.Name, res)
+	writeJSON(w, http.StatusOK, res)
+}
@@ -101,23 +101,117 @@ func (e ErrNotFound) Error() string {
 	return e.errorMsg
 }
 
-// checkForErrors tries to match up an API error with an predefined error in the SDK.
+// APIError is returned for every error response of the controller. It wraps the predefined
+// SDK error matching the response, such as ErrDuplicateApp or ErrNotFound, so it can be
+// checked with [errors.Is] and [errors.As]:
+//
+//	_, err := apps.New(client, "example-go", "team-a")
+//	if errors.Is(err, drycc.ErrDuplicateApp) {
+//	    // ...
+//	}
+//	var apiErr *drycc.APIError
+//	if errors.As(err, &apiErr) {
+//	    fmt.Println(apiErr.StatusCode, apiErr.Fields["id"])
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the URL of the request.
+	URL string
+	// RequestID is the value of the X-Request-Id header of the response, if any.
+	RequestID string
+	// Detail is the detail message sent by the controller, if any.
+	Detail string
+	// Fields holds the validation errors sent by the controller, keyed by field name.
+	// Errors not tied to a field are stored under "non_field_errors".
+	Fields map[string][]string
+	// Body is the raw body of the response.
+	Body []byte
+	// Err is the SDK error matching the response. If no predefined error matches,
+	// it is an error describing the status code and body.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the SDK error matching the response.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors returns the validation errors of the given field.
+func (e *APIError) FieldErrors(field string) []string {
+	return e.Fields[field]
+}
+
+// checkForErrors tries to match up an API error with an predefined error in the SDK,
+// and wraps it in an *APIError.
 func checkForErrors(res *http.Response) error {
 	if res.StatusCode >= 200 && res.StatusCode < 400 {
 		return nil
 	}
 	defer res.Body.Close()
 
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+	}
+	if res.Request != nil {
+		apiErr.Method = res.Request.Method
+		apiErr.URL = res.Request.URL.String()
+	}
+
 	out, err := io.ReadAll(res.Body)
 	if err != nil {
-		return unknownServerError(res.StatusCode, err.Error())
+		apiErr.Err = unknownServerError(res.StatusCode, err.Error())
+		return apiErr
 	}
+	apiErr.Body = out
+	apiErr.Detail, apiErr.Fields = parseErrorBody(out)
+	apiErr.Err = matchError(res.StatusCode, out)
+	return apiErr
+}
+
+// parseErrorBody extracts the detail message and the field errors of an error body.
+func parseErrorBody(body []byte) (string, map[string][]string) {
+	bodyMap := make(map[string]any)
+	if err := json.Unmarshal(body, &bodyMap); err != nil {
+		return "", nil
+	}
+
+	var detail string
+	fields := make(map[string][]string)
+	for key, value := range bodyMap {
+		switch v := value.(type) {
+		case string:
+			if key == "detail" {
+				detail = v
+			} else {
+				fields[key] = []string{v}
+			}
+		case []any:
+			if messages := arrayContents(bodyMap, key); len(messages) > 0 {
+				fields[key] = messages
+			}
+		}
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	return detail, fields
+}
 
-	switch res.StatusCode {
+// matchError returns the predefined error matching an error response.
+func matchError(statusCode int, out []byte) error {
+	switch statusCode {
 	case 400:
 		bodyMap := make(map[string]any)
 		if err := json.Unmarshal(out, &bodyMap); err != nil {
-			return unknownServerError(res.StatusCode, string(out))
+			return unknownServerError(statusCode, string(out))
 		}
 
 		if scanResponse(bodyMap, "username", []string{fieldReqMsg, invalidUserMsg}, true) {
@@ -195,7 +289,7 @@ func checkForErrors(res *http.Response) error {
 				return ErrTagNotFound
 			}
 		}
-		return unknownServerError(res.StatusCode, string(out))
+		return unknownServerError(statusCode, string(out))
 	case 401:
 		return ErrUnauthorized
 	case 403:
@@ -212,16 +306,16 @@ func checkForErrors(res *http.Response) error {
 	case 422:
 		bodyMap := make(map[string]any)
 		if err := json.Unmarshal(out, &bodyMap); err != nil {
-			return unknownServerError(res.StatusCode, fmt.Sprintf(jsonParsingError, err, string(out)))
+			return unknownServerError(statusCode, fmt.Sprintf(jsonParsingError, err, string(out)))
 		}
 		if v, ok := bodyMap["detail"].(string); ok {
 			return ErrUnprocessable{v}
 		}
-		return unknownServerError(res.StatusCode, string(out))
+		return unknownServerError(statusCode, string(out))
 	case 500:
 		return ErrServerError
 	default:
-		return unknownServerError(res.StatusCode, string(out))
+		return unknownServerError(statusCode, string(out))
 	}
 }
 
@@ -298,3 +298,73 @@ newline"}`),
 		}
 	}
 }
+
+func TestAPIError(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://localhost/v2/apps/", nil)
+	res := &http.Response{
+		StatusCode: 400,
+		Header:     http.Header{"X-Request-Id": {"1234"}},
+		Body: readCloser(`{"id":["Application with this id already exists."],` +
+			`"workspace":["This field may not be blank.","Invalid workspace."],"detail":"Bad request"}`),
+		Request: req,
+	}
+
+	err := checkForErrors(res)
+
+	if !errors.Is(err, ErrDuplicateApp) {
+		t.Errorf(failureMessage, ErrDuplicateApp, err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, Got %T", err)
+	}
+
+	if apiErr.StatusCode != 400 || apiErr.Method != "POST" || apiErr.URL != "http://localhost/v2/apps/" {
+		t.Errorf("Unexpected request info %d %s %s", apiErr.StatusCode, apiErr.Method, apiErr.URL)
+	}
+	if apiErr.RequestID != "1234" {
+		t.Errorf("Expected %s, Got %s", "1234", apiErr.RequestID)
+	}
+	if apiErr.Detail != "Bad request" {
+		t.Errorf("Expected %s, Got %s", "Bad request", apiErr.Detail)
+	}
+
+	expected := []string{"This field may not be blank.", "Invalid workspace."}
+	if actual := apiErr.FieldErrors("workspace"); fmt.Sprint(actual) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, Got %v", expected, actual)
+	}
+	if len(apiErr.Fields) != 2 {
+		t.Errorf("Expected 2 fields, Got %v", apiErr.Fields)
+	}
+}
+
+func TestAPIErrorUnknown(t *testing.T) {
+	res := &http.Response{
+		StatusCode: 418,
+		Body:       readCloser(`{"detail":"I'm a teapot"}`),
+	}
+
+	err := checkForErrors(res)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected an *APIError, Got %T", err)
+	}
+	if apiErr.StatusCode != 418 || apiErr.Detail != "I'm a teapot" {
+		t.Errorf("Unexpected error %d %s", apiErr.StatusCode, apiErr.Detail)
+	}
+	if string(apiErr.Body) != `{"detail":"I'm a teapot"}` {
+		t.Errorf("Unexpected body %s", apiErr.Body)
+	}
+
+	res = &http.Response{
+		StatusCode: 404,
+		Body:       readCloser("App not found"),
+	}
+
+	var notFound ErrNotFound
+	if err = checkForErrors(res); !errors.As(err, &notFound) || notFound.Error() != "App not found" {
+		t.Errorf(failureMessage, ErrNotFound{"App not found"}, err)
+	}
+}
@@ -2,8 +2,10 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // ListPodEvents lists events of an app process.
 func ListPodEvents(c *drycc.Client, appID string, podName string, results int) (api.AppEvents, int, error) {
+	return ListPodEventsWithContext(context.Background(), c, appID, podName, results)
+}
+
+// ListPodEventsWithContext is like [ListPodEvents] but cancels the request when ctx is done.
+func ListPodEventsWithContext(ctx context.Context, c *drycc.Client, appID string, podName string, results int) (api.AppEvents, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/events/?pod_name=%s", appID, podName)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.AppEvent{}, -1, reqErr
 	}
@@ -25,10 +32,25 @@ func ListPodEvents(c *drycc.Client, appID string, podName string, results int) (
 	return events, count, reqErr
 }
 
+// AllPodEvents returns an iterator over all events of an app process, fetching pages lazily.
+func AllPodEvents(c *drycc.Client, appID string, podName string) iter.Seq2[api.AppEvent, error] {
+	return AllPodEventsWithContext(context.Background(), c, appID, podName)
+}
+
+// AllPodEventsWithContext is like [AllPodEvents] but cancels the requests when ctx is done.
+func AllPodEventsWithContext(ctx context.Context, c *drycc.Client, appID string, podName string) iter.Seq2[api.AppEvent, error] {
+	return drycc.Paginate[api.AppEvent](ctx, c, fmt.Sprintf("/v2/apps/%s/events/?pod_name=%s", appID, podName), drycc.DefaultPageSize)
+}
+
 // ListPtypeEvents lists events of an app ptype.
 func ListPtypeEvents(c *drycc.Client, appID string, ptype string, results int) (api.AppEvents, int, error) {
+	return ListPtypeEventsWithContext(context.Background(), c, appID, ptype, results)
+}
+
+// ListPtypeEventsWithContext is like [ListPtypeEvents] but cancels the request when ctx is done.
+func ListPtypeEventsWithContext(ctx context.Context, c *drycc.Client, appID string, ptype string, results int) (api.AppEvents, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/events/?ptype=%s-%s", appID, appID, ptype)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.AppEvent{}, -1, reqErr
 	}
@@ -40,3 +62,13 @@ func ListPtypeEvents(c *drycc.Client, appID string, ptype string, results int) (
 
 	return events, count, reqErr
 }
+
+// AllPtypeEvents returns an iterator over all events of an app ptype, fetching pages lazily.
+func AllPtypeEvents(c *drycc.Client, appID string, ptype string) iter.Seq2[api.AppEvent, error] {
+	return AllPtypeEventsWithContext(context.Background(), c, appID, ptype)
+}
+
+// AllPtypeEventsWithContext is like [AllPtypeEvents] but cancels the requests when ctx is done.
+func AllPtypeEventsWithContext(ctx context.Context, c *drycc.Client, appID string, ptype string) iter.Seq2[api.AppEvent, error] {
+	return drycc.Paginate[api.AppEvent](ctx, c, fmt.Sprintf("/v2/apps/%s/events/?ptype=%s-%s", appID, appID, ptype), drycc.DefaultPageSize)
+}
@@ -2,8 +2,10 @@
 package gateways
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // List gateways registered with an app.
 func List(c *drycc.Client, appID string, results int) (api.Gateways, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Gateways, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/gateways/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Gateway{}, -1, reqErr
@@ -26,8 +33,23 @@ func List(c *drycc.Client, appID string, results int) (api.Gateways, int, error)
 	return gateways, count, reqErr
 }
 
+// All returns an iterator over all gateways of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Gateway, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Gateway, error] {
+	return drycc.Paginate[api.Gateway](ctx, c, fmt.Sprintf("/v2/apps/%s/gateways/", appID), drycc.DefaultPageSize)
+}
+
 // New adds a gateway to an app.
 func New(c *drycc.Client, appID string, name string, port int, protocol string) error {
+	return NewWithContext(context.Background(), c, appID, name, port, protocol)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID string, name string, port int, protocol string) error {
 	u := fmt.Sprintf("/v2/apps/%s/gateways/", appID)
 
 	req := api.GatewayCreateRequest{Name: name, Port: port, Protocol: protocol}
@@ -37,7 +59,7 @@ func New(c *drycc.Client, appID string, name string, port int, protocol string)
 		return err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -48,6 +70,11 @@ func New(c *drycc.Client, appID string, name string, port int, protocol string)
 
 // Delete removes a gateway or listener of gateway from an app.
 func Delete(c *drycc.Client, appID string, name string, port int, protocol string) error {
+	return DeleteWithContext(context.Background(), c, appID, name, port, protocol)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, name string, port int, protocol string) error {
 	u := fmt.Sprintf("/v2/apps/%s/gateways/", appID)
 
 	req := api.GatewayRemoveRequest{Name: name, Port: port, Protocol: protocol}
@@ -57,7 +84,7 @@ func Delete(c *drycc.Client, appID string, name string, port int, protocol strin
 		return err
 	}
 
-	res, err := c.Request("DELETE", u, body)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, body)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -4,6 +4,7 @@
 package hooks
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -13,7 +14,12 @@ import (
 
 // UserFromKey retrives a user from their SSH key fingerprint.
 func UserFromKey(c *drycc.Client, fingerprint string) (api.UserApps, error) {
-	res, reqErr := c.Request("GET", fmt.Sprintf("/v2/hooks/key/%s", fingerprint), nil)
+	return UserFromKeyWithContext(context.Background(), c, fingerprint)
+}
+
+// UserFromKeyWithContext is like [UserFromKey] but cancels the request when ctx is done.
+func UserFromKeyWithContext(ctx context.Context, c *drycc.Client, fingerprint string) (api.UserApps, error) {
+	res, reqErr := c.RequestWithContext(ctx, "GET", fmt.Sprintf("/v2/hooks/key/%s", fingerprint), nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.UserApps{}, reqErr
 	}
@@ -30,13 +36,18 @@ func UserFromKey(c *drycc.Client, fingerprint string) (api.UserApps, error) {
 
 // GetAppConfig retrives an app's configuration from the controller.
 func GetAppConfig(c *drycc.Client, username, app string) (api.Config, error) {
+	return GetAppConfigWithContext(context.Background(), c, username, app)
+}
+
+// GetAppConfigWithContext is like [GetAppConfig] but cancels the request when ctx is done.
+func GetAppConfigWithContext(ctx context.Context, c *drycc.Client, username, app string) (api.Config, error) {
 	req := api.ConfigHookRequest{User: username, App: app}
 	b, err := json.Marshal(req)
 	if err != nil {
 		return api.Config{}, err
 	}
 
-	res, reqErr := c.Request("POST", "/v2/hooks/config/", b)
+	res, reqErr := c.RequestWithContext(ctx, "POST", "/v2/hooks/config/", b)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Config{}, reqErr
 	}
@@ -55,6 +66,13 @@ func GetAppConfig(c *drycc.Client, username, app string) (api.Config, error) {
 // location for the dockerfile app the absolute url to the tar file for a buldpack app.
 func CreateBuild(c *drycc.Client, username, app, image, stack, gitSha string,
 	procfile api.ProcessType, dryccfile map[string]any, dockerfile string,
+) (int, error) {
+	return CreateBuildWithContext(context.Background(), c, username, app, image, stack, gitSha, procfile, dryccfile, dockerfile)
+}
+
+// CreateBuildWithContext is like [CreateBuild] but cancels the request when ctx is done.
+func CreateBuildWithContext(ctx context.Context, c *drycc.Client, username, app, image, stack, gitSha string,
+	procfile api.ProcessType, dryccfile map[string]any, dockerfile string,
 ) (int, error) {
 	req := api.BuildHookRequest{
 		Sha:        gitSha,
@@ -72,7 +90,7 @@ func CreateBuild(c *drycc.Client, username, app, image, stack, gitSha string,
 		return -1, err
 	}
 
-	res, reqErr := c.Request("POST", "/v2/hooks/build/", b)
+	res, reqErr := c.RequestWithContext(ctx, "POST", "/v2/hooks/build/", b)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return -1, reqErr
 	}
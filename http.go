@@ -2,6 +2,7 @@ package drycc
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -38,27 +39,32 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 
 	addUserAgent(&req.Header, c.UserAgent)
 
-	res, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return res, err
-	}
-
-	if err = checkForErrors(res); err != nil {
-		return res, err
-	}
+	return c.intercept(req, func(req *http.Request) (*http.Response, error) {
+		res, err := c.send(req)
+		if err != nil {
+			return res, err
+		}
 
-	apiVersion := res.Header.Get("DRYCC_API_VERSION")
+		if err = checkForErrors(res); err != nil {
+			return res, err
+		}
 
-	// Update controller api and platform version
-	c.ControllerAPIVersion = apiVersion
-	setControllerVersion(c, res.Header)
+		// Update controller api and platform version
+		apiVersion := c.setVersions(res.Header)
 
-	// Return results along with api compatibility error
-	return res, CheckAPICompatibility(apiVersion, APIVersion)
+		// Return results along with api compatibility error
+		return res, c.checkAPIVersion(apiVersion)
+	})
 }
 
 // NewRequest wraps [NewRequestWithContext] using [context.Background].
 func (c *Client) NewRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, path, body)
+}
+
+// NewRequestWithContext returns a new request for the given method and path relative to
+// the controller URL. The provided ctx controls the entire lifetime of the request.
+func (c *Client) NewRequestWithContext(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
 	url := *c.ControllerURL
 
 	if strings.Contains(path, "?") {
@@ -68,14 +74,19 @@ func (c *Client) NewRequest(method string, path string, body io.Reader) (*http.R
 	} else {
 		url.Path = path
 	}
-	return http.NewRequest(method, url.String(), body)
+	return http.NewRequestWithContext(ctx, method, url.String(), body)
 }
 
 // Request makes a HTTP request with the given method, relative URL, and body on the controller.
 // It also sets the Authorization and Content-Type headers to properly authenticate and communicate
 // API. This is primarily intended to use be used by the SDK itself, but could potentially be used elsewhere.
 func (c *Client) Request(method string, path string, body []byte) (*http.Response, error) {
-	req, err := c.NewRequest(method, path, bytes.NewBuffer(body))
+	return c.RequestWithContext(context.Background(), method, path, body)
+}
+
+// RequestWithContext is like [Client.Request] but cancels the request when ctx is done.
+func (c *Client) RequestWithContext(ctx context.Context, method string, path string, body []byte) (*http.Response, error) {
+	req, err := c.NewRequestWithContext(ctx, method, path, bytes.NewBuffer(body))
 	if err != nil {
 		return nil, err
 	}
@@ -84,6 +95,11 @@ func (c *Client) Request(method string, path string, body []byte) (*http.Respons
 
 // LimitedRequest allows limiting the number of responses in a request.
 func (c *Client) LimitedRequest(path string, results int) (string, int, error) {
+	return c.LimitedRequestWithContext(context.Background(), path, results)
+}
+
+// LimitedRequestWithContext is like [Client.LimitedRequest] but cancels the request when ctx is done.
+func (c *Client) LimitedRequestWithContext(ctx context.Context, path string, results int) (string, int, error) {
 	var query string
 	u, err := url.Parse(path)
 	if err != nil {
@@ -96,7 +112,7 @@ func (c *Client) LimitedRequest(path string, results int) (string, int, error) {
 		query = "?limit=" + strconv.Itoa(results)
 	}
 
-	res, reqErr := c.Request("GET", path+query, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", path+query, nil)
 
 	if reqErr != nil && !IsErrAPIMismatch(reqErr) {
 		return "", -1, reqErr
@@ -124,19 +140,23 @@ func (c *Client) LimitedRequest(path string, results int) (string, int, error) {
 
 // CheckConnection checks that the user is connected to a network and the URL points to a valid controller.
 func (c *Client) CheckConnection() error {
+	return c.CheckConnectionWithContext(context.Background())
+}
+
+// CheckConnectionWithContext is like [Client.CheckConnection] but cancels the request when ctx is done.
+func (c *Client) CheckConnectionWithContext(ctx context.Context) error {
 	errorMessage := `%s does not appear to be a valid Drycc controller.
 Make sure that the Controller URI is correct, the server is running and
 your drycc version is correct.`
 
 	// Make a request to /v2/ and expect a 401 response
-	req, err := http.NewRequest("GET", c.ControllerURL.String()+"/v2/", bytes.NewBuffer(nil))
-	addUserAgent(&req.Header, c.UserAgent)
-
+	req, err := http.NewRequestWithContext(ctx, "GET", c.ControllerURL.String()+"/v2/", bytes.NewBuffer(nil))
 	if err != nil {
 		return err
 	}
+	addUserAgent(&req.Header, c.UserAgent)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.intercept(req, c.send)
 	if err != nil {
 		return err
 	}
@@ -147,50 +167,75 @@ your drycc version is correct.`
 	}
 
 	// Update controller api version
-	apiVersion := res.Header.Get("DRYCC_API_VERSION")
-	c.ControllerAPIVersion = apiVersion
-	setControllerVersion(c, res.Header)
+	apiVersion := c.setVersions(res.Header)
 
-	return CheckAPICompatibility(apiVersion, APIVersion)
+	return c.checkAPIVersion(apiVersion)
 }
 
 // Healthcheck can be called to see if the controller is healthy
 func (c *Client) Healthcheck() error {
+	return c.HealthcheckWithContext(context.Background())
+}
+
+// HealthcheckWithContext is like [Client.Healthcheck] but cancels the request when ctx is done.
+func (c *Client) HealthcheckWithContext(ctx context.Context) error {
 	// Make a request to /healthz and expect an ok HTTP response
 	controllerURL := c.ControllerURL.String()
 	// Don't double the last slash in the URL path
 	if !strings.HasSuffix(controllerURL, "/") {
 		controllerURL = controllerURL + "/"
 	}
-	req, err := http.NewRequest("GET", controllerURL+"healthz", bytes.NewBuffer(nil))
-	addUserAgent(&req.Header, c.UserAgent)
-
+	req, err := http.NewRequestWithContext(ctx, "GET", controllerURL+"healthz", bytes.NewBuffer(nil))
 	if err != nil {
 		return err
 	}
+	addUserAgent(&req.Header, c.UserAgent)
 
-	res, err := c.HTTPClient.Do(req)
+	res, err := c.intercept(req, func(req *http.Request) (*http.Response, error) {
+		res, err := c.send(req)
+		if err != nil {
+			return res, err
+		}
+		return res, checkForErrors(res)
+	})
 	if err != nil {
 		return err
 	}
-
-	if err = checkForErrors(res); err != nil {
-		return err
-	}
 	res.Body.Close()
 
 	// Update controller api version
-	apiVersion := res.Header.Get("DRYCC_API_VERSION")
-	c.ControllerAPIVersion = apiVersion
-	setControllerVersion(c, res.Header)
+	apiVersion := c.setVersions(res.Header)
 
-	return CheckAPICompatibility(apiVersion, APIVersion)
+	return c.checkAPIVersion(apiVersion)
 }
 
 func addUserAgent(headers *http.Header, userAgent string) {
 	headers.Add("User-Agent", userAgent)
 }
 
-func setControllerVersion(c *Client, headers http.Header) {
+// setVersions records the controller api and platform versions sent in headers
+// and returns the api version.
+func (c *Client) setVersions(headers http.Header) string {
+	apiVersion := headers.Get("DRYCC_API_VERSION")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ControllerAPIVersion = apiVersion
 	c.ControllerVersion = headers.Get("DRYCC_PLATFORM_VERSION")
+	return apiVersion
+}
+
+// ServerAPIVersion returns the API version of the controller, as reported by the last response.
+// It is safe to call concurrently with requests.
+func (c *Client) ServerAPIVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ControllerAPIVersion
+}
+
+// ServerVersion returns the platform version of the controller, as reported by the last response.
+// It is safe to call concurrently with requests.
+func (c *Client) ServerVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ControllerVersion
 }
@@ -1,10 +1,13 @@
 package drycc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
@@ -256,3 +259,142 @@ func TestHealthcheck(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRequestWithContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{Version: APIVersion}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.UserAgent = "test"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err = drycc.RequestWithContext(ctx, "POST", "/request/", []byte("test")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+
+	if _, _, err = drycc.LimitedRequestWithContext(ctx, "/limited/", 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+
+	if err = drycc.HealthcheckWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+}
+
+func TestNewRequestWithContext(t *testing.T) {
+	t.Parallel()
+
+	drycc, err := New(false, "http://localhost", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), fakeHTTPServer{}, "value")
+	req, err := drycc.NewRequestWithContext(ctx, "GET", "/v2/apps/?limit=10", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Context() != ctx {
+		t.Error("Expected request to carry the given context")
+	}
+
+	expected := "http://localhost/v2/apps/?limit=10"
+	if req.URL.String() != expected {
+		t.Errorf("Expected %s, Got %s", expected, req.URL.String())
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{Version: APIVersion, PlatformVersion: "v9000"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.UserAgent = "test"
+	drycc.ServiceKey = "testing"
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			res, err := drycc.Request("POST", "/request/", []byte("test"))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := drycc.LimitedRequest("/limited/", 2); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			drycc.ServerAPIVersion()
+			drycc.ServerVersion()
+		}()
+	}
+	wg.Wait()
+
+	if drycc.ServerAPIVersion() != handler.Version {
+		t.Errorf("Expected %s, Got %s", handler.Version, drycc.ServerAPIVersion())
+	}
+
+	if drycc.ServerVersion() != handler.PlatformVersion {
+		t.Errorf("Expected %s, Got %s", handler.PlatformVersion, drycc.ServerVersion())
+	}
+}
+
+func TestWithoutRedirect(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("DRYCC_API_VERSION", APIVersion)
+		if req.URL.Path == "/redirect/" {
+			http.Redirect(res, req, "/target/", http.StatusFound)
+			return
+		}
+		res.Write([]byte("target"))
+	}))
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := drycc.RequestWithContext(WithoutRedirect(context.Background()), "GET", "/redirect/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Errorf("Expected %d, Got %d", http.StatusFound, res.StatusCode)
+	}
+
+	res, err = drycc.Request("GET", "/redirect/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected %d, Got %d", http.StatusOK, res.StatusCode)
+	}
+}
@@ -0,0 +1,45 @@
+package drycc
+
+import (
+	"net/http"
+)
+
+// Handler sends a request to the controller. It returns the response along with the error
+// mapped by the SDK, such as ErrUnauthorized or ErrAPIMismatch, exactly as [Client.Do] would.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the handling of a request made by a [Client]. It may inspect or modify
+// the outgoing request, call next to send it, then inspect or replace the outcome.
+// Interceptors that don't call next must return a non-nil response or error.
+//
+// Interceptors also wrap websocket handshakes made with [Client.DialWebsocket]. For those,
+// req has a ws or wss URL and, if the handshake succeeds, next returns a response with
+// status 101 Switching Protocols and no body.
+//
+// The following interceptor logs every request along with its outcome:
+//
+//	client.Interceptors = append(client.Interceptors, func(req *http.Request, next drycc.Handler) (*http.Response, error) {
+//	    start := time.Now()
+//	    res, err := next(req)
+//	    log.Printf("%s %s (%v): %v", req.Method, req.URL, time.Since(start), err)
+//	    return res, err
+//	})
+type Interceptor func(req *http.Request, next Handler) (*http.Response, error)
+
+// Use appends interceptors to the client. The first interceptor is the outermost one:
+// it sees the request first and the outcome last. Use must not be called while
+// requests are in flight.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.Interceptors = append(c.Interceptors, interceptors...)
+}
+
+// intercept runs req through the client's interceptors, terminated by handler.
+func (c *Client) intercept(req *http.Request, handler Handler) (*http.Response, error) {
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.Interceptors[i], handler
+		handler = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, next)
+		}
+	}
+	return handler(req)
+}
@@ -0,0 +1,135 @@
+package drycc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestInterceptors(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{Version: APIVersion}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.ServiceKey = "testing"
+
+	var calls []string
+	var outcome error
+	drycc.Use(
+		func(req *http.Request, next Handler) (*http.Response, error) {
+			calls = append(calls, "outer")
+			res, err := next(req)
+			outcome = err
+			return res, err
+		},
+		func(req *http.Request, next Handler) (*http.Response, error) {
+			calls = append(calls, "inner")
+			// The fake server only accepts requests with this user agent.
+			req.Header.Set("User-Agent", "test")
+			if req.Header.Get("Authorization") != "token abc" {
+				t.Errorf("Expected the request to be authenticated, Got %q", req.Header.Get("Authorization"))
+			}
+			return next(req)
+		},
+	)
+
+	res, err := drycc.Request("POST", "/request/", []byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if expected := []string{"outer", "inner"}; !reflect.DeepEqual(expected, calls) {
+		t.Errorf("Expected %v, Got %v", expected, calls)
+	}
+
+	// Interceptors see the error mapped by the SDK.
+	if _, err = drycc.Request("GET", "/unknown/", nil); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !errors.As(outcome, &ErrNotFound{}) {
+		t.Errorf("Expected ErrNotFound, Got %v", outcome)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	t.Parallel()
+
+	drycc, err := NewClient("http://drycc.invalid", WithInterceptors(
+		func(req *http.Request, _ Handler) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Drycc_api_version": {APIVersion}},
+				Body:       io.NopCloser(http.NoBody),
+				Request:    req,
+			}, nil
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := drycc.Request("GET", "/v2/apps/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if _, err = drycc.DialWebsocket(context.Background(), "/v2/ws/"); err == nil {
+		t.Error("Expected an error when no handshake is performed")
+	}
+}
+
+func TestInterceptorWebsocket(t *testing.T) {
+	t.Parallel()
+
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		headers <- conn.Request().Header
+		io.Copy(conn, conn)
+	}))
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var status int
+	drycc.Use(func(req *http.Request, next Handler) (*http.Response, error) {
+		if req.URL.Scheme != "ws" {
+			t.Errorf("Expected a ws url, Got %s", req.URL)
+		}
+		req.Header.Set("X-Request-Id", "1234")
+		res, err := next(req)
+		if res != nil {
+			status = res.StatusCode
+		}
+		return res, err
+	})
+
+	conn, err := drycc.DialWebsocket(context.Background(), "/v2/ws/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if status != http.StatusSwitchingProtocols {
+		t.Errorf("Expected %d, Got %d", http.StatusSwitchingProtocols, status)
+	}
+	if header := <-headers; header.Get("X-Request-Id") != "1234" {
+		t.Errorf("Expected %s, Got %s", "1234", header.Get("X-Request-Id"))
+	}
+}
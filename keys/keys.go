@@ -2,8 +2,10 @@
 package keys
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,7 +13,12 @@ import (
 
 // List lists a user's ssh keys.
 func List(c *drycc.Client, results int) (api.Keys, int, error) {
-	body, count, reqErr := c.LimitedRequest("/v2/keys/", results)
+	return ListWithContext(context.Background(), c, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, results int) (api.Keys, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, "/v2/keys/", results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Key{}, -1, reqErr
@@ -25,17 +32,32 @@ func List(c *drycc.Client, results int) (api.Keys, int, error) {
 	return keys, count, reqErr
 }
 
+// All returns an iterator over all keys of the user, fetching pages lazily.
+func All(c *drycc.Client) iter.Seq2[api.Key, error] {
+	return AllWithContext(context.Background(), c)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client) iter.Seq2[api.Key, error] {
+	return drycc.Paginate[api.Key](ctx, c, "/v2/keys/", drycc.DefaultPageSize)
+}
+
 // New adds a new ssh key for the user. This is used for authenting with the git
 // remote for the builder. This key must be unique to the current user, or the error
 // drycc.ErrDuplicateKey will be returned.
 func New(c *drycc.Client, id string, pubKey string) (api.Key, error) {
+	return NewWithContext(context.Background(), c, id, pubKey)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, id string, pubKey string) (api.Key, error) {
 	req := api.KeyCreateRequest{ID: id, Public: pubKey}
 	body, err := json.Marshal(req)
 	if err != nil {
 		return api.Key{}, err
 	}
 
-	res, reqErr := c.Request("POST", "/v2/keys/", body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", "/v2/keys/", body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Key{}, reqErr
 	}
@@ -52,9 +74,14 @@ func New(c *drycc.Client, id string, pubKey string) (api.Key, error) {
 // Delete removes a user's ssh key. The key ID will be the key comment, usually the email or user@hostname
 // of the user. The exact keyID can be retrieved with List()
 func Delete(c *drycc.Client, keyID string) error {
+	return DeleteWithContext(context.Background(), c, keyID)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, keyID string) error {
 	u := fmt.Sprintf("/v2/keys/%s", keyID)
 
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -2,8 +2,10 @@
 package limits
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"strings"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -12,12 +14,12 @@ import (
 
 // Specs is list all available limit specs
 func Specs(c *drycc.Client, keywords string, results int) ([]api.LimitSpec, int, error) {
-	u := "/v2/limits/specs/"
-	if keywords != "" {
-		u += fmt.Sprintf("?keywords=%s", keywords)
-	}
+	return SpecsWithContext(context.Background(), c, keywords, results)
+}
 
-	body, count, reqErr := c.LimitedRequest(u, results)
+// SpecsWithContext is like [Specs] but cancels the request when ctx is done.
+func SpecsWithContext(ctx context.Context, c *drycc.Client, keywords string, results int) ([]api.LimitSpec, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, specsPath(keywords), results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.LimitSpec{}, -1, reqErr
 	}
@@ -28,24 +30,24 @@ func Specs(c *drycc.Client, keywords string, results int) ([]api.LimitSpec, int,
 	return limitSpecs, count, reqErr
 }
 
+// AllSpecs returns an iterator over all available limit specs, fetching pages lazily.
+func AllSpecs(c *drycc.Client, keywords string) iter.Seq2[api.LimitSpec, error] {
+	return AllSpecsWithContext(context.Background(), c, keywords)
+}
+
+// AllSpecsWithContext is like [AllSpecs] but cancels the requests when ctx is done.
+func AllSpecsWithContext(ctx context.Context, c *drycc.Client, keywords string) iter.Seq2[api.LimitSpec, error] {
+	return drycc.Paginate[api.LimitSpec](ctx, c, specsPath(keywords), drycc.DefaultPageSize)
+}
+
 // Plans is list all available limit plans
 func Plans(c *drycc.Client, specID string, cpu, memory, results int) ([]api.LimitPlan, int, error) {
-	var queryArray []string
-	if cpu > 0 {
-		queryArray = append(queryArray, fmt.Sprintf("cpu=%d", cpu))
-	}
-	if memory > 0 {
-		queryArray = append(queryArray, fmt.Sprintf("memory=%d", memory))
-	}
-	if specID != "" {
-		queryArray = append(queryArray, fmt.Sprintf("spec-id=%s", specID))
-	}
-	u := "/v2/limits/plans/"
-	if len(queryArray) > 0 {
-		u = fmt.Sprintf("%s?%s", u, strings.Join(queryArray, "&"))
-	}
+	return PlansWithContext(context.Background(), c, specID, cpu, memory, results)
+}
 
-	body, count, reqErr := c.LimitedRequest(u, results)
+// PlansWithContext is like [Plans] but cancels the request when ctx is done.
+func PlansWithContext(ctx context.Context, c *drycc.Client, specID string, cpu, memory, results int) ([]api.LimitPlan, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, plansPath(specID, cpu, memory), results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.LimitPlan{}, -1, reqErr
@@ -57,10 +59,25 @@ func Plans(c *drycc.Client, specID string, cpu, memory, results int) ([]api.Limi
 	return limitPlans, count, reqErr
 }
 
+// AllPlans returns an iterator over all available limit plans, fetching pages lazily.
+func AllPlans(c *drycc.Client, specID string, cpu, memory int) iter.Seq2[api.LimitPlan, error] {
+	return AllPlansWithContext(context.Background(), c, specID, cpu, memory)
+}
+
+// AllPlansWithContext is like [AllPlans] but cancels the requests when ctx is done.
+func AllPlansWithContext(ctx context.Context, c *drycc.Client, specID string, cpu, memory int) iter.Seq2[api.LimitPlan, error] {
+	return drycc.Paginate[api.LimitPlan](ctx, c, plansPath(specID, cpu, memory), drycc.DefaultPageSize)
+}
+
 // GetPlan is get a available Plan
 func GetPlan(c *drycc.Client, planID string) (api.LimitPlan, error) {
+	return GetPlanWithContext(context.Background(), c, planID)
+}
+
+// GetPlanWithContext is like [GetPlan] but cancels the request when ctx is done.
+func GetPlanWithContext(ctx context.Context, c *drycc.Client, planID string) (api.LimitPlan, error) {
 	u := fmt.Sprintf("/v2/limits/plans/%s/", planID)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.LimitPlan{}, reqErr
@@ -72,3 +89,29 @@ func GetPlan(c *drycc.Client, planID string) (api.LimitPlan, error) {
 	}
 	return limitPlan, reqErr
 }
+
+func specsPath(keywords string) string {
+	u := "/v2/limits/specs/"
+	if keywords != "" {
+		u += fmt.Sprintf("?keywords=%s", keywords)
+	}
+	return u
+}
+
+func plansPath(specID string, cpu, memory int) string {
+	var queryArray []string
+	if cpu > 0 {
+		queryArray = append(queryArray, fmt.Sprintf("cpu=%d", cpu))
+	}
+	if memory > 0 {
+		queryArray = append(queryArray, fmt.Sprintf("memory=%d", memory))
+	}
+	if specID != "" {
+		queryArray = append(queryArray, fmt.Sprintf("spec-id=%s", specID))
+	}
+	u := "/v2/limits/plans/"
+	if len(queryArray) > 0 {
+		u = fmt.Sprintf("%s?%s", u, strings.Join(queryArray, "&"))
+	}
+	return u
+}
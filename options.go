@@ -0,0 +1,308 @@
+package drycc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Option configures a client created with [NewClient].
+type Option func(*clientConfig) error
+
+type clientConfig struct {
+	token      string
+	serviceKey string
+	userAgent  string
+	verifySSL  bool
+	retry      *RetryPolicy
+
+	interceptors []Interceptor
+
+	mismatchPolicy MismatchPolicy
+	onMismatch     func(serverAPIVersion, clientAPIVersion string)
+
+	rootCAs      *x509.CertPool
+	certificates []tls.Certificate
+
+	keepAlives          bool
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+
+	dialTimeout           time.Duration
+	tlsHandshakeTimeout   time.Duration
+	responseHeaderTimeout time.Duration
+	timeout               time.Duration
+
+	proxy func(*http.Request) (*url.URL, error)
+
+	transport http.RoundTripper
+	// transportOptions is set by the options that configure the default transport,
+	// which can't be applied to a transport given with WithTransport.
+	transportOptions bool
+}
+
+// NewClient creates a new client to communicate with the controller at controllerURL,
+// configured by the given options. Unlike [New], SSL connections are always verified
+// unless [WithInsecureSkipVerify] is given, and keep-alives are only enabled with
+// [WithConnectionPool].
+//
+//	client, err := drycc.NewClient("https://drycc.example.com",
+//	    drycc.WithToken("abc123"),
+//	    drycc.WithCAFile("/etc/drycc/ca.pem"),
+//	    drycc.WithClientCertFile("/etc/drycc/client.pem", "/etc/drycc/client-key.pem"),
+//	    drycc.WithResponseHeaderTimeout(30*time.Second),
+//	)
+func NewClient(controllerURL string, opts ...Option) (*Client, error) {
+	cfg := clientConfig{verifySSL: true, userAgent: DefaultUserAgent, proxy: http.ProxyFromEnvironment}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	u, err := parseControllerURL(controllerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := cfg.transport
+	if transport == nil {
+		transport = cfg.newTransport()
+	} else if cfg.transportOptions {
+		return nil, errors.New("WithTransport cannot be combined with TLS, pooling, timeout or proxy options")
+	}
+
+	return &Client{
+		HTTPClient:    &http.Client{Transport: transport, Timeout: cfg.timeout},
+		VerifySSL:     cfg.verifySSL,
+		ControllerURL: u,
+		Token:         cfg.token,
+		ServiceKey:    cfg.serviceKey,
+		UserAgent:     cfg.userAgent,
+		Retry:         cfg.retry,
+		Interceptors:  cfg.interceptors,
+
+		APIMismatchPolicy: cfg.mismatchPolicy,
+		OnAPIMismatch:     cfg.onMismatch,
+	}, nil
+}
+
+func (cfg *clientConfig) newTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: cfg.dialTimeout, KeepAlive: 30 * time.Second}
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !cfg.verifySSL,
+			RootCAs:            cfg.rootCAs,
+			Certificates:       cfg.certificates,
+		},
+		Proxy:                 cfg.proxy,
+		DialContext:           dialer.DialContext,
+		DisableKeepAlives:     !cfg.keepAlives,
+		MaxIdleConns:          cfg.maxIdleConns,
+		MaxIdleConnsPerHost:   cfg.maxIdleConnsPerHost,
+		IdleConnTimeout:       cfg.idleConnTimeout,
+		TLSHandshakeTimeout:   cfg.tlsHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.responseHeaderTimeout,
+	}
+}
+
+// WithToken sets the token used to authenticate requests.
+func WithToken(token string) Option {
+	return func(cfg *clientConfig) error {
+		cfg.token = token
+		return nil
+	}
+}
+
+// WithServiceKey sets the service key used with the hooks resource.
+func WithServiceKey(serviceKey string) Option {
+	return func(cfg *clientConfig) error {
+		cfg.serviceKey = serviceKey
+		return nil
+	}
+}
+
+// WithUserAgent sets the user agent used when making requests.
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *clientConfig) error {
+		cfg.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithRetry sets the policy used to retry requests that fail with a transient error.
+func WithRetry(policy *RetryPolicy) Option {
+	return func(cfg *clientConfig) error {
+		cfg.retry = policy
+		return nil
+	}
+}
+
+// WithInterceptors appends interceptors wrapping every request sent by the client.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(cfg *clientConfig) error {
+		cfg.interceptors = append(cfg.interceptors, interceptors...)
+		return nil
+	}
+}
+
+// WithAPIMismatchPolicy sets what the client does when the controller API version is not
+// compatible with the SDK. The callback is only used by the MismatchWarn policy.
+func WithAPIMismatchPolicy(policy MismatchPolicy, onMismatch func(serverAPIVersion, clientAPIVersion string)) Option {
+	return func(cfg *clientConfig) error {
+		cfg.mismatchPolicy = policy
+		cfg.onMismatch = onMismatch
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of the controller's certificate.
+// This should only be used if you know the controller is using untrusted SSL keys.
+func WithInsecureSkipVerify() Option {
+	return func(cfg *clientConfig) error {
+		cfg.verifySSL = false
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithRootCAs sets the certificate authorities used to verify the controller's certificate,
+// instead of the system pool.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(cfg *clientConfig) error {
+		cfg.rootCAs = pool
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithCAFile reads a PEM encoded CA bundle from path and uses it to verify the controller's
+// certificate, instead of the system pool.
+func WithCAFile(path string) Option {
+	return func(cfg *clientConfig) error {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", path)
+		}
+		cfg.rootCAs = pool
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithClientCertificate sets the certificate presented to the controller for mutual TLS.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(cfg *clientConfig) error {
+		cfg.certificates = append(cfg.certificates, cert)
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithClientCertFile loads a PEM encoded certificate and private key from files and presents
+// them to the controller for mutual TLS.
+func WithClientCertFile(certFile, keyFile string) Option {
+	return func(cfg *clientConfig) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		cfg.certificates = append(cfg.certificates, cert)
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithConnectionPool enables keep-alives and sets the limits of the idle connection pool.
+// As in [http.Transport], a zero maxIdleConns or idleConnTimeout means no limit, and a zero
+// maxIdleConnsPerHost means [http.DefaultMaxIdleConnsPerHost].
+func WithConnectionPool(maxIdleConns, maxIdleConnsPerHost int, idleConnTimeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.keepAlives = true
+		cfg.maxIdleConns = maxIdleConns
+		cfg.maxIdleConnsPerHost = maxIdleConnsPerHost
+		cfg.idleConnTimeout = idleConnTimeout
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithDialTimeout sets the maximum amount of time to wait for a connection to the controller.
+func WithDialTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.dialTimeout = timeout
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithTLSHandshakeTimeout sets the maximum amount of time to wait for a TLS handshake.
+func WithTLSHandshakeTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.tlsHandshakeTimeout = timeout
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithResponseHeaderTimeout sets the maximum amount of time to wait for the controller's
+// response headers after the request is written.
+func WithResponseHeaderTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.responseHeaderTimeout = timeout
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithTimeout sets a time limit for every request, including reading the response body.
+// It applies to every attempt when retries are enabled.
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		cfg.timeout = timeout
+		return nil
+	}
+}
+
+// WithProxy sets the function returning the proxy to use for a request.
+// By default, the proxy is read from the environment.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(cfg *clientConfig) error {
+		cfg.proxy = proxy
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithProxyURL sends every request through the proxy at proxyURL.
+func WithProxyURL(proxyURL string) Option {
+	return func(cfg *clientConfig) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+		cfg.proxy = http.ProxyURL(u)
+		cfg.transportOptions = true
+		return nil
+	}
+}
+
+// WithTransport sets the base RoundTripper used to send requests. It can't be combined
+// with the options that configure the default transport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *clientConfig) error {
+		cfg.transport = transport
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package drycc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	retry := DefaultRetryPolicy()
+	drycc, err := NewClient("drycc.example.com",
+		WithToken("abc"),
+		WithServiceKey("testing"),
+		WithUserAgent("test"),
+		WithRetry(retry),
+		WithConnectionPool(10, 2, time.Minute),
+		WithDialTimeout(time.Second),
+		WithTLSHandshakeTimeout(2*time.Second),
+		WithResponseHeaderTimeout(3*time.Second),
+		WithTimeout(time.Minute),
+		WithProxyURL("http://proxy.example.com:3128"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if drycc.ControllerURL.String() != "http://drycc.example.com" {
+		t.Errorf("Expected %s, Got %s", "http://drycc.example.com", drycc.ControllerURL)
+	}
+	if drycc.Token != "abc" || drycc.ServiceKey != "testing" || drycc.UserAgent != "test" {
+		t.Errorf("Unexpected credentials %s %s %s", drycc.Token, drycc.ServiceKey, drycc.UserAgent)
+	}
+	if drycc.Retry != retry {
+		t.Error("Expected retry policy to be set")
+	}
+	if !drycc.VerifySSL {
+		t.Error("Expected SSL to be verified by default")
+	}
+	if drycc.HTTPClient.Timeout != time.Minute {
+		t.Errorf("Expected %v, Got %v", time.Minute, drycc.HTTPClient.Timeout)
+	}
+
+	transport := drycc.HTTPClient.Transport.(*http.Transport)
+	if transport.DisableKeepAlives || transport.MaxIdleConns != 10 || transport.MaxIdleConnsPerHost != 2 {
+		t.Errorf("Unexpected pool settings %v %d %d",
+			transport.DisableKeepAlives, transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("Unexpected timeouts %v %v", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+
+	req, _ := http.NewRequest("GET", "http://drycc.example.com/v2/", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy.String() != "http://proxy.example.com:3128" {
+		t.Errorf("Expected proxy %s, Got %v (%v)", "http://proxy.example.com:3128", proxy, err)
+	}
+}
+
+func TestNewClientTransportConflict(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewClient("drycc.example.com", WithTransport(http.DefaultTransport)); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewClient("drycc.example.com", WithTransport(http.DefaultTransport), WithDialTimeout(time.Second)); err == nil {
+		t.Error("Expected an error combining WithTransport with a transport option")
+	}
+}
+
+func TestNewClientMutualTLS(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewUnstartedServer(fakeHTTPServer{Version: APIVersion})
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	// Without a client certificate, the handshake is rejected.
+	drycc, err := NewClient(server.URL, WithRootCAs(pool), WithUserAgent("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = drycc.Healthcheck(); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+
+	drycc, err = NewClient(server.URL, WithRootCAs(pool), WithUserAgent("test"),
+		WithClientCertificate(server.TLS.Certificates[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = drycc.Healthcheck(); err != nil {
+		t.Error(err)
+	}
+
+	// The server certificate is not trusted by the system pool.
+	drycc, err = NewClient(server.URL, WithUserAgent("test"), WithClientCertificate(server.TLS.Certificates[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = drycc.Healthcheck(); err == nil {
+		t.Error("Expected the server certificate to be rejected")
+	}
+}
@@ -0,0 +1,111 @@
+package drycc
+
+import (
+	"context"
+	"encoding/json"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// DefaultPageSize is the number of results requested per page when iterating over a list endpoint.
+const DefaultPageSize = 100
+
+// Page is a single page of results returned by a list endpoint of the controller.
+type Page[T any] struct {
+	// Count is the total number of results across all pages.
+	Count int `json:"count"`
+	// Next is the URL of the next page, or empty if this is the last page.
+	Next string `json:"next"`
+	// Previous is the URL of the previous page, or empty if this is the first page.
+	Previous string `json:"previous"`
+	// Results holds the results of this page.
+	Results []T `json:"results"`
+}
+
+// HasNext returns true if there is a page after this one.
+func (p Page[T]) HasNext() bool {
+	return p.Next != ""
+}
+
+// HasPrevious returns true if there is a page before this one.
+func (p Page[T]) HasPrevious() bool {
+	return p.Previous != ""
+}
+
+// GetPage fetches a single page of results from the list endpoint at path, requesting at most
+// results items. Like the rest of the SDK, ErrAPIMismatch is returned alongside a valid page.
+func GetPage[T any](ctx context.Context, c *Client, path string, results int) (Page[T], error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(results))
+	u.RawQuery = query.Encode()
+	return fetchPage[T](ctx, c, u.RequestURI())
+}
+
+// NextPage fetches the page following p. It returns an empty page if p is the last page.
+func NextPage[T any](ctx context.Context, c *Client, p Page[T]) (Page[T], error) {
+	return followPage[T](ctx, c, p.Next)
+}
+
+// PreviousPage fetches the page before p. It returns an empty page if p is the first page.
+func PreviousPage[T any](ctx context.Context, c *Client, p Page[T]) (Page[T], error) {
+	return followPage[T](ctx, c, p.Previous)
+}
+
+// Paginate returns an iterator over every result of the list endpoint at path. Pages of
+// pageSize results are fetched lazily by following the next links returned by the controller,
+// so breaking out of the loop stops any further requests. A request error is yielded once
+// and ends the iteration; ErrAPIMismatch is ignored.
+func Paginate[T any](ctx context.Context, c *Client, path string, pageSize int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		page, err := GetPage[T](ctx, c, path, pageSize)
+		for {
+			if err != nil && !IsErrAPIMismatch(err) {
+				yield(zero, err)
+				return
+			}
+			for _, result := range page.Results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+			if !page.HasNext() {
+				return
+			}
+			page, err = NextPage(ctx, c, page)
+		}
+	}
+}
+
+// followPage fetches the page at link, which is an absolute URL returned by the controller.
+// Only the path and query are kept so that pages are always fetched from ControllerURL,
+// even if the controller is unaware of the address it is served under.
+func followPage[T any](ctx context.Context, c *Client, link string) (Page[T], error) {
+	if link == "" {
+		return Page[T]{}, nil
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return fetchPage[T](ctx, c, u.RequestURI())
+}
+
+func fetchPage[T any](ctx context.Context, c *Client, path string) (Page[T], error) {
+	res, reqErr := c.RequestWithContext(ctx, "GET", path, nil)
+	if reqErr != nil && !IsErrAPIMismatch(reqErr) {
+		return Page[T]{}, reqErr
+	}
+	defer res.Body.Close()
+
+	var page Page[T]
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return Page[T]{}, err
+	}
+	return page, reqErr
+}
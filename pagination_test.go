@@ -0,0 +1,154 @@
+package drycc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type fakePagedServer struct{}
+
+func (fakePagedServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	res.Header().Add("DRYCC_API_VERSION", APIVersion)
+
+	if req.URL.Path != "/paged/" || req.Method != "GET" {
+		fmt.Printf("Unrecongized URL %s\n", req.URL)
+		res.WriteHeader(http.StatusNotFound)
+		res.Write(nil)
+		return
+	}
+
+	query := req.URL.Query()
+	if query.Get("limit") != "2" || query.Get("kind") != "test" {
+		fmt.Printf("Unexpected query %s\n", req.URL.RawQuery)
+		res.WriteHeader(http.StatusInternalServerError)
+		res.Write(nil)
+		return
+	}
+
+	switch query.Get("offset") {
+	case "":
+		res.Write([]byte(`{"count": 5, "next": "http://replaced.com/paged/?kind=test&limit=2&offset=2", "previous": null,
+			"results": [{"test": "a"}, {"test": "b"}]}`))
+	case "2":
+		res.Write([]byte(`{"count": 5, "next": "http://replaced.com/paged/?kind=test&limit=2&offset=4",
+			"previous": "http://replaced.com/paged/?kind=test&limit=2", "results": [{"test": "c"}, {"test": "d"}]}`))
+	case "4":
+		res.Write([]byte(`{"count": 5, "next": null, "previous": "http://replaced.com/paged/?kind=test&limit=2&offset=2",
+			"results": [{"test": "e"}]}`))
+	default:
+		res.WriteHeader(http.StatusInternalServerError)
+		res.Write(nil)
+	}
+}
+
+type pagedResult struct {
+	Test string `json:"test"`
+}
+
+func TestGetPage(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(fakePagedServer{})
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := GetPage[pagedResult](context.Background(), drycc, "/paged/?kind=test", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Count != 5 {
+		t.Errorf("Expected %d, Got %d", 5, page.Count)
+	}
+	if page.HasPrevious() || !page.HasNext() {
+		t.Errorf("Expected only a next page, Got next %q previous %q", page.Next, page.Previous)
+	}
+
+	page, err = NextPage(context.Background(), drycc, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []pagedResult{{"c"}, {"d"}}
+	if !reflect.DeepEqual(expected, page.Results) {
+		t.Errorf("Expected %v, Got %v", expected, page.Results)
+	}
+
+	page, err = PreviousPage(context.Background(), drycc, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []pagedResult{{"a"}, {"b"}}
+	if !reflect.DeepEqual(expected, page.Results) {
+		t.Errorf("Expected %v, Got %v", expected, page.Results)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(fakePagedServer{})
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var actual []pagedResult
+	for result, err := range Paginate[pagedResult](context.Background(), drycc, "/paged/?kind=test", 2) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual = append(actual, result)
+	}
+
+	expected := []pagedResult{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, Got %v", expected, actual)
+	}
+
+	// Breaking early must not fetch further pages.
+	actual = nil
+	for result := range Paginate[pagedResult](context.Background(), drycc, "/paged/?kind=test", 2) {
+		actual = append(actual, result)
+		if len(actual) == 1 {
+			break
+		}
+	}
+	if len(actual) != 1 {
+		t.Errorf("Expected 1 result, Got %d", len(actual))
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(fakePagedServer{})
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var errs []error
+	for _, err := range Paginate[pagedResult](context.Background(), drycc, "/missing/", 2) {
+		errs = append(errs, err)
+	}
+
+	var notFound ErrNotFound
+	if len(errs) != 1 || !errors.As(errs[0], &notFound) {
+		t.Errorf("Expected a single ErrNotFound, Got %v", errs)
+	}
+}
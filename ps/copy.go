@@ -0,0 +1,305 @@
+package ps
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// ErrNoTar is returned by [CopyTo] and [CopyFrom] when tar isn't available in the container.
+var ErrNoTar = errors.New("tar is not available in the container")
+
+// CopyProgress reports the progress of a copy.
+type CopyProgress struct {
+	// Path is the slash-separated path of the file being copied, starting with the name
+	// of the copied file or directory.
+	Path string
+	// Bytes is the number of bytes of the file copied so far, out of Size.
+	Bytes int64
+	Size  int64
+	// Total is the number of bytes of all the files copied so far.
+	Total int64
+}
+
+// CopyOptions configures [CopyTo] and [CopyFrom].
+type CopyOptions struct {
+	// Progress, if not nil, is called as the content of the files is copied.
+	Progress func(CopyProgress)
+}
+
+// CopyTo copies a local file or directory into a pod, like kubectl cp. remotePath is the
+// path of the copy in the container, whose parent directory must exist. File modes are
+// preserved. The copy is done by tar in the container, over the exec websocket.
+func CopyTo(c *drycc.Client, appID, podID, localPath, remotePath string, opts CopyOptions) error {
+	return CopyToWithContext(context.Background(), c, appID, podID, localPath, remotePath, opts)
+}
+
+// CopyToWithContext is like [CopyTo] but stops the copy when ctx is done.
+func CopyToWithContext(ctx context.Context, c *drycc.Client, appID, podID, localPath, remotePath string, opts CopyOptions) error {
+	dir, base, err := splitRemotePath(remotePath)
+	if err != nil {
+		return err
+	}
+	s, err := startExec(ctx, c, appID, podID, api.Command{
+		Command: []string{"tar", "-xpf", "-", "-C", dir},
+		Stdin:   true,
+	}, false)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	stdin := &errWriter{w: s.Stdin}
+	buf := bufio.NewWriterSize(stdin, 32*1024)
+	writeErr := writeTar(buf, localPath, base, opts.Progress)
+	if writeErr == nil {
+		writeErr = buf.Flush()
+	}
+	if writeErr == nil {
+		writeErr = stdin.Close()
+	}
+	if writeErr != nil && stdin.err == nil {
+		// The archive couldn't be written, such as when localPath doesn't exist. tar would
+		// wait for the rest of it.
+		s.Close()
+		return writeErr
+	}
+
+	stderr, err := waitCopy(s)
+	if err != nil {
+		return copyErr(err, stderr)
+	}
+	return writeErr
+}
+
+// writeTar writes localPath as a tar archive, naming it base.
+func writeTar(w io.Writer, localPath, base string, progress func(CopyProgress)) error {
+	tw := tar.NewWriter(w)
+	var total int64
+	err := filepath.WalkDir(localPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localPath, p)
+		if err != nil {
+			return err
+		}
+		link := ""
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case !info.Mode().IsRegular() && !info.IsDir():
+			// Devices, sockets and pipes can't be copied.
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = path.Join(base, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(&progressWriter{w: tw, path: hdr.Name, size: hdr.Size, total: &total, progress: progress}, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// CopyFrom copies a file or directory out of a pod, like kubectl cp. localPath is the path
+// of the copy, whose parent directory must exist. File modes are preserved; symbolic
+// links and special files are skipped. The copy is done by tar in the container, over the
+// exec websocket.
+func CopyFrom(c *drycc.Client, appID, podID, remotePath, localPath string, opts CopyOptions) error {
+	return CopyFromWithContext(context.Background(), c, appID, podID, remotePath, localPath, opts)
+}
+
+// CopyFromWithContext is like [CopyFrom] but stops the copy when ctx is done.
+func CopyFromWithContext(ctx context.Context, c *drycc.Client, appID, podID, remotePath, localPath string, opts CopyOptions) error {
+	dir, base, err := splitRemotePath(remotePath)
+	if err != nil {
+		return err
+	}
+	s, err := startExec(ctx, c, appID, podID, api.Command{
+		Command: []string{"tar", "-cf", "-", "-C", dir, base},
+	}, false)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	readErr := readTar(s.Stdout, localPath, base, opts.Progress)
+	io.Copy(io.Discard, s.Stdout)
+	stderr, err := waitCopy(s)
+	if err != nil {
+		return copyErr(err, stderr)
+	}
+	return readErr
+}
+
+// readTar extracts a tar archive whose entries are named after base into localPath.
+func readTar(r io.Reader, localPath, base string, progress func(CopyProgress)) error {
+	tr := tar.NewReader(r)
+	type dirMode struct {
+		path string
+		mode fs.FileMode
+	}
+	var dirs []dirMode
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(hdr.Name, "/")
+		rel, ok := strings.CutPrefix(name, base)
+		if !ok || (rel != "" && !strings.HasPrefix(rel, "/")) {
+			return fmt.Errorf("unexpected path in archive: %s", hdr.Name)
+		}
+		rel = strings.TrimPrefix(rel, "/")
+		if rel != "" && !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return fmt.Errorf("unexpected path in archive: %s", hdr.Name)
+		}
+		target := filepath.Join(localPath, filepath.FromSlash(rel))
+		mode := hdr.FileInfo().Mode().Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirMode{target, mode})
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(&progressWriter{w: f, path: name, size: hdr.Size, total: &total, progress: progress}, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := os.Chmod(target, mode); err != nil {
+				return err
+			}
+		}
+	}
+	// Directory modes are set last, so read-only directories can be filled.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if err := os.Chmod(dirs[i].path, dirs[i].mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitRemotePath returns the parent directory and the name of a path in a container.
+func splitRemotePath(remotePath string) (string, string, error) {
+	remotePath = path.Clean(remotePath)
+	base := path.Base(remotePath)
+	if base == "." || base == ".." || base == "/" {
+		return "", "", fmt.Errorf("invalid remote path: %s", remotePath)
+	}
+	return path.Dir(remotePath), base, nil
+}
+
+// waitCopy waits for the tar command of a copy, returning its standard error.
+func waitCopy(s *ExecSession) (string, error) {
+	stderr, _ := io.ReadAll(s.Stderr)
+	return strings.TrimSpace(string(stderr)), s.Wait()
+}
+
+// copyErr returns the error of a failed tar command.
+func copyErr(err error, stderr string) error {
+	var exitErr *ExitError
+	isExit := errors.As(err, &exitErr)
+	if (isExit && exitErr.Code == 127) ||
+		(!isExit && strings.Contains(err.Error(), "tar") && strings.Contains(err.Error(), "not found")) {
+		return fmt.Errorf("%w: %v", ErrNoTar, err)
+	}
+	if stderr != "" {
+		return fmt.Errorf("%w: %s", err, stderr)
+	}
+	return err
+}
+
+// progressWriter reports the progress of the copy of a file.
+type progressWriter struct {
+	w        io.Writer
+	path     string
+	size     int64
+	written  int64
+	total    *int64
+	progress func(CopyProgress)
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	n, err := p.w.Write(data)
+	p.written += int64(n)
+	*p.total += int64(n)
+	if p.progress != nil {
+		p.progress(CopyProgress{Path: p.path, Bytes: p.written, Size: p.size, Total: *p.total})
+	}
+	return n, err
+}
+
+// errWriter records the error of the standard input of a session, to tell it from the
+// errors of the local files.
+type errWriter struct {
+	w   io.WriteCloser
+	err error
+}
+
+func (e *errWriter) Write(data []byte) (int, error) {
+	n, err := e.w.Write(data)
+	if err != nil {
+		e.err = err
+	}
+	return n, err
+}
+
+func (e *errWriter) Close() error {
+	if err := e.w.Close(); err != nil {
+		e.err = err
+	}
+	return e.err
+}
@@ -0,0 +1,215 @@
+package ps
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"golang.org/x/net/websocket"
+)
+
+type fakeFile struct {
+	mode fs.FileMode
+	data []byte
+}
+
+// fakeTar implements the tar commands run by CopyTo and CopyFrom on an in-memory file system.
+type fakeTar struct {
+	mu    sync.Mutex
+	files map[string]fakeFile
+}
+
+func (f *fakeTar) handle(conn *websocket.Conn, appID, pod string, command api.Command) {
+	e := drycctest.ExecStream{Conn: conn}
+	if pod == "example-go-notar" {
+		stdin := e.Stdin(nil)
+		e.Fail(`exec: "tar": executable file not found in $PATH`)
+		// Input sent before the client got the status is drained until it goes away.
+		io.Copy(io.Discard, stdin)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch command.Command[1] {
+	case "-cf":
+		root := path.Join(command.Command[4], command.Command[5])
+		var names []string
+		for name := range f.files {
+			if name == root || strings.HasPrefix(name, root+"/") {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			e.Stderr([]byte("tar: " + command.Command[5] + ": Cannot stat: No such file or directory\n"))
+			e.Exit(2)
+			return
+		}
+		slices.Sort(names)
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, name := range names {
+			file := f.files[name]
+			hdr := &tar.Header{
+				Name: strings.TrimPrefix(name, command.Command[4]+"/"),
+				Mode: int64(file.mode.Perm()),
+				Size: int64(len(file.data)),
+			}
+			if file.mode.IsDir() {
+				hdr.Typeflag, hdr.Name = tar.TypeDir, hdr.Name+"/"
+			}
+			tw.WriteHeader(hdr)
+			tw.Write(file.data)
+		}
+		tw.Close()
+		// Send the archive in several messages.
+		for chunk := range slices.Chunk(buf.Bytes(), 1000) {
+			e.Stdout(chunk)
+		}
+		e.Exit(0)
+	case "-xpf":
+		stdin := e.Stdin(nil)
+		tr := tar.NewReader(stdin)
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				break
+			}
+			data, _ := io.ReadAll(tr)
+			mode := hdr.FileInfo().Mode()
+			f.files[path.Join(command.Command[4], hdr.Name)] = fakeFile{mode: mode, data: data}
+		}
+		// The rest of the input is drained until the client closes it.
+		io.Copy(io.Discard, stdin)
+		e.Exit(0)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeTar{files: map[string]fakeFile{}}
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web"},
+		api.Pods{Name: "example-go-notar", Type: "web"},
+	)
+	srv.HandleExec(fake.handle)
+	client := srv.Client()
+
+	src := filepath.Join(t.TempDir(), "src")
+	for _, dir := range []string{"sub", "empty"} {
+		if err := os.MkdirAll(filepath.Join(src, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	big := bytes.Repeat([]byte("0123456789"), 5000)
+	files := map[string]fakeFile{
+		"a.txt":    {mode: 0o640, data: []byte("hello")},
+		"sub/b.sh": {mode: 0o755, data: big},
+	}
+	for name, file := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.WriteFile(p, file.data, file.mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, file.mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var last CopyProgress
+	err := CopyTo(client, "example-go", "example-go-web-1", src, "/tmp/fixtures", CopyOptions{
+		Progress: func(p CopyProgress) { last = p },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := CopyProgress{Path: "fixtures/sub/b.sh", Bytes: int64(len(big)), Size: int64(len(big)), Total: int64(len(big) + 5)}
+	if last != expected {
+		t.Errorf("Expected %v, Got %v", expected, last)
+	}
+	for name, file := range files {
+		copied := fake.files["/tmp/fixtures/"+name]
+		if copied.mode != file.mode || !bytes.Equal(copied.data, file.data) {
+			t.Errorf("Expected %s to be copied with mode %v, Got %v", name, file.mode, copied.mode)
+		}
+	}
+	if !fake.files["/tmp/fixtures/empty"].mode.IsDir() {
+		t.Error("Expected the empty directory to be copied")
+	}
+
+	dst := filepath.Join(t.TempDir(), "dst")
+	if err := CopyFrom(client, "example-go", "example-go-web-1", "/tmp/fixtures", dst, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, file := range files {
+		p := filepath.Join(dst, filepath.FromSlash(name))
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(p)
+		if info.Mode() != file.mode || !bytes.Equal(data, file.data) {
+			t.Errorf("Expected %s to be copied with mode %v, Got %v", name, file.mode, info.Mode())
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dst, "empty")); err != nil || !info.IsDir() {
+		t.Errorf("Expected the empty directory to be copied, Got %v", err)
+	}
+
+	single := filepath.Join(t.TempDir(), "a.txt")
+	if err := CopyFrom(client, "example-go", "example-go-web-1", "/tmp/fixtures/a.txt", single, CopyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(single); string(data) != "hello" {
+		t.Errorf("Expected %v, Got %v", "hello", string(data))
+	}
+
+	err = CopyFrom(client, "example-go", "example-go-web-1", "/tmp/missing", dst, CopyOptions{})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || !strings.Contains(err.Error(), "Cannot stat") {
+		t.Errorf("Expected the error of tar, Got %v", err)
+	}
+
+	if err := CopyFrom(client, "example-go", "example-go-notar", "/tmp/fixtures", dst, CopyOptions{}); !errors.Is(err, ErrNoTar) {
+		t.Errorf("Expected %v, Got %v", ErrNoTar, err)
+	}
+	if err := CopyTo(client, "example-go", "example-go-notar", src, "/tmp/fixtures", CopyOptions{}); !errors.Is(err, ErrNoTar) {
+		t.Errorf("Expected %v, Got %v", ErrNoTar, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = CopyToWithContext(ctx, client, "example-go", "example-go-web-1", filepath.Join(src, "missing"), "/tmp/missing", CopyOptions{})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the error of the local path, Got %v", err)
+	}
+	if err := CopyTo(client, "example-go", "example-go-web-1", src, "/", CopyOptions{}); err == nil {
+		t.Error("Expected an error copying to /")
+	}
+}
+
+func TestReadTarTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "dump/../../escape", Mode: 0o644, Typeflag: tar.TypeReg})
+	tw.Close()
+	if err := readTar(&buf, t.TempDir(), "dump", nil); err == nil {
+		t.Error("Expected an error for a path escaping the destination")
+	}
+}
@@ -0,0 +1,342 @@
+package ps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/websocket"
+)
+
+// The channels of the exec websocket. Every message of the connection opened by [Exec]
+// starts with one of these bytes, followed by the data of the channel:
+//
+//   - StdinChannel: data written to the standard input of the command.
+//   - StdoutChannel, StderrChannel: output of the command. TTY sessions only use
+//     StdoutChannel.
+//   - ErrorChannel: the exit status of the command, as a Kubernetes Status object,
+//     sent once before the controller closes the connection.
+//   - ResizeChannel: the size of the terminal, as a [TerminalSize] object.
+//   - CloseChannel: the end of the data of the channel that follows, such as StdinChannel
+//     to close the standard input of the command.
+const (
+	StdinChannel byte = iota
+	StdoutChannel
+	StderrChannel
+	ErrorChannel
+	ResizeChannel
+
+	CloseChannel byte = 255
+)
+
+// ErrNoTTY is returned when resizing the terminal of a session without TTY.
+var ErrNoTTY = errors.New("exec session has no TTY")
+
+// ExitError is returned by [ExecSession.Wait] when a command exits with a non-zero code.
+type ExitError struct {
+	// Code is the exit code of the command.
+	Code int
+	// Message is the message reported by the controller.
+	Message string
+	// Stderr holds the standard error of the command when it was run by [ExecOutput].
+	Stderr []byte
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+// TerminalSize is the size of the terminal of a TTY session.
+type TerminalSize struct {
+	Width  uint16 `json:"Width"`
+	Height uint16 `json:"Height"`
+}
+
+// execStatus is the exit status of a command, sent on ErrorChannel.
+type execStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+	Details struct {
+		Causes []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"causes"`
+	} `json:"details"`
+}
+
+// err returns the error matching the status, or nil if the command succeeded.
+func (s execStatus) err() error {
+	if s.Status == "Success" {
+		return nil
+	}
+	if s.Reason == "NonZeroExitCode" {
+		for _, cause := range s.Details.Causes {
+			if cause.Reason != "ExitCode" {
+				continue
+			}
+			if code, err := strconv.Atoi(cause.Message); err == nil {
+				return &ExitError{Code: code, Message: s.Message}
+			}
+		}
+	}
+	if s.Message == "" {
+		return fmt.Errorf("command failed: %s", s.Status)
+	}
+	return errors.New(s.Message)
+}
+
+// ExecSession is a command running in a pod, started with [StartExec].
+type ExecSession struct {
+	// Stdin writes to the standard input of the command, and closing it sends the end of
+	// the input, for commands reading it until then. It is nil unless the command was
+	// started with Stdin set.
+	Stdin io.WriteCloser
+	// Stdout reads the standard output of the command, and its terminal for TTY sessions.
+	// Output that isn't read is buffered, so it never blocks the session.
+	Stdout io.Reader
+	// Stderr reads the standard error of the command.
+	Stderr io.Reader
+
+	conn *websocket.Conn
+	ctx  context.Context
+	tty  bool
+	stop func() bool
+
+	stdout, stderr *pipe
+
+	done   chan struct{}
+	err    error
+	closed atomic.Bool
+}
+
+// StartExec starts a command in a pod, and returns the session to interact with it.
+func StartExec(c *drycc.Client, appID, podID string, command api.Command) (*ExecSession, error) {
+	return StartExecWithContext(context.Background(), c, appID, podID, command)
+}
+
+// StartExecWithContext is like [StartExec] but closes the session when ctx is done.
+// Unlike [ExecWithContext], ctx bounds the whole session and not only the handshake.
+func StartExecWithContext(ctx context.Context, c *drycc.Client, appID, podID string, command api.Command) (*ExecSession, error) {
+	return startExec(ctx, c, appID, podID, command, false)
+}
+
+// startExec starts a session. If combined is set, stdout and stderr are read from the
+// same pipe, in the order they were sent.
+func startExec(ctx context.Context, c *drycc.Client, appID, podID string, command api.Command, combined bool) (*ExecSession, error) {
+	conn, err := ExecWithContext(ctx, c, appID, podID, command)
+	if err != nil {
+		return nil, err
+	}
+	s := &ExecSession{conn: conn, ctx: ctx, tty: command.Tty, stdout: newPipe(), done: make(chan struct{})}
+	s.stderr = s.stdout
+	if !combined {
+		s.stderr = newPipe()
+	}
+	s.Stdout, s.Stderr = s.stdout, s.stderr
+	if command.Stdin {
+		s.Stdin = stdinWriter{s}
+	}
+	s.stop = context.AfterFunc(ctx, s.close)
+	go s.receive()
+	return s, nil
+}
+
+// receive reads the messages of the connection until it is closed.
+func (s *ExecSession) receive() {
+	var status error
+	var exited bool
+	var err error
+	for {
+		var msg []byte
+		if err = websocket.Message.Receive(s.conn, &msg); err != nil {
+			break
+		}
+		if len(msg) == 0 {
+			continue
+		}
+		switch msg[0] {
+		case StdoutChannel:
+			s.stdout.write(msg[1:])
+		case StderrChannel:
+			s.stderr.write(msg[1:])
+		case ErrorChannel:
+			var st execStatus
+			if err := json.Unmarshal(msg[1:], &st); err != nil {
+				status = fmt.Errorf("invalid exit status: %w", err)
+			} else {
+				status = st.err()
+			}
+			exited = true
+			// The status is the last message, the session is over.
+			err = io.EOF
+		}
+		if err != nil {
+			s.conn.Close()
+			break
+		}
+	}
+
+	switch {
+	case s.ctx.Err() != nil:
+		s.err = s.ctx.Err()
+	case s.closed.Load():
+		s.err = net.ErrClosed
+	case errors.Is(err, io.EOF) && exited:
+		s.err = status
+	case errors.Is(err, io.EOF):
+		// The connection was dropped before the command exited.
+		s.err = io.ErrUnexpectedEOF
+	default:
+		s.err = err
+	}
+	s.stdout.close(io.EOF)
+	s.stderr.close(io.EOF)
+	close(s.done)
+}
+
+// Resize sets the size of the terminal of a TTY session.
+func (s *ExecSession) Resize(size TerminalSize) error {
+	if !s.tty {
+		return ErrNoTTY
+	}
+	data, err := json.Marshal(size)
+	if err != nil {
+		return err
+	}
+	return websocket.Message.Send(s.conn, append([]byte{ResizeChannel}, data...))
+}
+
+// Wait waits for the command to exit. It returns an *ExitError if the command exited with
+// a non-zero code, or the context's error if the session's context is done first.
+func (s *ExecSession) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Close terminates the session. Pending Wait calls return net.ErrClosed.
+func (s *ExecSession) Close() error {
+	s.close()
+	s.stop()
+	return nil
+}
+
+func (s *ExecSession) close() {
+	if !s.closed.Swap(true) {
+		s.conn.Close()
+	}
+}
+
+// stdinWriter writes to the standard input of a session.
+type stdinWriter struct {
+	s *ExecSession
+}
+
+func (w stdinWriter) Write(p []byte) (int, error) {
+	if err := websocket.Message.Send(w.s.conn, append([]byte{StdinChannel}, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w stdinWriter) Close() error {
+	return websocket.Message.Send(w.s.conn, []byte{CloseChannel, StdinChannel})
+}
+
+// ExecOutput runs a non-interactive command in a pod and returns its standard output.
+// If the command exits with a non-zero code, the error is an *ExitError holding the
+// standard error of the command.
+func ExecOutput(c *drycc.Client, appID, podID string, command []string) ([]byte, error) {
+	return ExecOutputWithContext(context.Background(), c, appID, podID, command)
+}
+
+// ExecOutputWithContext is like [ExecOutput] but stops the command when ctx is done.
+func ExecOutputWithContext(ctx context.Context, c *drycc.Client, appID, podID string, command []string) ([]byte, error) {
+	s, err := startExec(ctx, c, appID, podID, api.Command{Command: command}, false)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	var stderr []byte
+	stderrDone := make(chan struct{})
+	go func() {
+		stderr, _ = io.ReadAll(s.Stderr)
+		close(stderrDone)
+	}()
+	stdout, _ := io.ReadAll(s.Stdout)
+	<-stderrDone
+	err = s.Wait()
+	if exitErr := (*ExitError)(nil); errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr
+	}
+	return stdout, err
+}
+
+// ExecCombinedOutput runs a non-interactive command in a pod and returns its standard
+// output and standard error, interleaved in the order they were written.
+func ExecCombinedOutput(c *drycc.Client, appID, podID string, command []string) ([]byte, error) {
+	return ExecCombinedOutputWithContext(context.Background(), c, appID, podID, command)
+}
+
+// ExecCombinedOutputWithContext is like [ExecCombinedOutput] but stops the command when
+// ctx is done.
+func ExecCombinedOutputWithContext(ctx context.Context, c *drycc.Client, appID, podID string, command []string) ([]byte, error) {
+	s, err := startExec(ctx, c, appID, podID, api.Command{Command: command}, true)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	output, _ := io.ReadAll(s.Stdout)
+	return output, s.Wait()
+}
+
+// pipe is an unbounded in-memory pipe, so output that isn't read never blocks a session.
+type pipe struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	err  error
+}
+
+func newPipe() *pipe {
+	p := &pipe{}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+func (p *pipe) Read(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.buf.Len() == 0 && p.err == nil {
+		p.cond.Wait()
+	}
+	if p.buf.Len() > 0 {
+		return p.buf.Read(b)
+	}
+	return 0, p.err
+}
+
+func (p *pipe) write(b []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.buf.Write(b)
+	p.cond.Broadcast()
+}
+
+func (p *pipe) close(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = err
+	}
+	p.cond.Broadcast()
+}
@@ -0,0 +1,201 @@
+package ps
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"golang.org/x/net/websocket"
+)
+
+// newExecServer returns a server running commands with a fake shell: "echo" writes its
+// arguments, "fail" writes them to stderr and exits with 3, "cat" copies its input and
+// "wc" counts the bytes of its input, "drop" goes away without an exit status and "wait"
+// blocks until the client goes away.
+func newExecServer(t *testing.T, resizes chan<- [2]uint16) *drycctest.Server {
+	srv := drycctest.NewServer()
+	t.Cleanup(srv.Close)
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go", api.Pods{Name: "example-go-web-1", Type: "web"})
+	srv.HandleExec(func(conn *websocket.Conn, appID, pod string, command api.Command) {
+		e := drycctest.ExecStream{Conn: conn}
+		args := strings.Join(command.Command[1:], " ")
+		switch command.Command[0] {
+		case "echo":
+			e.Stdout([]byte(args))
+			e.Stderr([]byte("warning"))
+			e.Stdout([]byte("\n"))
+			e.Exit(0)
+		case "fail":
+			e.Stderr([]byte(args))
+			e.Exit(3)
+		case "cat":
+			stdin := e.Stdin(func(width, height uint16) {
+				resizes <- [2]uint16{width, height}
+			})
+			line, _ := bufio.NewReader(stdin).ReadString('\n')
+			e.Stdout([]byte(line))
+			e.Exit(0)
+		case "wc":
+			n, _ := io.Copy(io.Discard, e.Stdin(nil))
+			e.Stdout([]byte(strconv.FormatInt(n, 10)))
+			e.Exit(0)
+		case "drop":
+			e.Stdout([]byte("partial"))
+		case "wait":
+			io.Copy(io.Discard, e.Stdin(nil))
+		}
+	})
+	return srv
+}
+
+func TestExecSession(t *testing.T) {
+	t.Parallel()
+
+	resizes := make(chan [2]uint16, 1)
+	srv := newExecServer(t, resizes)
+
+	s, err := StartExec(srv.Client(), "example-go", "example-go-web-1",
+		api.Command{Command: []string{"cat"}, Stdin: true, Tty: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Resize(TerminalSize{Width: 80, Height: 24}); err != nil {
+		t.Fatal(err)
+	}
+	if actual := <-resizes; actual != [2]uint16{80, 24} {
+		t.Errorf("Expected %v, Got %v", [2]uint16{80, 24}, actual)
+	}
+	if _, err := io.WriteString(s.Stdin, "hello\n"); err != nil {
+		t.Fatal(err)
+	}
+	output, err := io.ReadAll(s.Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hello\n" {
+		t.Errorf("Expected %q, Got %q", "hello\n", output)
+	}
+	if err := s.Wait(); err != nil {
+		t.Errorf("Expected no error, Got %v", err)
+	}
+
+	s, err = StartExec(srv.Client(), "example-go", "example-go-web-1", api.Command{Command: []string{"echo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Stdin != nil {
+		t.Error("Expected no stdin")
+	}
+	if err := s.Resize(TerminalSize{}); !errors.Is(err, ErrNoTTY) {
+		t.Errorf("Expected %v, Got %v", ErrNoTTY, err)
+	}
+	s.Wait()
+}
+
+func TestExecSessionStdin(t *testing.T) {
+	t.Parallel()
+
+	srv := newExecServer(t, nil)
+
+	s, err := StartExec(srv.Client(), "example-go", "example-go-web-1", api.Command{Command: []string{"wc"}, Stdin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := io.WriteString(s.Stdin, "hello world"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Stdin.Close(); err != nil {
+		t.Fatal(err)
+	}
+	output, _ := io.ReadAll(s.Stdout)
+	if string(output) != "11" {
+		t.Errorf("Expected %q, Got %q", "11", output)
+	}
+	if err := s.Wait(); err != nil {
+		t.Errorf("Expected no error, Got %v", err)
+	}
+
+	_, err = ExecOutput(srv.Client(), "example-go", "example-go-web-1", []string{"drop"})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected %v, Got %v", io.ErrUnexpectedEOF, err)
+	}
+}
+
+func TestExecSessionClose(t *testing.T) {
+	t.Parallel()
+
+	srv := newExecServer(t, nil)
+
+	s, err := StartExec(srv.Client(), "example-go", "example-go-web-1", api.Command{Command: []string{"wait"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if err := s.Wait(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Expected %v, Got %v", net.ErrClosed, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s, err = StartExecWithContext(ctx, srv.Client(), "example-go", "example-go-web-1", api.Command{Command: []string{"wait"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	cancel()
+	if err := s.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+}
+
+func TestExecOutput(t *testing.T) {
+	t.Parallel()
+
+	srv := newExecServer(t, nil)
+	client := srv.Client()
+
+	output, err := ExecOutput(client, "example-go", "example-go-web-1", []string{"echo", "hello", "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hello world\n" {
+		t.Errorf("Expected %q, Got %q", "hello world\n", output)
+	}
+
+	output, err = ExecCombinedOutput(client, "example-go", "example-go-web-1", []string{"echo", "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hellowarning\n" {
+		t.Errorf("Expected %q, Got %q", "hellowarning\n", output)
+	}
+
+	_, err = ExecOutput(client, "example-go", "example-go-web-1", []string{"fail", "no such file"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected an *ExitError, Got %v", err)
+	}
+	expected := &ExitError{
+		Code:    3,
+		Message: "command terminated with non-zero exit code: exit status 3",
+		Stderr:  []byte("no such file"),
+	}
+	if !reflect.DeepEqual(expected, exitErr) {
+		t.Errorf("Expected %v, Got %v", expected, exitErr)
+	}
+
+	if _, err := ExecOutput(client, "example-go", "missing", []string{"echo"}); err == nil {
+		t.Error("Expected an error for a missing pod")
+	}
+}
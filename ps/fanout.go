@@ -0,0 +1,176 @@
+package ps
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// DefaultExecConcurrency is the number of commands run at once by [ExecAll] when
+// ExecAllOptions.Concurrency isn't set.
+const DefaultExecConcurrency = 4
+
+// ExecAllOptions selects the pods [ExecAll] runs a command on.
+type ExecAllOptions struct {
+	// Ptype selects the pods of a process type. If empty, pods of every type are selected.
+	Ptype string
+	// Release selects the pods of a release, such as "v3" or "3". If empty, pods of
+	// every release are selected.
+	Release string
+	// Concurrency is the maximum number of commands running at once.
+	Concurrency int
+	// Stdout and Stderr, if not nil, receive the output of the commands as it is written,
+	// one line at a time prefixed with the name of the pod, such as "[example-go-web-1] ".
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ExecResult is the outcome of a command run on a pod by [ExecAll].
+type ExecResult struct {
+	Pod    string
+	Stdout []byte
+	Stderr []byte
+	// ExitCode is the exit code of the command, or -1 if it couldn't be run to completion.
+	ExitCode int
+	Duration time.Duration
+	// Err is the error running the command, other than a non-zero exit code.
+	Err error
+}
+
+// ExecAll runs a non-interactive command on every pod of an app selected by opts, and
+// returns the results in the order of the pods. The command failing on a pod doesn't
+// stop it running on the others, and its error is in the ExecResult of the pod rather
+// than returned. The returned error is that of listing the pods, in which case no command
+// is run.
+func ExecAll(c *drycc.Client, appID string, command []string, opts ExecAllOptions) ([]ExecResult, error) {
+	return ExecAllWithContext(context.Background(), c, appID, command, opts)
+}
+
+// ExecAllWithContext is like [ExecAll] but stops the commands when ctx is done.
+func ExecAllWithContext(ctx context.Context, c *drycc.Client, appID string, command []string, opts ExecAllOptions) ([]ExecResult, error) {
+	release := opts.Release
+	if release != "" && !strings.HasPrefix(release, "v") {
+		release = "v" + release
+	}
+	var pods []string
+	for pod, err := range AllWithContext(ctx, c, appID) {
+		if err != nil {
+			return nil, err
+		}
+		if (opts.Ptype == "" || pod.Type == opts.Ptype) && (release == "" || pod.Release == release) {
+			pods = append(pods, pod.Name)
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultExecConcurrency
+	}
+	var mu sync.Mutex // serializes the writes to opts.Stdout and opts.Stderr
+	results := make([]ExecResult, len(pods))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, pod := range pods {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = execOn(ctx, c, appID, pod, command, opts, &mu)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// execOn runs a command on a pod for ExecAll.
+func execOn(ctx context.Context, c *drycc.Client, appID, pod string, command []string, opts ExecAllOptions, mu *sync.Mutex) ExecResult {
+	result := ExecResult{Pod: pod, ExitCode: -1}
+	start := time.Now()
+
+	s, err := startExec(ctx, c, appID, pod, api.Command{Command: command}, false)
+	if err != nil {
+		result.Err = err
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer s.Close()
+
+	var stdout, stderr bytes.Buffer
+	stdoutPrefix := newPrefixWriter(opts.Stdout, pod, mu)
+	stderrPrefix := newPrefixWriter(opts.Stderr, pod, mu)
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.MultiWriter(&stderr, stderrPrefix), s.Stderr)
+		close(done)
+	}()
+	io.Copy(io.MultiWriter(&stdout, stdoutPrefix), s.Stdout)
+	<-done
+	stdoutPrefix.flush()
+	stderrPrefix.flush()
+
+	result.Stdout, result.Stderr = stdout.Bytes(), stderr.Bytes()
+	err = s.Wait()
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		result.ExitCode = 0
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.Code
+	default:
+		result.Err = err
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// prefixWriter writes complete lines prefixed with the name of a pod, so that the output
+// of several pods sharing a writer isn't interleaved within a line.
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, pod string, mu *sync.Mutex) *prefixWriter {
+	if w == nil {
+		w = io.Discard
+	}
+	return &prefixWriter{w: w, prefix: []byte("[" + pod + "] "), mu: mu}
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+// flush writes the last line if it doesn't end with a newline.
+func (p *prefixWriter) flush() {
+	if len(p.buf) > 0 {
+		p.writeLine(append(p.buf, '\n'))
+		p.buf = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Errors of the destination writer don't affect the command.
+	p.w.Write(append(p.prefix[:len(p.prefix):len(p.prefix)], line...))
+}
@@ -0,0 +1,108 @@
+package ps
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"golang.org/x/net/websocket"
+)
+
+// syncBuffer is a buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestExecAll(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web", Release: "v2"},
+		api.Pods{Name: "example-go-web-2", Type: "web", Release: "v2"},
+		api.Pods{Name: "example-go-web-3", Type: "web", Release: "v2"},
+		api.Pods{Name: "example-go-web-4", Type: "web", Release: "v1"},
+		api.Pods{Name: "example-go-worker-1", Type: "worker", Release: "v2"},
+	)
+	var running, maxRunning atomic.Int32
+	srv.HandleExec(func(conn *websocket.Conn, appID, pod string, command api.Command) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		e := drycctest.ExecStream{Conn: conn}
+		e.Stdout([]byte("total: 1\npartial"))
+		if pod == "example-go-web-2" {
+			e.Stderr([]byte("out of memory\n"))
+			e.Exit(137)
+			return
+		}
+		e.Exit(0)
+	})
+
+	var stdout, stderr syncBuffer
+	results, err := ExecAll(srv.Client(), "example-go", []string{"cat", "/proc/meminfo"}, ExecAllOptions{
+		Ptype:       "web",
+		Release:     "2",
+		Concurrency: 2,
+		Stdout:      &stdout,
+		Stderr:      &stderr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pods []string
+	for _, r := range results {
+		pods = append(pods, r.Pod)
+		if r.Err != nil || r.Duration <= 0 || string(r.Stdout) != "total: 1\npartial" {
+			t.Errorf("Expected the command to run on %s, Got %+v", r.Pod, r)
+		}
+	}
+	expected := []string{"example-go-web-1", "example-go-web-2", "example-go-web-3"}
+	if !reflect.DeepEqual(expected, pods) {
+		t.Fatalf("Expected %v, Got %v", expected, pods)
+	}
+	if results[1].ExitCode != 137 || string(results[1].Stderr) != "out of memory\n" || results[0].ExitCode != 0 {
+		t.Errorf("Expected exit codes 0 and 137, Got %d and %d", results[0].ExitCode, results[1].ExitCode)
+	}
+	if max := maxRunning.Load(); max != 2 {
+		t.Errorf("Expected %v, Got %v", 2, max)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout.buf.String(), "\n"), "\n")
+	slices.Sort(lines)
+	expectedLines := []string{
+		"[example-go-web-1] partial", "[example-go-web-1] total: 1",
+		"[example-go-web-2] partial", "[example-go-web-2] total: 1",
+		"[example-go-web-3] partial", "[example-go-web-3] total: 1",
+	}
+	if !reflect.DeepEqual(expectedLines, lines) {
+		t.Errorf("Expected %v, Got %v", expectedLines, lines)
+	}
+	if expected := "[example-go-web-2] out of memory\n"; stderr.buf.String() != expected {
+		t.Errorf("Expected %q, Got %q", expected, stderr.buf.String())
+	}
+}
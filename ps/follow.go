@@ -0,0 +1,337 @@
+package ps
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"golang.org/x/net/websocket"
+)
+
+// FollowOptions configures [FollowLogs]. The zero value follows every pod of the app.
+type FollowOptions struct {
+	// Ptype selects the pods of a process type. If empty, pods of every type are followed.
+	Ptype string
+	// Container selects the container of the pods. If empty, the default container is used.
+	Container string
+	// Lines is the number of lines fetched from the pods running when following starts.
+	Lines int
+	// History is the number of lines remembered per pod to skip the lines replayed when a
+	// stream reconnects. Pods appearing later start with this many lines. Defaults to 100.
+	History int
+	// PollInterval is the interval between the listings of the pods. Defaults to 10s.
+	PollInterval time.Duration
+	// MinBackoff and MaxBackoff bound the exponential delay between the reconnections of
+	// a stream. They default to 1s and 30s.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnError, if not nil, is called with the errors listing the pods, with an empty pod,
+	// and with the errors of the streams before they reconnect.
+	OnError func(pod string, err error)
+}
+
+func (o *FollowOptions) defaults() {
+	if o.History <= 0 {
+		o.History = 100
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 10 * time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = max(30*time.Second, o.MinBackoff)
+	}
+}
+
+// FollowLogs follows the logs of the pods of an app selected by opts, and merges their
+// lines into the returned channel, with the pod and the container set. Pods are listed
+// every PollInterval: streams are opened for the new pods and closed for the deleted ones.
+// Streams that end while their pod exists are reconnected with backoff, and the lines
+// they replay are skipped.
+//
+// The channel is closed once ctx is done. An error is only returned if the pods can't be
+// listed initially.
+func FollowLogs(ctx context.Context, c *drycc.Client, appID string, opts FollowOptions) (<-chan api.LogLine, error) {
+	opts.defaults()
+	pods, err := followedPods(ctx, c, appID, opts.Ptype)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(chan api.LogLine)
+	go func() {
+		var wg sync.WaitGroup
+		streams := map[string]context.CancelFunc{}
+		follow := func(pods []string, initial int) {
+			seen := map[string]bool{}
+			for _, pod := range pods {
+				seen[pod] = true
+				if _, ok := streams[pod]; ok {
+					continue
+				}
+				podCtx, cancel := context.WithCancel(ctx)
+				streams[pod] = cancel
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					f := &podFollower{c: c, appID: appID, pod: pod, opts: opts, lines: lines}
+					f.run(podCtx, initial)
+				}()
+			}
+			for pod, cancel := range streams {
+				if !seen[pod] {
+					cancel()
+					delete(streams, pod)
+				}
+			}
+		}
+
+		follow(pods, opts.Lines)
+		ticker := time.NewTicker(opts.PollInterval)
+		defer ticker.Stop()
+	poll:
+		for {
+			select {
+			case <-ctx.Done():
+				break poll
+			case <-ticker.C:
+			}
+			pods, err := followedPods(ctx, c, appID, opts.Ptype)
+			if err != nil {
+				if ctx.Err() == nil && opts.OnError != nil {
+					opts.OnError("", err)
+				}
+				continue
+			}
+			follow(pods, opts.History)
+		}
+		wg.Wait()
+		close(lines)
+	}()
+	return lines, nil
+}
+
+// followedPods lists the names of the pods of a process type.
+func followedPods(ctx context.Context, c *drycc.Client, appID, ptype string) ([]string, error) {
+	var pods []string
+	for pod, err := range AllWithContext(ctx, c, appID) {
+		if err != nil {
+			return nil, err
+		}
+		if ptype == "" || pod.Type == ptype {
+			pods = append(pods, pod.Name)
+		}
+	}
+	return pods, nil
+}
+
+// replayTimeout is how long the lines held by a [resync] wait for the rest of the replay.
+const replayTimeout = 500 * time.Millisecond
+
+// podFollower follows the logs of a pod, reconnecting its stream until its context is done.
+type podFollower struct {
+	c     *drycc.Client
+	appID string
+	pod   string
+	opts  FollowOptions
+	lines chan<- api.LogLine
+
+	// history holds the last lines sent, oldest first.
+	history []string
+}
+
+func (f *podFollower) run(ctx context.Context, lines int) {
+	backoff := f.opts.MinBackoff
+	for {
+		var r *resync
+		if len(f.history) > 0 {
+			r = newResync(f.history)
+			lines = len(f.history)
+		}
+		received, err := f.stream(ctx, lines, r)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil && f.opts.OnError != nil {
+			f.opts.OnError(f.pod, err)
+		}
+		if received {
+			backoff = f.opts.MinBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, f.opts.MaxBackoff)
+	}
+}
+
+// stream opens a stream and sends its lines until it ends. It returns whether any line
+// was received, and the error ending the stream, if any.
+func (f *podFollower) stream(ctx context.Context, lines int, r *resync) (bool, error) {
+	request := api.PodLogsRequest{Lines: lines, Follow: true, Container: f.opts.Container}
+	conn, err := LogsWithContext(ctx, f.c, f.appID, f.pod, request)
+	if err != nil {
+		return false, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	messages := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			var msg string
+			if err := websocket.Message.Receive(conn, &msg); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	received := false
+	for {
+		// The lines held by the resync are released once the replay is over: when the
+		// stream ends, or when no line follows them for a while.
+		var replayed <-chan time.Time
+		if r != nil && len(r.buffered) > 0 {
+			replayed = time.After(replayTimeout)
+		}
+		var msg string
+		select {
+		case msg = <-messages:
+		case <-replayed:
+			pending := r.flush()
+			r = nil
+			if !f.sendAll(ctx, pending) {
+				return true, ctx.Err()
+			}
+			continue
+		case err := <-errc:
+			if r != nil && ctx.Err() == nil {
+				f.sendAll(ctx, r.flush())
+			}
+			if received && ctx.Err() == nil {
+				// The stream ended after delivering lines; reconnect as soon as possible.
+				return true, nil
+			}
+			return received, err
+		}
+		received = true
+		for _, line := range strings.Split(strings.TrimSuffix(msg, "\n"), "\n") {
+			pending := []string{line}
+			if r != nil {
+				pending = r.push(line)
+				if r.done {
+					r = nil
+				}
+			}
+			if !f.sendAll(ctx, pending) {
+				return true, ctx.Err()
+			}
+		}
+	}
+}
+
+// sendAll sends lines of the pod, and reports whether they were all sent.
+func (f *podFollower) sendAll(ctx context.Context, lines []string) bool {
+	for _, line := range lines {
+		if !f.send(ctx, line) {
+			return false
+		}
+	}
+	return true
+}
+
+// send sends a line of the pod and remembers it.
+func (f *podFollower) send(ctx context.Context, line string) bool {
+	l := api.ParseLogLine(line)
+	if l.Pod != f.pod {
+		// The line isn't prefixed with the pod, so a prefix such as "INFO:" is part of the
+		// message.
+		l.Pod, l.Container, l.Message = f.pod, "", strings.TrimSuffix(line, "\r")
+		if !l.Timestamp.IsZero() {
+			_, l.Message, _ = strings.Cut(l.Message, " ")
+		}
+	}
+	if l.Container == "" {
+		l.Container = f.opts.Container
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case f.lines <- l:
+	}
+	if len(f.history) == f.opts.History {
+		f.history = f.history[1:]
+	}
+	f.history = append(f.history, line)
+	return true
+}
+
+// resync skips the lines replayed by a reconnected stream. Requesting as many lines as the
+// history holds, the stream starts with the n last lines of the history, followed by new
+// lines. n is the largest number for which the first lines of the stream match, so lines
+// are held until every larger candidate is ruled out.
+type resync struct {
+	history    []string
+	candidates []int // possible values of n, in descending order
+	buffered   []string
+	done       bool
+}
+
+func newResync(history []string) *resync {
+	r := &resync{history: slices.Clone(history)}
+	for n := len(history); n >= 0; n-- {
+		r.candidates = append(r.candidates, n)
+	}
+	return r
+}
+
+// push adds a line of the stream, and returns the lines that can be sent.
+func (r *resync) push(line string) []string {
+	if r.done {
+		return []string{line}
+	}
+	i := len(r.buffered)
+	r.buffered = append(r.buffered, line)
+	candidates := r.candidates[:0]
+	for _, n := range r.candidates {
+		if i >= n || r.history[len(r.history)-n+i] == line {
+			candidates = append(candidates, n)
+		}
+	}
+	r.candidates = candidates
+	if n := r.candidates[0]; n <= len(r.buffered) {
+		r.done = true
+		return r.buffered[n:]
+	}
+	return nil
+}
+
+// flush ends the resync when the replay is over, and returns the lines held that weren't
+// replayed.
+func (r *resync) flush() []string {
+	r.done = true
+	for _, n := range r.candidates {
+		if n <= len(r.buffered) {
+			return r.buffered[n:]
+		}
+	}
+	return r.buffered
+}
@@ -0,0 +1,197 @@
+package ps
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+	"golang.org/x/net/websocket"
+)
+
+func TestFollowLogs(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web"},
+		api.Pods{Name: "example-go-worker-1", Type: "worker"},
+	)
+
+	var mu sync.Mutex
+	logs := map[string][]string{
+		"example-go-web-1": {"2024-05-21T02:27:03Z 1", "INFO: 2"},
+		"example-go-web-2": {"a"},
+	}
+	connections := map[string]int{}
+	closed := make(chan string, 2)
+	srv.HandleLogs(func(conn *websocket.Conn, appID, pod string, request api.PodLogsRequest) {
+		mu.Lock()
+		connections[pod]++
+		n := connections[pod]
+		log := logs[pod]
+		log = log[max(0, len(log)-request.Lines):]
+		mu.Unlock()
+		for _, line := range log {
+			websocket.Message.Send(conn, line+"\n")
+		}
+
+		next := "3"
+		if n > 1 {
+			next = "4"
+		}
+		if pod == "example-go-web-1" && n <= 2 {
+			mu.Lock()
+			logs[pod] = append(logs[pod], next)
+			mu.Unlock()
+			websocket.Message.Send(conn, next+"\n")
+		}
+		if pod == "example-go-web-1" && n == 1 {
+			// The first stream drops, and should be reconnected.
+			return
+		}
+		var msg string
+		websocket.Message.Receive(conn, &msg)
+		closed <- pod
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines, err := FollowLogs(ctx, srv.Client(), "example-go", FollowOptions{
+		Ptype:        "web",
+		Container:    "web",
+		Lines:        10,
+		PollInterval: 10 * time.Millisecond,
+		MinBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next := func() api.LogLine {
+		t.Helper()
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for a line")
+			return api.LogLine{}
+		}
+	}
+
+	var messages []string
+	first := next()
+	if first.Timestamp != time.Date(2024, 5, 21, 2, 27, 3, 0, time.UTC) || first.Container != "web" {
+		t.Errorf("Expected a line with a timestamp, Got %v", first)
+	}
+	messages = append(messages, first.Message)
+	for range 3 {
+		line := next()
+		if line.Pod != "example-go-web-1" {
+			t.Errorf("Expected %v, Got %v", "example-go-web-1", line.Pod)
+		}
+		messages = append(messages, line.Message)
+	}
+	if expected := []string{"1", "INFO: 2", "3", "4"}; !reflect.DeepEqual(expected, messages) {
+		t.Errorf("Expected %v, Got %v", expected, messages)
+	}
+
+	srv.AddPods("example-go", api.Pods{Name: "example-go-web-2", Type: "web"})
+	if line := next(); line.Pod != "example-go-web-2" || line.Message != "a" {
+		t.Errorf("Expected the line of the new pod, Got %v", line)
+	}
+
+	srv.RemovePod("example-go", "example-go-web-1")
+	if pod := <-closed; pod != "example-go-web-1" {
+		t.Errorf("Expected %v, Got %v", "example-go-web-1", pod)
+	}
+
+	cancel()
+	for line := range lines {
+		t.Errorf("Expected no more lines, Got %v", line)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if connections["example-go-worker-1"] != 0 {
+		t.Error("Expected the worker pod not to be followed")
+	}
+}
+
+func TestResync(t *testing.T) {
+	tests := []struct {
+		history  []string
+		stream   []string
+		expected []string
+	}{
+		// The stream replays the whole history.
+		{[]string{"a", "b", "c"}, []string{"a", "b", "c", "d"}, []string{"d"}},
+		// Lines were written while disconnected.
+		{[]string{"a", "b", "c"}, []string{"b", "c", "d", "e"}, []string{"d", "e"}},
+		// More lines than the history were written, nothing is replayed.
+		{[]string{"a", "b"}, []string{"x", "y", "z"}, []string{"x", "y", "z"}},
+		// Repeated lines are matched with the longest overlap.
+		{[]string{"a", "a"}, []string{"a", "a", "b"}, []string{"b"}},
+		{[]string{"x", "a"}, []string{"a", "b"}, []string{"b"}},
+		// The replay is shorter than the history, its lines are released when it's over.
+		{[]string{"a", "a", "a"}, []string{"a", "a"}, nil},
+		{[]string{"a", "a", "b", "a"}, []string{"a", "a"}, []string{"a"}},
+	}
+	for _, test := range tests {
+		r := newResync(test.history)
+		var actual []string
+		for _, line := range test.stream {
+			actual = append(actual, r.push(line)...)
+		}
+		if !r.done {
+			actual = append(actual, r.flush()...)
+		}
+		if !reflect.DeepEqual(test.expected, actual) {
+			t.Errorf("Expected %v, Got %v", test.expected, actual)
+		}
+	}
+}
+
+func TestFollowReplay(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPods("example-go", api.Pods{Name: "example-go-web-1", Type: "web"})
+	srv.HandleLogs(func(conn *websocket.Conn, appID, pod string, request api.PodLogsRequest) {
+		// Two lines are replayed, and one of them is new.
+		websocket.Message.Send(conn, "a\na\n")
+		if request.Container == "open" {
+			var msg string
+			websocket.Message.Receive(conn, &msg)
+		}
+	})
+
+	for _, container := range []string{"open", "closed"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		lines := make(chan api.LogLine, 10)
+		f := &podFollower{
+			c:       srv.Client(),
+			appID:   "example-go",
+			pod:     "example-go-web-1",
+			opts:    FollowOptions{Container: container, History: 100},
+			lines:   lines,
+			history: []string{"a", "a", "b", "a"},
+		}
+		go f.stream(ctx, len(f.history), newResync(f.history))
+		select {
+		case line := <-lines:
+			if line.Message != "a" {
+				t.Errorf("%s: Expected %q, Got %q", container, "a", line.Message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("%s: Expected the new line to be released", container)
+		}
+		cancel()
+	}
+}
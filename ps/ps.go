@@ -2,10 +2,10 @@
 package ps
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"net/url"
+	"iter"
 	"sort"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -15,8 +15,13 @@ import (
 
 // List lists an app's processes.
 func List(c *drycc.Client, appID string, results int) (api.PodsList, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.PodsList, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/pods/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Pods{}, -1, reqErr
 	}
@@ -29,55 +34,49 @@ func List(c *drycc.Client, appID string, results int) (api.PodsList, int, error)
 	return procs, count, reqErr
 }
 
+// All returns an iterator over all processes of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Pods, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Pods, error] {
+	return drycc.Paginate[api.Pods](ctx, c, fmt.Sprintf("/v2/apps/%s/pods/", appID), drycc.DefaultPageSize)
+}
+
 // Exec a command in a container.
 func Exec(c *drycc.Client, appID, podID string, command api.Command) (*websocket.Conn, error) {
-	scheme := "ws"
-	if c.ControllerURL.Scheme == "https" {
-		scheme = "wss"
-	}
+	return ExecWithContext(context.Background(), c, appID, podID, command)
+}
+
+// ExecWithContext is like [Exec] but cancels the websocket handshake when ctx is done.
+func ExecWithContext(ctx context.Context, c *drycc.Client, appID, podID string, command api.Command) (*websocket.Conn, error) {
 	path := fmt.Sprintf("v2/apps/%s/pods/%s/exec/", appID, podID)
-	u := url.URL{Scheme: scheme, Host: c.ControllerURL.Host, Path: path}
-	config, err := websocket.NewConfig(u.String(), c.ControllerURL.String())
+	conn, err := c.DialWebsocket(ctx, path)
 	if err != nil {
 		return nil, err
 	}
-	config.Header = http.Header{
-		"User-Agent":          {c.UserAgent},
-		"Authorization":       {"token " + c.Token},
-		"X-Drycc-Service-Key": {c.ServiceKey},
-	}
-	conn, err := websocket.DialConfig(config)
-	if err != nil {
+	if err = websocket.JSON.Send(conn, command); err != nil {
+		conn.Close()
 		return nil, err
 	}
-	websocket.JSON.Send(conn, command)
 	return conn, nil
 }
 
 // Logs retrieves logs from an pod. The number of log lines fetched can be set by the lines
 func Logs(c *drycc.Client, appID, podID string, request api.PodLogsRequest) (*websocket.Conn, error) {
-	scheme := "ws"
-	if c.ControllerURL.Scheme == "https" {
-		scheme = "wss"
-	}
-	path := fmt.Sprintf("v2/apps/%s/pods/%s/logs/", appID, podID)
-	endpoint := url.URL{Scheme: scheme, Host: c.ControllerURL.Host, Path: path}
+	return LogsWithContext(context.Background(), c, appID, podID, request)
+}
 
-	config, err := websocket.NewConfig(endpoint.String(), c.ControllerURL.String())
-	if err != nil {
-		return nil, err
-	}
-	config.Header = http.Header{
-		"User-Agent":          {c.UserAgent},
-		"Authorization":       {"token " + c.Token},
-		"X-Drycc-Service-Key": {c.ServiceKey},
-	}
-	conn, err := websocket.DialConfig(config)
+// LogsWithContext is like [Logs] but cancels the websocket handshake when ctx is done.
+func LogsWithContext(ctx context.Context, c *drycc.Client, appID, podID string, request api.PodLogsRequest) (*websocket.Conn, error) {
+	path := fmt.Sprintf("v2/apps/%s/pods/%s/logs/", appID, podID)
+	conn, err := c.DialWebsocket(ctx, path)
 	if err != nil {
 		return nil, err
 	}
-	err = websocket.JSON.Send(conn, request)
-	if err != nil {
+	if err = websocket.JSON.Send(conn, request); err != nil {
+		conn.Close()
 		return nil, err
 	}
 	return conn, nil
@@ -85,9 +84,14 @@ func Logs(c *drycc.Client, appID, podID string, request api.PodLogsRequest) (*we
 
 // Describe pod state
 func Describe(c *drycc.Client, appID string, podID string, results int) (api.PodState, int, error) {
+	return DescribeWithContext(context.Background(), c, appID, podID, results)
+}
+
+// DescribeWithContext is like [Describe] but cancels the request when ctx is done.
+func DescribeWithContext(ctx context.Context, c *drycc.Client, appID string, podID string, results int) (api.PodState, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/pods/%s/describe/", appID, podID)
 
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.PodState{}, -1, reqErr
 	}
@@ -101,6 +105,11 @@ func Describe(c *drycc.Client, appID string, podID string, results int) (api.Pod
 
 // Delete deletes a pod from an app.
 func Delete(c *drycc.Client, appID string, podIDs string) error {
+	return DeleteWithContext(context.Background(), c, appID, podIDs)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, podIDs string) error {
 	u := fmt.Sprintf("/v2/apps/%s/pods/", appID)
 
 	req := api.PodIDs{PodIDs: podIDs}
@@ -109,7 +118,7 @@ func Delete(c *drycc.Client, appID string, podIDs string) error {
 	if err != nil {
 		return err
 	}
-	res, err := c.Request("DELETE", u, body)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, body)
 	if err == nil {
 		res.Body.Close()
 	}
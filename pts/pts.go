@@ -2,8 +2,10 @@
 package pts
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"sort"
 
 	drycc "github.com/drycc/controller-sdk-go"
@@ -12,8 +14,13 @@ import (
 
 // List lists an app's processes.
 func List(c *drycc.Client, appID string, results int) (api.Ptypes, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Ptypes, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/ptypes/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Ptype{}, -1, reqErr
 	}
@@ -26,11 +33,26 @@ func List(c *drycc.Client, appID string, results int) (api.Ptypes, int, error) {
 	return ptypes, count, reqErr
 }
 
+// All returns an iterator over all ptypes of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Ptype, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Ptype, error] {
+	return drycc.Paginate[api.Ptype](ctx, c, fmt.Sprintf("/v2/apps/%s/ptypes/", appID), drycc.DefaultPageSize)
+}
+
 // Describe Ptype state
 func Describe(c *drycc.Client, appID string, ptype string, results int) (api.PtypeStates, int, error) {
+	return DescribeWithContext(context.Background(), c, appID, ptype, results)
+}
+
+// DescribeWithContext is like [Describe] but cancels the request when ctx is done.
+func DescribeWithContext(ctx context.Context, c *drycc.Client, appID string, ptype string, results int) (api.PtypeStates, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/ptypes/%s-%s/describe/", appID, appID, ptype)
 
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.PtypeStates{}, -1, reqErr
 	}
@@ -45,6 +67,11 @@ func Describe(c *drycc.Client, appID string, ptype string, results int) (api.Pty
 // Scale increases or decreases an app's processes. The processes are specified in the target argument,
 // a key-value map, where the key is the process name and the value is the number of replicas
 func Scale(c *drycc.Client, appID string, targets map[string]int) error {
+	return ScaleWithContext(context.Background(), c, appID, targets)
+}
+
+// ScaleWithContext is like [Scale] but cancels the request when ctx is done.
+func ScaleWithContext(ctx context.Context, c *drycc.Client, appID string, targets map[string]int) error {
 	u := fmt.Sprintf("/v2/apps/%s/ptypes/scale/", appID)
 
 	body, err := json.Marshal(targets)
@@ -52,7 +79,7 @@ func Scale(c *drycc.Client, appID string, targets map[string]int) error {
 		return err
 	}
 
-	res, err := c.Request("POST", u, body)
+	res, err := c.RequestWithContext(ctx, "POST", u, body)
 	if err != nil && !drycc.IsErrAPIMismatch(err) {
 		return err
 	}
@@ -64,12 +91,17 @@ func Scale(c *drycc.Client, appID string, targets map[string]int) error {
 // procType and name. To restart an specific process, pass an procType by leave name empty.
 // To restart a specific instance, pass a procType and a name.
 func Restart(c *drycc.Client, appID string, targets map[string]string) error {
+	return RestartWithContext(context.Background(), c, appID, targets)
+}
+
+// RestartWithContext is like [Restart] but cancels the request when ctx is done.
+func RestartWithContext(ctx context.Context, c *drycc.Client, appID string, targets map[string]string) error {
 	u := fmt.Sprintf("/v2/apps/%s/ptypes/restart/", appID)
 	body, err := json.Marshal(targets)
 	if err != nil {
 		return err
 	}
-	res, err := c.Request("POST", u, body)
+	res, err := c.RequestWithContext(ctx, "POST", u, body)
 	if err != nil && !drycc.IsErrAPIMismatch(err) {
 		return err
 	}
@@ -79,12 +111,17 @@ func Restart(c *drycc.Client, appID string, targets map[string]string) error {
 
 // Clean clean an app's processes.
 func Clean(c *drycc.Client, appID string, targets map[string]string) error {
+	return CleanWithContext(context.Background(), c, appID, targets)
+}
+
+// CleanWithContext is like [Clean] but cancels the request when ctx is done.
+func CleanWithContext(ctx context.Context, c *drycc.Client, appID string, targets map[string]string) error {
 	u := fmt.Sprintf("/v2/apps/%s/ptypes/clean/", appID)
 	body, err := json.Marshal(targets)
 	if err != nil {
 		return err
 	}
-	res, err := c.Request("POST", u, body)
+	res, err := c.RequestWithContext(ctx, "POST", u, body)
 	if err != nil && !drycc.IsErrAPIMismatch(err) {
 		return err
 	}
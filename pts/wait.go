@@ -0,0 +1,340 @@
+package pts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/events"
+	"github.com/drycc/controller-sdk-go/ps"
+)
+
+// diagnosticTimeout bounds the requests gathering the diagnostic of a [NotReadyError],
+// which are made after the context of the wait is done.
+const diagnosticTimeout = 10 * time.Second
+
+// WaitOptions configures [WaitReady].
+type WaitOptions struct {
+	// Interval is the interval between the polls of the ptypes and pods. Defaults to 2s.
+	Interval time.Duration
+	// Timeout, if positive, bounds the wait in addition to the context.
+	Timeout time.Duration
+	// Progress, if not nil, is called with the status of the ptypes after each poll.
+	Progress func([]PtypeStatus)
+	// Events is the number of events fetched per ptype for the diagnostic of a
+	// [NotReadyError]. Defaults to 10.
+	Events int
+}
+
+func (o *WaitOptions) defaults() {
+	if o.Interval <= 0 {
+		o.Interval = 2 * time.Second
+	}
+	if o.Events <= 0 {
+		o.Events = 10
+	}
+}
+
+// PtypeStatus is the rollout status of a ptype observed by [WaitReady].
+type PtypeStatus struct {
+	Name string
+	// Found is false if the ptype isn't listed yet.
+	Found bool
+	// Desired is the number of replicas of the ptype.
+	Desired   int
+	UpToDate  int
+	Available int
+	// Pods is the number of pods of the ptype, of which ReadyPods have all their
+	// containers ready.
+	Pods      int
+	ReadyPods int
+}
+
+// Ready reports whether every replica of the ptype is up to date and available, and
+// exactly the desired number of pods run and are ready.
+func (s PtypeStatus) Ready() bool {
+	return s.Found && s.UpToDate >= s.Desired && s.Available >= s.Desired &&
+		s.Pods == s.Desired && s.ReadyPods == s.Desired
+}
+
+func (s PtypeStatus) String() string {
+	if !s.Found {
+		return s.Name + " not found"
+	}
+	return fmt.Sprintf("%s %d/%d ready, %d up-to-date, %d available, %d pods",
+		s.Name, s.ReadyPods, s.Desired, s.UpToDate, s.Available, s.Pods)
+}
+
+// CrashingContainer is a container of a pod that isn't ready and keeps restarting.
+type CrashingContainer struct {
+	Pod       string
+	Ptype     string
+	Container string
+	// Reason and Message describe why the container is waiting, such as CrashLoopBackOff,
+	// or why its last run terminated.
+	Reason       string
+	Message      string
+	RestartCount int
+}
+
+// NotReadyError is returned by [WaitReady] when the ptypes aren't ready before the
+// deadline. The diagnostic is gathered on a best-effort basis: pods or events that can't
+// be fetched are left out.
+type NotReadyError struct {
+	// Ptypes is the last status of the ptypes waited for.
+	Ptypes []PtypeStatus
+	// Crashing lists the crashlooping containers of the ptypes that aren't ready.
+	Crashing []CrashingContainer
+	// Events holds the recent events of the ptypes that aren't ready, by ptype.
+	Events map[string]api.AppEvents
+	// Err is the error of the context, usually context.DeadlineExceeded.
+	Err error
+}
+
+func (e *NotReadyError) Error() string {
+	var b strings.Builder
+	var pending []string
+	for _, s := range e.Ptypes {
+		if !s.Ready() {
+			pending = append(pending, s.String())
+		}
+	}
+	fmt.Fprintf(&b, "ptypes not ready: %s: %v", strings.Join(pending, "; "), e.Err)
+	if len(e.Crashing) > 0 {
+		b.WriteString("\ncrashlooping pods:")
+		for _, c := range e.Crashing {
+			fmt.Fprintf(&b, "\n  %s (%s): %s, %d restarts", c.Pod, c.Container, c.Reason, c.RestartCount)
+			if c.Message != "" {
+				fmt.Fprintf(&b, ": %s", c.Message)
+			}
+		}
+	}
+	if len(e.Events) > 0 {
+		b.WriteString("\nrecent events:")
+		for _, s := range e.Ptypes {
+			for _, event := range e.Events[s.Name] {
+				fmt.Fprintf(&b, "\n  %s %s %s: %s", event.Created, s.Name, event.Reason, event.Message)
+			}
+		}
+	}
+	return b.String()
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return e.Err
+}
+
+// WaitReady waits until the given ptypes of an app are rolled out: every replica is up to
+// date and available, and the ptype runs exactly the desired number of pods, all ready.
+// If ptypes is empty, every ptype of the app is waited for. The ptypes and pods are polled
+// every Interval.
+//
+// Polls failing with a transient error, such as a server or a network error, are retried
+// until the deadline; other errors, such as a missing app, an API mismatch or an invalid
+// response, are returned right away.
+//
+// If ctx is done or the timeout expires first, WaitReady returns a [*NotReadyError]
+// describing the ptypes that aren't ready, with their crashlooping pods and recent events,
+// unless ctx was canceled, in which case ctx.Err() is returned.
+func WaitReady(ctx context.Context, c *drycc.Client, appID string, ptypes []string, opts WaitOptions) error {
+	opts.defaults()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	var statuses []PtypeStatus
+	var pods api.PodsList
+	for {
+		polled, polledPods, err := pollStatus(ctx, c, appID, ptypes)
+		if err != nil && ctx.Err() == nil && !transient(err) {
+			return err
+		}
+		if err == nil {
+			statuses, pods = polled, polledPods
+			if opts.Progress != nil {
+				opts.Progress(statuses)
+			}
+			if !slices.ContainsFunc(statuses, func(s PtypeStatus) bool { return !s.Ready() }) {
+				return nil
+			}
+		}
+		select {
+		case <-ticker.C:
+			continue
+		case <-ctx.Done():
+		}
+
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return ctx.Err()
+		}
+		if statuses == nil {
+			// The deadline expired before a poll completed.
+			for _, name := range ptypes {
+				statuses = append(statuses, PtypeStatus{Name: name})
+			}
+		}
+		return diagnose(ctx, c, appID, statuses, pods, opts.Events)
+	}
+}
+
+// transient reports whether a poll failing with err may succeed later: network errors, a
+// response cut short, and server errors or rate limiting of the controller.
+func transient(err error) bool {
+	var apiErr *drycc.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	// *url.Error is a net.Error too, so only the errors of the connections are transient,
+	// and not those of the URL or of the certificates.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// pollStatus returns the status of the given ptypes, or of every ptype if ptypes is
+// empty, and the pods of the app.
+func pollStatus(ctx context.Context, c *drycc.Client, appID string, ptypes []string) ([]PtypeStatus, api.PodsList, error) {
+	var statuses []PtypeStatus
+	index := map[string]int{}
+	for _, name := range ptypes {
+		index[name] = len(statuses)
+		statuses = append(statuses, PtypeStatus{Name: name})
+	}
+	for p, err := range AllWithContext(ctx, c, appID) {
+		if err != nil {
+			return nil, nil, err
+		}
+		i, ok := index[p.Name]
+		if !ok {
+			if len(ptypes) > 0 {
+				continue
+			}
+			i = len(statuses)
+			index[p.Name] = i
+			statuses = append(statuses, PtypeStatus{Name: p.Name})
+		}
+		s := &statuses[i]
+		s.Found = true
+		s.UpToDate, s.Available = p.UpToDate, p.AvailableReplicas
+		if _, desired, ok := parseReady(p.Ready); ok {
+			s.Desired = desired
+		} else {
+			s.Desired = p.UpToDate
+		}
+	}
+
+	var pods api.PodsList
+	for pod, err := range ps.AllWithContext(ctx, c, appID) {
+		if err != nil {
+			return nil, nil, err
+		}
+		i, ok := index[pod.Type]
+		if !ok {
+			continue
+		}
+		pods = append(pods, pod)
+		statuses[i].Pods++
+		if ready, total, ok := parseReady(pod.Ready); ok && total > 0 && ready == total {
+			statuses[i].ReadyPods++
+		}
+	}
+	return statuses, pods, nil
+}
+
+// parseReady parses a readiness such as "1/2".
+func parseReady(s string) (int, int, bool) {
+	ready, total, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	n, err1 := strconv.Atoi(ready)
+	m, err2 := strconv.Atoi(total)
+	return n, m, err1 == nil && err2 == nil
+}
+
+// diagnose returns the error of ptypes that aren't ready once ctx is done.
+func diagnose(ctx context.Context, c *drycc.Client, appID string, statuses []PtypeStatus, pods api.PodsList, results int) error {
+	notReady := &NotReadyError{Ptypes: statuses, Err: ctx.Err()}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), diagnosticTimeout)
+	defer cancel()
+
+	pending := map[string]bool{}
+	for _, s := range statuses {
+		if !s.Ready() {
+			pending[s.Name] = true
+		}
+	}
+	for _, pod := range pods {
+		if !pending[pod.Type] {
+			continue
+		}
+		if ready, total, ok := parseReady(pod.Ready); ok && total > 0 && ready == total {
+			continue
+		}
+		state, _, err := ps.DescribeWithContext(ctx, c, appID, pod.Name, 100)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			continue
+		}
+		for _, container := range state {
+			if crashing, ok := crashingContainer(container); ok {
+				crashing.Pod, crashing.Ptype = pod.Name, pod.Type
+				notReady.Crashing = append(notReady.Crashing, crashing)
+			}
+		}
+	}
+
+	for _, s := range statuses {
+		if !pending[s.Name] || !s.Found {
+			continue
+		}
+		recent, _, err := events.ListPtypeEventsWithContext(ctx, c, appID, s.Name, results)
+		if (err != nil && !drycc.IsErrAPIMismatch(err)) || len(recent) == 0 {
+			continue
+		}
+		if notReady.Events == nil {
+			notReady.Events = map[string]api.AppEvents{}
+		}
+		notReady.Events[s.Name] = recent
+	}
+	return notReady
+}
+
+// crashingContainer reports whether a container is crashlooping: it isn't ready, and is
+// either backing off or has restarted.
+func crashingContainer(state api.ContainerState) (CrashingContainer, bool) {
+	if state.Ready {
+		return CrashingContainer{}, false
+	}
+	reason, _ := state.State["waiting"]["reason"].(string)
+	if reason != "CrashLoopBackOff" && state.RestartCount == 0 {
+		return CrashingContainer{}, false
+	}
+	message, _ := state.State["waiting"]["message"].(string)
+	if reason == "" {
+		// The container is running again; report why its last run ended.
+		reason, _ = state.LastState["terminated"]["reason"].(string)
+		message, _ = state.LastState["terminated"]["message"].(string)
+	}
+	return CrashingContainer{
+		Container:    state.Container,
+		Reason:       reason,
+		Message:      message,
+		RestartCount: state.RestartCount,
+	}, true
+}
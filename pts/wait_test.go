@@ -0,0 +1,164 @@
+package pts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func TestWaitReady(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPtypes("example-go",
+		api.Ptype{Name: "web", Ready: "1/2", UpToDate: 1, AvailableReplicas: 1},
+		api.Ptype{Name: "worker", Ready: "0/1"},
+	)
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web", Ready: "1/1"},
+		api.Pods{Name: "example-go-web-old", Type: "web", Ready: "1/1"},
+		api.Pods{Name: "example-go-web-2", Type: "web", Ready: "0/1"},
+	)
+
+	// Each poll moves the rollout of web forward.
+	var updates []PtypeStatus
+	progress := func(statuses []PtypeStatus) {
+		updates = append(updates, statuses[0])
+		switch len(updates) {
+		case 1:
+			srv.UpdatePtype("example-go", "web", func(p *api.Ptype) {
+				p.UpToDate, p.AvailableReplicas = 2, 2
+			})
+			srv.UpdatePod("example-go", "example-go-web-2", func(p *api.Pods) { p.Ready = "1/1" })
+		case 2:
+			srv.RemovePod("example-go", "example-go-web-old")
+		}
+	}
+	err := WaitReady(context.Background(), srv.Client(), "example-go", []string{"web"}, WaitOptions{
+		Interval: time.Millisecond,
+		Progress: progress,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []PtypeStatus{
+		{Name: "web", Found: true, Desired: 2, UpToDate: 1, Available: 1, Pods: 3, ReadyPods: 2},
+		{Name: "web", Found: true, Desired: 2, UpToDate: 2, Available: 2, Pods: 3, ReadyPods: 3},
+		{Name: "web", Found: true, Desired: 2, UpToDate: 2, Available: 2, Pods: 2, ReadyPods: 2},
+	}
+	if len(updates) != len(expected) {
+		t.Fatalf("Expected %v, Got %v", expected, updates)
+	}
+	for i := range expected {
+		if updates[i] != expected[i] {
+			t.Errorf("Expected %v, Got %v", expected[i], updates[i])
+		}
+	}
+
+	// Server errors are retried, client errors aren't.
+	srv.Fail(drycctest.Failure{Method: "GET", Path: "/v2/apps/example-go/pods/", StatusCode: http.StatusServiceUnavailable, Times: 2})
+	err = WaitReady(context.Background(), srv.Client(), "example-go", []string{"web"}, WaitOptions{Interval: time.Millisecond})
+	if err != nil {
+		t.Errorf("Expected the server errors to be retried, Got %v", err)
+	}
+	err = WaitReady(context.Background(), srv.Client(), "missing", nil, WaitOptions{Interval: time.Millisecond, Timeout: time.Minute})
+	if !errors.As(err, new(drycc.ErrNotFound)) {
+		t.Errorf("Expected %v, Got %v", drycc.ErrNotFound{}, err)
+	}
+	// An invalid response isn't retried.
+	srv.Fail(drycctest.Failure{Method: "GET", Path: "/v2/apps/example-go/ptypes/", StatusCode: http.StatusOK, Body: "<html>"})
+	err = WaitReady(context.Background(), srv.Client(), "example-go", nil, WaitOptions{Interval: time.Millisecond, Timeout: time.Minute})
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected the error decoding the response, Got %v", err)
+	}
+	srv.ClearFailures()
+
+	// Network errors are retried until the deadline.
+	down := drycctest.NewServer()
+	client := down.Client()
+	down.Close()
+	err = WaitReady(context.Background(), client, "example-go", nil, WaitOptions{Interval: time.Millisecond, Timeout: 50 * time.Millisecond})
+	var notReady *NotReadyError
+	if !errors.As(err, &notReady) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a %T, Got %v", notReady, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = WaitReady(ctx, srv.Client(), "example-go", nil, WaitOptions{Interval: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, err)
+	}
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddPtypes("example-go",
+		api.Ptype{Name: "web", Ready: "1/2", UpToDate: 2, AvailableReplicas: 1},
+		api.Ptype{Name: "worker", Ready: "1/1", UpToDate: 1, AvailableReplicas: 1},
+	)
+	srv.AddPods("example-go",
+		api.Pods{Name: "example-go-web-1", Type: "web", Ready: "1/1"},
+		api.Pods{Name: "example-go-web-2", Type: "web", Ready: "0/1", Restarts: 5},
+		api.Pods{Name: "example-go-worker-1", Type: "worker", Ready: "1/1"},
+	)
+	srv.SetPodState("example-go", "example-go-web-2", api.PodState{{
+		Container: "web",
+		State: map[string]map[string]any{"waiting": {
+			"reason":  "CrashLoopBackOff",
+			"message": "back-off 5m0s restarting failed container",
+		}},
+		RestartCount: 5,
+	}})
+	srv.AddPtypeEvents("example-go", "web", api.AppEvent{Reason: "BackOff", Message: "Back-off restarting failed container"})
+	srv.AddPtypeEvents("example-go", "worker", api.AppEvent{Reason: "Started", Message: "Started container"})
+
+	err := WaitReady(context.Background(), srv.Client(), "example-go", nil, WaitOptions{
+		Interval: time.Millisecond,
+		Timeout:  50 * time.Millisecond,
+	})
+	var notReady *NotReadyError
+	if !errors.As(err, &notReady) {
+		t.Fatalf("Expected a NotReadyError, Got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, Got %v", context.DeadlineExceeded, notReady.Err)
+	}
+	if len(notReady.Ptypes) != 2 || notReady.Ptypes[0].Ready() || !notReady.Ptypes[1].Ready() {
+		t.Errorf("Expected web not to be ready, Got %v", notReady.Ptypes)
+	}
+	expected := CrashingContainer{
+		Pod:          "example-go-web-2",
+		Ptype:        "web",
+		Container:    "web",
+		Reason:       "CrashLoopBackOff",
+		Message:      "back-off 5m0s restarting failed container",
+		RestartCount: 5,
+	}
+	if len(notReady.Crashing) != 1 || notReady.Crashing[0] != expected {
+		t.Errorf("Expected %v, Got %v", expected, notReady.Crashing)
+	}
+	if len(notReady.Events) != 1 || notReady.Events["web"][0].Reason != "BackOff" {
+		t.Errorf("Expected the events of web, Got %v", notReady.Events)
+	}
+	for _, s := range []string{"web 1/2 ready", "example-go-web-2 (web): CrashLoopBackOff, 5 restarts", "web BackOff"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("Expected %q in %q", s, err.Error())
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package releases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/builds"
+	"github.com/drycc/controller-sdk-go/config"
+)
+
+// maskedValue replaces the values of environment variables and registry credentials in
+// a [ReleaseDiff].
+const maskedValue = "****"
+
+// ChangeKind is the kind of a [Change].
+type ChangeKind string
+
+// The kinds of changes.
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is a change of a value between two releases. From is empty for added values and
+// To is empty for removed values.
+type Change struct {
+	Key  string     `json:"key"`
+	Kind ChangeKind `json:"kind"`
+	From string     `json:"from,omitempty"`
+	To   string     `json:"to,omitempty"`
+}
+
+// ReleaseDiff is the difference between two releases of an app, as returned by [Diff].
+// Keys are process types for the Procfile, limits and timeouts, and are prefixed with the
+// process type for healthchecks ("web.livenessProbe"), lifecycle hooks ("web.preStop"),
+// tags and registry settings. Environment variables are keyed by name, prefixed with
+// their process type ("web/PORT") or group ("@db/URL") if scoped.
+//
+// The values of environment variables and registry settings are masked, unless the diff
+// is returned by [ReleaseDiff.Unmasked].
+//
+// ReleaseDiff renders as JSON with [encoding/json], and as text with
+// [ReleaseDiff.Unified].
+type ReleaseDiff struct {
+	App  string `json:"app"`
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	// Build holds the changes of the image, stack and sha of the build.
+	Build        []Change `json:"build,omitempty"`
+	Procfile     []Change `json:"procfile,omitempty"`
+	Env          []Change `json:"env,omitempty"`
+	Refs         []Change `json:"refs,omitempty"`
+	Limits       []Change `json:"limits,omitempty"`
+	Timeouts     []Change `json:"timeouts,omitempty"`
+	Healthchecks []Change `json:"healthchecks,omitempty"`
+	Lifecycle    []Change `json:"lifecycle,omitempty"`
+	Tags         []Change `json:"tags,omitempty"`
+	Registry     []Change `json:"registry,omitempty"`
+
+	// env and registry hold the unmasked changes.
+	env      []Change
+	registry []Change
+}
+
+// Diff returns the difference between two releases of an app, resolving the build and the
+// config of each release.
+func Diff(c *drycc.Client, appID string, from, to int) (*ReleaseDiff, error) {
+	return DiffWithContext(context.Background(), c, appID, from, to)
+}
+
+// DiffWithContext is like [Diff] but cancels the requests when ctx is done.
+func DiffWithContext(ctx context.Context, c *drycc.Client, appID string, from, to int) (*ReleaseDiff, error) {
+	fromBuild, fromConfig, err := resolve(ctx, c, appID, from)
+	if err != nil {
+		return nil, err
+	}
+	toBuild, toConfig, err := resolve(ctx, c, appID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &ReleaseDiff{App: appID, From: from, To: to}
+	d.Build = diffValues(
+		map[string]string{"image": fromBuild.Image, "stack": fromBuild.Stack, "sha": fromBuild.Sha},
+		map[string]string{"image": toBuild.Image, "stack": toBuild.Stack, "sha": toBuild.Sha},
+	)
+	d.Procfile = diffValues(fromBuild.Procfile, toBuild.Procfile)
+	d.env = diffValues(envValues(fromConfig.Values), envValues(toConfig.Values))
+	d.Refs = diffValues(refValues(fromConfig.ValuesRefs), refValues(toConfig.ValuesRefs))
+	d.Limits = diffValues(flatten(fromConfig.Limits, 1), flatten(toConfig.Limits, 1))
+	d.Timeouts = diffValues(flatten(fromConfig.Timeout, 1), flatten(toConfig.Timeout, 1))
+	d.Healthchecks = diffValues(flatten(fromConfig.Healthcheck, 2), flatten(toConfig.Healthcheck, 2))
+	d.Lifecycle = diffValues(flatten(fromConfig.Lifecycle, 2), flatten(toConfig.Lifecycle, 2))
+	d.Tags = diffValues(flatten(fromConfig.Tags, 2), flatten(toConfig.Tags, 2))
+	d.registry = diffValues(flatten(fromConfig.Registry, 2), flatten(toConfig.Registry, 2))
+	d.Env, d.Registry = mask(d.env), mask(d.registry)
+	return d, nil
+}
+
+// resolve returns the build and the config of a release. Releases without a build, such
+// as the initial release, have an empty build.
+func resolve(ctx context.Context, c *drycc.Client, appID string, version int) (api.Build, api.Config, error) {
+	build, err := builds.GetWithContext(ctx, c, appID, version)
+	var notFound drycc.ErrNotFound
+	if errors.As(err, &notFound) {
+		build, err = api.Build{}, nil
+	}
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return api.Build{}, api.Config{}, fmt.Errorf("build of release v%d: %w", version, err)
+	}
+	cfg, err := config.ListWithContext(ctx, c, appID, version)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return api.Build{}, api.Config{}, fmt.Errorf("config of release v%d: %w", version, err)
+	}
+	return build, cfg, nil
+}
+
+// Unmasked returns a copy of a diff returned by [Diff], showing the values of environment
+// variables and registry settings.
+func (d *ReleaseDiff) Unmasked() *ReleaseDiff {
+	unmasked := *d
+	unmasked.Env, unmasked.Registry = d.env, d.registry
+	return &unmasked
+}
+
+// Empty reports whether the releases don't differ.
+func (d *ReleaseDiff) Empty() bool {
+	for _, s := range d.sections() {
+		if len(s.changes) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type diffSection struct {
+	name    string
+	changes []Change
+}
+
+func (d *ReleaseDiff) sections() []diffSection {
+	return []diffSection{
+		{"build", d.Build},
+		{"procfile", d.Procfile},
+		{"env", d.Env},
+		{"refs", d.Refs},
+		{"limits", d.Limits},
+		{"timeouts", d.Timeouts},
+		{"healthchecks", d.Healthchecks},
+		{"lifecycle", d.Lifecycle},
+		{"tags", d.Tags},
+		{"registry", d.Registry},
+	}
+}
+
+// Unified renders the diff like a unified diff, with a hunk per changed section:
+//
+//	--- example-go v41
+//	+++ example-go v42
+//	@@ build @@
+//	-image: example:v1
+//	+image: example:v2
+func (d *ReleaseDiff) Unified() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s v%d\n+++ %s v%d\n", d.App, d.From, d.App, d.To)
+	for _, s := range d.sections() {
+		if len(s.changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "@@ %s @@\n", s.name)
+		for _, change := range s.changes {
+			if change.Kind != Added {
+				fmt.Fprintf(&b, "-%s: %s\n", change.Key, change.From)
+			}
+			if change.Kind != Removed {
+				fmt.Fprintf(&b, "+%s: %s\n", change.Key, change.To)
+			}
+		}
+	}
+	return b.String()
+}
+
+// diffValues returns the changes between two sets of values, sorted by key. Empty values
+// are considered unset.
+func diffValues(from, to map[string]string) []Change {
+	var changes []Change
+	keys := slices.Collect(maps.Keys(from))
+	for k := range maps.Keys(to) {
+		if _, ok := from[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		f, t := from[k], to[k]
+		switch {
+		case f == t:
+		case f == "":
+			changes = append(changes, Change{Key: k, Kind: Added, To: t})
+		case t == "":
+			changes = append(changes, Change{Key: k, Kind: Removed, From: f})
+		default:
+			changes = append(changes, Change{Key: k, Kind: Modified, From: f, To: t})
+		}
+	}
+	return changes
+}
+
+// mask returns a copy of changes with their values masked.
+func mask(changes []Change) []Change {
+	masked := slices.Clone(changes)
+	for i := range masked {
+		if masked[i].From != "" {
+			masked[i].From = maskedValue
+		}
+		if masked[i].To != "" {
+			masked[i].To = maskedValue
+		}
+	}
+	return masked
+}
+
+// envValues returns config values by key, see [ReleaseDiff].
+func envValues(values []api.ConfigValue) map[string]string {
+	env := map[string]string{}
+	for _, v := range values {
+		key := v.Name
+		switch {
+		case v.Ptype != "":
+			key = v.Ptype + "/" + v.Name
+		case v.Group != "":
+			key = "@" + v.Group + "/" + v.Name
+		}
+		env[key] = render(v.Value)
+	}
+	return env
+}
+
+// refValues returns the config groups referenced by each process type.
+func refValues(refs api.ValuesRefs) map[string]string {
+	values := map[string]string{}
+	for ptype, groups := range refs {
+		values[ptype] = strings.Join(groups, ",")
+	}
+	return values
+}
+
+// flatten returns the values of a map by key. Nested maps are flattened down to depth,
+// joining their keys with dots.
+func flatten(v any, depth int) map[string]string {
+	values := map[string]string{}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return values
+	}
+	var decoded any
+	if json.Unmarshal(data, &decoded) != nil {
+		return values
+	}
+	var walk func(prefix string, v any, depth int)
+	walk = func(prefix string, v any, depth int) {
+		if m, ok := v.(map[string]any); ok && depth > 0 {
+			for k, child := range m {
+				if prefix != "" {
+					k = prefix + "." + k
+				}
+				walk(k, child, depth-1)
+			}
+			return
+		}
+		values[prefix] = render(v)
+	}
+	walk("", decoded, depth)
+	return values
+}
+
+// render renders a value of a diff: strings as is, and other values as JSON.
+func render(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,111 @@
+package releases
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	probe := &api.ContainerProbe{TimeoutSeconds: 5, HTTPGet: &api.HTTPGetAction{Path: "/healthz", Port: 8000}}
+	srv.SetConfig("example-go", api.Config{
+		Values: []api.ConfigValue{
+			{ConfigVar: api.ConfigVar{Name: "DEBUG", Value: "1"}},
+			{Ptype: "web", ConfigVar: api.ConfigVar{Name: "PORT", Value: 8000}},
+		},
+		Limits: map[string]any{"web": "std1.large.c1m1"},
+	})
+	from := srv.SetBuild("example-go", api.Build{
+		Image: "example:v1", Stack: "container", Sha: "abc",
+		Procfile: map[string]string{"web": "./server", "clock": "./clock"},
+	})
+	srv.SetConfig("example-go", api.Config{
+		Values: []api.ConfigValue{
+			{Ptype: "web", ConfigVar: api.ConfigVar{Name: "PORT", Value: 8080}},
+			{Group: "db", ConfigVar: api.ConfigVar{Name: "URL", Value: "postgres://db"}},
+		},
+		Limits:      map[string]any{"web": "std1.xlarge.c2m4"},
+		Healthcheck: map[string]*api.Healthcheck{"web": {LivenessProbe: &probe}},
+		Tags:        map[string]api.ConfigTags{"web": {"zone": "a"}},
+	})
+	to := srv.SetBuild("example-go", api.Build{
+		Image: "example:v2", Stack: "container", Sha: "def",
+		Procfile: map[string]string{"web": "./server", "worker": "./worker"},
+	})
+
+	d, err := Diff(srv.Client(), "example-go", from.Version, to.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ReleaseDiff{
+		App: "example-go", From: from.Version, To: to.Version,
+		Build: []Change{
+			{Key: "image", Kind: Modified, From: "example:v1", To: "example:v2"},
+			{Key: "sha", Kind: Modified, From: "abc", To: "def"},
+		},
+		Procfile: []Change{
+			{Key: "clock", Kind: Removed, From: "./clock"},
+			{Key: "worker", Kind: Added, To: "./worker"},
+		},
+		Env: []Change{
+			{Key: "@db/URL", Kind: Added, To: "****"},
+			{Key: "DEBUG", Kind: Removed, From: "****"},
+			{Key: "web/PORT", Kind: Modified, From: "****", To: "****"},
+		},
+		Limits: []Change{{Key: "web", Kind: Modified, From: "std1.large.c1m1", To: "std1.xlarge.c2m4"}},
+		Healthchecks: []Change{{
+			Key: "web.livenessProbe", Kind: Added,
+			To: `{"failureThreshold":0,"httpGet":{"path":"/healthz","port":8000},"initialDelaySeconds":0,"periodSeconds":0,"successThreshold":0,"timeoutSeconds":5}`,
+		}},
+		Tags: []Change{{Key: "web.zone", Kind: Added, To: "a"}},
+	}
+	expected.env = d.env
+	if !reflect.DeepEqual(expected, d) {
+		t.Errorf("Expected %+v, Got %+v", expected, d)
+	}
+
+	unmasked := d.Unmasked().Env
+	if unmasked[2].From != "8000" || unmasked[2].To != "8080" {
+		t.Errorf("Expected the values of PORT, Got %v", unmasked[2])
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "postgres") || !strings.Contains(string(data), `{"key":"DEBUG","kind":"removed","from":"****"}`) {
+		t.Errorf("Expected masked values, Got %s", data)
+	}
+
+	text := d.Unified()
+	for _, s := range []string{
+		"--- example-go v3\n+++ example-go v5\n@@ build @@\n-image: example:v1\n+image: example:v2\n",
+		"@@ procfile @@\n-clock: ./clock\n+worker: ./worker\n",
+		"@@ env @@\n+@db/URL: ****\n-DEBUG: ****\n-web/PORT: ****\n+web/PORT: ****\n",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected %q in %q", s, text)
+		}
+	}
+
+	// The initial release has no build.
+	d, err = Diff(srv.Client(), "example-go", 1, from.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Build) != 3 || d.Build[0].Kind != Added || d.Empty() {
+		t.Errorf("Expected the build to be added, Got %v", d.Build)
+	}
+	if d, _ := Diff(srv.Client(), "example-go", to.Version, to.Version); !d.Empty() {
+		t.Errorf("Expected an empty diff, Got %v", d.Unified())
+	}
+}
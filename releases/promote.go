@@ -0,0 +1,164 @@
+package releases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/builds"
+	"github.com/drycc/controller-sdk-go/config"
+)
+
+// restoreTimeout bounds the restoration of the config of the target app after a failed
+// promotion.
+const restoreTimeout = 30 * time.Second
+
+// PromoteOptions configures [Promote].
+type PromoteOptions struct {
+	// Version is the version of the source release. If zero, the current release is promoted.
+	Version int
+	// ConfigKeys are the names of the config values copied from the source release, with
+	// their process type or group. Each name must be set in the source release.
+	ConfigKeys []string
+	// Limits copies the limits of the process types of the source release.
+	Limits bool
+}
+
+// Promotion is the result of [Promote].
+type Promotion struct {
+	Source        string
+	SourceVersion int
+	Target        string
+	// Version is the version of the release created on the target app.
+	Version int
+	// Diff is the difference between the previous release of the target app and the new one.
+	Diff *ReleaseDiff
+}
+
+// Promote creates a build on the target app with the image, stack, Procfile and Dryccfile
+// of a release of the source app, such as to promote a release from a staging app to a
+// production app. The selected config values and limits are copied first, in a release of
+// their own, so the build starts with them.
+//
+// The promotion isn't atomic: if the build fails, the copied values and limits are set
+// back to their previous values in another release, and the error of this restoration, if
+// any, is joined to the error of the build.
+func Promote(c *drycc.Client, sourceApp, targetApp string, opts PromoteOptions) (*Promotion, error) {
+	return PromoteWithContext(context.Background(), c, sourceApp, targetApp, opts)
+}
+
+// PromoteWithContext is like [Promote] but cancels the requests when ctx is done.
+func PromoteWithContext(ctx context.Context, c *drycc.Client, sourceApp, targetApp string, opts PromoteOptions) (*Promotion, error) {
+	p := &Promotion{Source: sourceApp, SourceVersion: opts.Version, Target: targetApp}
+	if p.SourceVersion <= 0 {
+		version, err := latestVersion(ctx, c, sourceApp)
+		if err != nil {
+			return nil, err
+		}
+		p.SourceVersion = version
+	}
+	build, err := builds.GetWithContext(ctx, c, sourceApp, p.SourceVersion)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return nil, fmt.Errorf("build of release v%d of %s: %w", p.SourceVersion, sourceApp, err)
+	}
+
+	var patch api.Config
+	if len(opts.ConfigKeys) > 0 || opts.Limits {
+		source, err := config.ListWithContext(ctx, c, sourceApp, p.SourceVersion)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			return nil, fmt.Errorf("config of release v%d of %s: %w", p.SourceVersion, sourceApp, err)
+		}
+		for _, key := range opts.ConfigKeys {
+			n := len(patch.Values)
+			for _, v := range source.Values {
+				if v.Name == key {
+					patch.Values = append(patch.Values, v)
+				}
+			}
+			if len(patch.Values) == n {
+				return nil, fmt.Errorf("config value %s is not set in release v%d of %s", key, p.SourceVersion, sourceApp)
+			}
+		}
+		if opts.Limits && len(source.Limits) > 0 {
+			patch.Limits = source.Limits
+		}
+	}
+
+	previous, err := latestVersion(ctx, c, targetApp)
+	if err != nil {
+		return nil, err
+	}
+	var revert *api.Config
+	if len(patch.Values) > 0 || len(patch.Limits) > 0 {
+		current, err := config.ListWithContext(ctx, c, targetApp, 0)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			return nil, fmt.Errorf("config of %s: %w", targetApp, err)
+		}
+		revert = revertPatch(current, patch)
+		if _, err := config.SetWithContext(ctx, c, targetApp, patch, true); err != nil && !drycc.IsErrAPIMismatch(err) {
+			return nil, fmt.Errorf("copying config to %s: %w", targetApp, err)
+		}
+	}
+	if _, err := builds.NewWithContext(ctx, c, targetApp, build.Image, build.Stack, build.Procfile, build.Dryccfile); err != nil && !drycc.IsErrAPIMismatch(err) {
+		if revert != nil {
+			err = errors.Join(err, restoreConfig(ctx, c, targetApp, *revert))
+		}
+		return nil, err
+	}
+
+	if p.Version, err = latestVersion(ctx, c, targetApp); err != nil {
+		return nil, err
+	}
+	if p.Diff, err = DiffWithContext(ctx, c, targetApp, previous, p.Version); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// revertPatch returns the patch restoring the values and limits of current changed by patch.
+// The values and limits that current doesn't have are unset.
+func revertPatch(current, patch api.Config) *api.Config {
+	revert := &api.Config{}
+	for _, v := range patch.Values {
+		previous := api.ConfigValue{Ptype: v.Ptype, Group: v.Group, ConfigVar: api.ConfigVar{Name: v.Name}}
+		for _, cv := range current.Values {
+			if cv.Name == v.Name && cv.Ptype == v.Ptype && cv.Group == v.Group {
+				previous.Value = cv.Value
+			}
+		}
+		revert.Values = append(revert.Values, previous)
+	}
+	if len(patch.Limits) > 0 {
+		revert.Limits = map[string]any{}
+		for ptype := range patch.Limits {
+			revert.Limits[ptype] = current.Limits[ptype]
+		}
+	}
+	return revert
+}
+
+// restoreConfig restores the config of the target app after a failed promotion, even if the
+// context of the promotion is done.
+func restoreConfig(ctx context.Context, c *drycc.Client, appID string, revert api.Config) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), restoreTimeout)
+	defer cancel()
+	if _, err := config.SetWithContext(ctx, c, appID, revert, true); err != nil && !drycc.IsErrAPIMismatch(err) {
+		return fmt.Errorf("restoring the config of %s: %w", appID, err)
+	}
+	return nil
+}
+
+// latestVersion returns the version of the latest release of an app.
+func latestVersion(ctx context.Context, c *drycc.Client, appID string) (int, error) {
+	latest, _, err := ListWithContext(ctx, c, appID, "", 1)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return 0, err
+	}
+	if len(latest) == 0 {
+		return 0, fmt.Errorf("app %s has no release", appID)
+	}
+	return latest[0].Version, nil
+}
@@ -0,0 +1,116 @@
+package releases
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func TestPromote(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-staging"})
+	srv.AddApp(api.App{ID: "example-production"})
+	srv.SetConfig("example-staging", api.Config{
+		Values: []api.ConfigValue{
+			{ConfigVar: api.ConfigVar{Name: "FEATURE_FLAGS", Value: "beta"}},
+			{Ptype: "web", ConfigVar: api.ConfigVar{Name: "WORKERS", Value: "4"}},
+			{ConfigVar: api.ConfigVar{Name: "DATABASE_URL", Value: "postgres://staging"}},
+		},
+		Limits: map[string]any{"web": "std1.large.c1m1"},
+	})
+	build := api.Build{
+		Image:     "registry.example.com/example:v2",
+		Stack:     "container",
+		Procfile:  map[string]string{"web": "./server"},
+		Dryccfile: map[string]any{"deploy": map[string]any{"web": map[string]any{"replicas": float64(2)}}},
+	}
+	source := srv.SetBuild("example-staging", build)
+	srv.SetBuild("example-staging", api.Build{Image: "registry.example.com/example:v3", Stack: "container", Procfile: build.Procfile})
+
+	p, err := Promote(srv.Client(), "example-staging", "example-production", PromoteOptions{
+		Version:    source.Version,
+		ConfigKeys: []string{"FEATURE_FLAGS", "WORKERS"},
+		Limits:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.SourceVersion != source.Version || p.Version != 3 {
+		t.Errorf("Expected v%d to be promoted to v3, Got %+v", source.Version, p)
+	}
+
+	releases := srv.Releases("example-production")
+	if len(releases) != 3 {
+		t.Fatalf("Expected a config and a build release, Got %v", releases)
+	}
+	config := srv.Config("example-production")
+	var names []string
+	for _, v := range config.Values {
+		names = append(names, v.Ptype+"/"+v.Name)
+	}
+	if expected := []string{"/FEATURE_FLAGS", "web/WORKERS"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("Expected %v, Got %v", expected, names)
+	}
+	if config.Limits["web"] != "std1.large.c1m1" {
+		t.Errorf("Expected the limits to be copied, Got %v", config.Limits)
+	}
+
+	text := p.Diff.Unified()
+	for _, s := range []string{
+		"--- example-production v1\n+++ example-production v3\n",
+		"+image: registry.example.com/example:v2\n",
+		"+web: ./server\n",
+		"+FEATURE_FLAGS: ****\n",
+		"+web: std1.large.c1m1\n",
+	} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Expected %q in %q", s, text)
+		}
+	}
+
+	// The current release of the source is promoted by default.
+	if p, err = Promote(srv.Client(), "example-staging", "example-production", PromoteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Diff.Build) != 1 || p.Diff.Build[0].To != "registry.example.com/example:v3" {
+		t.Errorf("Expected the image to change, Got %v", p.Diff.Build)
+	}
+
+	_, err = Promote(srv.Client(), "example-staging", "example-production", PromoteOptions{ConfigKeys: []string{"MISSING"}})
+	if err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("Expected an error for a missing config value, Got %v", err)
+	}
+	if n := len(srv.Releases("example-production")); n != 4 {
+		t.Errorf("Expected no release to be created, Got %d releases", n)
+	}
+
+	// The config copied to the target app is restored when the build fails.
+	srv.SetConfig("example-production", api.Config{
+		Values: []api.ConfigValue{{ConfigVar: api.ConfigVar{Name: "FEATURE_FLAGS", Value: "off"}}},
+	})
+	before := srv.Config("example-production")
+	srv.Fail(drycctest.Failure{Method: "POST", Path: "/v2/apps/example-production/build/", StatusCode: http.StatusInternalServerError, Times: 1})
+	_, err = Promote(srv.Client(), "example-staging", "example-production", PromoteOptions{ConfigKeys: []string{"FEATURE_FLAGS", "DATABASE_URL"}})
+	if err == nil {
+		t.Error("Expected the error of the build")
+	}
+	if after := srv.Config("example-production"); !reflect.DeepEqual(before.Values, after.Values) || !reflect.DeepEqual(before.Limits, after.Limits) {
+		t.Errorf("Expected the config to be restored to %v, Got %v", before.Values, after.Values)
+	}
+
+	// The controller is a minor version behind.
+	srv.SetAPIVersion("2.2")
+	if _, err = Promote(srv.Client(), "example-staging", "example-production", PromoteOptions{ConfigKeys: []string{"DATABASE_URL"}}); err != nil {
+		t.Fatalf("Expected an API mismatch to be tolerated, Got %v", err)
+	}
+	if values := srv.Config("example-production").Values; len(values) != 2 || values[1].Name != "DATABASE_URL" {
+		t.Errorf("Expected the config to be copied, Got %v", values)
+	}
+}
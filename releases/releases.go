@@ -2,8 +2,10 @@
 package releases
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,12 +13,12 @@ import (
 
 // List lists an app's releases.
 func List(c *drycc.Client, appID, ptypes string, results int) ([]api.Release, int, error) {
-	u := fmt.Sprintf("/v2/apps/%s/releases/", appID)
-	if ptypes != "" {
-		u += fmt.Sprintf("?ptypes=%s", ptypes)
-	}
+	return ListWithContext(context.Background(), c, appID, ptypes, results)
+}
 
-	body, count, reqErr := c.LimitedRequest(u, results)
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID, ptypes string, results int) ([]api.Release, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, listPath(appID, ptypes), results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Release{}, -1, reqErr
@@ -30,11 +32,26 @@ func List(c *drycc.Client, appID, ptypes string, results int) ([]api.Release, in
 	return releases, count, reqErr
 }
 
+// All returns an iterator over all releases of an app, fetching pages lazily.
+func All(c *drycc.Client, appID, ptypes string) iter.Seq2[api.Release, error] {
+	return AllWithContext(context.Background(), c, appID, ptypes)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID, ptypes string) iter.Seq2[api.Release, error] {
+	return drycc.Paginate[api.Release](ctx, c, listPath(appID, ptypes), drycc.DefaultPageSize)
+}
+
 // Get retrieves a release of an app.
 func Get(c *drycc.Client, appID string, version int) (api.Release, error) {
+	return GetWithContext(context.Background(), c, appID, version)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string, version int) (api.Release, error) {
 	u := fmt.Sprintf("/v2/apps/%s/releases/v%d/", appID, version)
 
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Release{}, reqErr
 	}
@@ -52,12 +69,17 @@ func Get(c *drycc.Client, appID string, version int) (api.Release, error) {
 // procType and name. To deploy an specific process, pass an procType by leave name empty.
 // To deploy a specific instance, pass a procType and a name.
 func Deploy(c *drycc.Client, appID string, targets map[string]any) error {
+	return DeployWithContext(context.Background(), c, appID, targets)
+}
+
+// DeployWithContext is like [Deploy] but cancels the request when ctx is done.
+func DeployWithContext(ctx context.Context, c *drycc.Client, appID string, targets map[string]any) error {
 	u := fmt.Sprintf("/v2/apps/%s/releases/deploy/", appID)
 	body, err := json.Marshal(targets)
 	if err != nil {
 		return err
 	}
-	res, err := c.Request("POST", u, body)
+	res, err := c.RequestWithContext(ctx, "POST", u, body)
 	if err != nil && !drycc.IsErrAPIMismatch(err) {
 		return err
 	}
@@ -68,6 +90,11 @@ func Deploy(c *drycc.Client, appID string, targets map[string]any) error {
 // Rollback rolls back an app to a previous release. If version is -1, this rolls back to
 // the previous release. Otherwise, roll back to the specified version.
 func Rollback(c *drycc.Client, appID string, ptypes string, version int) (int, error) {
+	return RollbackWithContext(context.Background(), c, appID, ptypes, version)
+}
+
+// RollbackWithContext is like [Rollback] but cancels the request when ctx is done.
+func RollbackWithContext(ctx context.Context, c *drycc.Client, appID string, ptypes string, version int) (int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/releases/rollback/", appID)
 
 	req := api.ReleaseRollback{Ptypes: ptypes, Version: version}
@@ -81,7 +108,7 @@ func Rollback(c *drycc.Client, appID string, ptypes string, version int) (int, e
 		}
 	}
 
-	res, reqErr := c.Request("POST", u, reqBody)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, reqBody)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return -1, reqErr
 	}
@@ -95,3 +122,11 @@ func Rollback(c *drycc.Client, appID string, ptypes string, version int) (int, e
 
 	return response.Version, reqErr
 }
+
+func listPath(appID, ptypes string) string {
+	u := fmt.Sprintf("/v2/apps/%s/releases/", appID)
+	if ptypes != "" {
+		u += fmt.Sprintf("?ptypes=%s", ptypes)
+	}
+	return u
+}
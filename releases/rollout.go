@@ -0,0 +1,154 @@
+package releases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// The states of releases and of their conditions.
+const (
+	StateCreated = "created"
+	StateSucceed = "succeed"
+	StateCrashed = "crashed"
+)
+
+// RolloutEventType is the type of a [RolloutEvent].
+type RolloutEventType int
+
+const (
+	// StateChanged is emitted when the state of the release is first seen, and when it changes.
+	StateChanged RolloutEventType = iota
+	// ConditionAdded is emitted for each condition added to the release.
+	ConditionAdded
+)
+
+func (t RolloutEventType) String() string {
+	switch t {
+	case StateChanged:
+		return "StateChanged"
+	case ConditionAdded:
+		return "ConditionAdded"
+	}
+	return fmt.Sprintf("RolloutEventType(%d)", int(t))
+}
+
+// RolloutEvent is a change of a release observed by [WatchRollout].
+type RolloutEvent struct {
+	Type RolloutEventType
+	// Release is the release as polled.
+	Release api.Release
+	// Condition is the added condition, for ConditionAdded events.
+	Condition api.Condition
+}
+
+// RolloutError is returned by [WatchRollout] when a rollout fails.
+type RolloutError struct {
+	App     string
+	Version int
+	// State is the state of the release.
+	State string
+	// Exception is the exception of the failed condition, or of the release.
+	Exception string
+	// Condition is the failed condition, if the failure was reported by one.
+	Condition *api.Condition
+}
+
+func (e *RolloutError) Error() string {
+	msg := fmt.Sprintf("rollout of release v%d of %s failed", e.Version, e.App)
+	if e.Condition != nil && e.Condition.Action != "" {
+		msg += " during " + e.Condition.Action
+	}
+	if e.Exception != "" {
+		msg += ": " + e.Exception
+	}
+	return msg
+}
+
+// WatchOptions configures [WatchRollout].
+type WatchOptions struct {
+	// Interval is the interval between the polls of the release. Defaults to 2s.
+	Interval time.Duration
+	// SkipConditions is the number of conditions of the release that predate the rollout.
+	// They aren't reported, and the rollout completes with the next condition. To follow a
+	// [Deploy] of an existing release, set it to the number of conditions of the release
+	// before deploying. If zero, the rollout completes with the state of the release.
+	SkipConditions int
+	// OnEvent, if not nil, is called with the events of the rollout, in order.
+	OnEvent func(RolloutEvent)
+}
+
+// WatchRollout polls a release until its rollout completes, and returns the release. A
+// version below 1 watches the latest release. The rollout fails with a [*RolloutError] if
+// a new condition or the release crashes. If ctx is done first, its error is returned,
+// wrapped with the last state of the release, along with the last polled release.
+func WatchRollout(ctx context.Context, c *drycc.Client, appID string, version int, opts WatchOptions) (api.Release, error) {
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	emit := func(e RolloutEvent) {
+		if opts.OnEvent != nil {
+			opts.OnEvent(e)
+		}
+	}
+	if version < 1 {
+		latest, err := latestVersion(ctx, c, appID)
+		if err != nil {
+			return api.Release{}, err
+		}
+		version = latest
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	var last api.Release
+	seen := opts.SkipConditions
+	for {
+		release, err := GetWithContext(ctx, c, appID, version)
+		if err != nil && ctx.Err() == nil {
+			return api.Release{}, err
+		}
+		if err == nil {
+			if release.State != last.State || last.Version == 0 {
+				emit(RolloutEvent{Type: StateChanged, Release: release})
+			}
+			var crashed *api.Condition
+			for i := seen; i < len(release.Conditions); i++ {
+				condition := release.Conditions[i]
+				emit(RolloutEvent{Type: ConditionAdded, Release: release, Condition: condition})
+				if condition.State == StateCrashed && crashed == nil {
+					crashed = &condition
+				}
+			}
+			added := len(release.Conditions) > seen
+			seen = max(seen, len(release.Conditions))
+			last = release
+
+			switch {
+			case crashed != nil:
+				return release, &RolloutError{
+					App: appID, Version: version, State: release.State,
+					Exception: crashed.Exception, Condition: crashed,
+				}
+			case release.State == StateCrashed:
+				return release, &RolloutError{
+					App: appID, Version: version, State: release.State, Exception: release.Exception,
+				}
+			case opts.SkipConditions > 0 && added, opts.SkipConditions == 0 && release.State == StateSucceed:
+				return release, nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if last.Version == 0 {
+				return last, ctx.Err()
+			}
+			return last, fmt.Errorf("release v%d of %s is %s: %w", version, appID, last.State, ctx.Err())
+		}
+	}
+}
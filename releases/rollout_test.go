@@ -0,0 +1,88 @@
+package releases
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func TestWatchRollout(t *testing.T) {
+	t.Parallel()
+
+	srv := drycctest.NewServer()
+	defer srv.Close()
+	srv.AddApp(api.App{ID: "example-go"})
+	client := srv.Client()
+	opts := WatchOptions{Interval: time.Millisecond}
+
+	// The release is rolled out on the second poll.
+	v2 := srv.AddRelease("example-go", api.Release{State: StateCreated})
+	var events []string
+	opts.OnEvent = func(e RolloutEvent) {
+		events = append(events, e.Type.String()+" "+e.Release.State+" "+e.Condition.Action)
+		if len(events) == 1 {
+			srv.UpdateRelease("example-go", v2.Version, func(r *api.Release) {
+				r.State = StateSucceed
+				r.Conditions = append(r.Conditions, api.Condition{State: StateSucceed, Action: "pipeline", Ptypes: []string{"web"}})
+			})
+		}
+	}
+	release, err := WatchRollout(context.Background(), client, "example-go", 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != v2.Version || release.State != StateSucceed {
+		t.Errorf("Expected release v%d to succeed, Got %v", v2.Version, release)
+	}
+	expected := []string{"StateChanged created ", "StateChanged succeed ", "ConditionAdded succeed pipeline"}
+	if !reflect.DeepEqual(expected, events) {
+		t.Errorf("Expected %v, Got %v", expected, events)
+	}
+
+	// A deploy of the release adds a condition.
+	opts.OnEvent, opts.SkipConditions = nil, len(release.Conditions)
+	if err := DeployWithContext(context.Background(), client, "example-go", nil); err != nil {
+		t.Fatal(err)
+	}
+	if release, err = WatchRollout(context.Background(), client, "example-go", v2.Version, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(release.Conditions) != 2 || release.Conditions[1].Action != "deploy" {
+		t.Errorf("Expected a deploy condition, Got %v", release.Conditions)
+	}
+
+	v3 := srv.AddRelease("example-go", api.Release{
+		State: StateCrashed,
+		Conditions: []api.Condition{{
+			State: StateCrashed, Action: "pipeline", Exception: "ImagePullBackOff: image example not found",
+		}},
+	})
+	_, err = WatchRollout(context.Background(), client, "example-go", v3.Version, WatchOptions{Interval: time.Millisecond})
+	var rolloutErr *RolloutError
+	if !errors.As(err, &rolloutErr) {
+		t.Fatalf("Expected a RolloutError, Got %v", err)
+	}
+	if rolloutErr.Exception != "ImagePullBackOff: image example not found" || rolloutErr.Condition == nil {
+		t.Errorf("Expected the exception of the condition, Got %v", rolloutErr)
+	}
+	if expected := "rollout of release v3 of example-go failed during pipeline: ImagePullBackOff: image example not found"; err.Error() != expected {
+		t.Errorf("Expected %v, Got %v", expected, err)
+	}
+
+	srv.AddRelease("example-go", api.Release{State: StateCreated})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	release, err = WatchRollout(ctx, client, "example-go", 0, WatchOptions{Interval: time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "release v4 of example-go is created") {
+		t.Errorf("Expected %v, Got %v", context.DeadlineExceeded, err)
+	}
+	if release.Version != 4 {
+		t.Errorf("Expected %v, Got %v", 4, release.Version)
+	}
+}
@@ -2,8 +2,10 @@
 package resources
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // Services is list all available resource services
 func Services(c *drycc.Client, results int) (api.ResourceServices, int, error) {
+	return ServicesWithContext(context.Background(), c, results)
+}
+
+// ServicesWithContext is like [Services] but cancels the request when ctx is done.
+func ServicesWithContext(ctx context.Context, c *drycc.Client, results int) (api.ResourceServices, int, error) {
 	u := "/v2/resources/services/"
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.ResourceService{}, -1, reqErr
 	}
@@ -23,10 +30,25 @@ func Services(c *drycc.Client, results int) (api.ResourceServices, int, error) {
 	return services, count, reqErr
 }
 
+// AllServices returns an iterator over all resource services, fetching pages lazily.
+func AllServices(c *drycc.Client) iter.Seq2[api.ResourceService, error] {
+	return AllServicesWithContext(context.Background(), c)
+}
+
+// AllServicesWithContext is like [AllServices] but cancels the requests when ctx is done.
+func AllServicesWithContext(ctx context.Context, c *drycc.Client) iter.Seq2[api.ResourceService, error] {
+	return drycc.Paginate[api.ResourceService](ctx, c, "/v2/resources/services/", drycc.DefaultPageSize)
+}
+
 // Plans is list all available resource services
 func Plans(c *drycc.Client, serviceName string, results int) (api.ResourcePlans, int, error) {
+	return PlansWithContext(context.Background(), c, serviceName, results)
+}
+
+// PlansWithContext is like [Plans] but cancels the request when ctx is done.
+func PlansWithContext(ctx context.Context, c *drycc.Client, serviceName string, results int) (api.ResourcePlans, int, error) {
 	u := fmt.Sprintf("/v2/resources/services/%s/plans/", serviceName)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.ResourcePlan{}, -1, reqErr
 	}
@@ -37,10 +59,25 @@ func Plans(c *drycc.Client, serviceName string, results int) (api.ResourcePlans,
 	return plans, count, reqErr
 }
 
+// AllPlans returns an iterator over all plans of a resource service, fetching pages lazily.
+func AllPlans(c *drycc.Client, serviceName string) iter.Seq2[api.ResourcePlan, error] {
+	return AllPlansWithContext(context.Background(), c, serviceName)
+}
+
+// AllPlansWithContext is like [AllPlans] but cancels the requests when ctx is done.
+func AllPlansWithContext(ctx context.Context, c *drycc.Client, serviceName string) iter.Seq2[api.ResourcePlan, error] {
+	return drycc.Paginate[api.ResourcePlan](ctx, c, fmt.Sprintf("/v2/resources/services/%s/plans/", serviceName), drycc.DefaultPageSize)
+}
+
 // List list an app's resources.
 func List(c *drycc.Client, appID string, results int) (api.Resources, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Resources, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/resources/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Resource{}, -1, reqErr
 	}
@@ -51,14 +88,29 @@ func List(c *drycc.Client, appID string, results int) (api.Resources, int, error
 	return resources, count, reqErr
 }
 
+// All returns an iterator over all resources of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Resource, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Resource, error] {
+	return drycc.Paginate[api.Resource](ctx, c, fmt.Sprintf("/v2/apps/%s/resources/", appID), drycc.DefaultPageSize)
+}
+
 // Create create an app's resource.
 func Create(c *drycc.Client, appID string, resource api.Resource) (api.Resource, error) {
+	return CreateWithContext(context.Background(), c, appID, resource)
+}
+
+// CreateWithContext is like [Create] but cancels the request when ctx is done.
+func CreateWithContext(ctx context.Context, c *drycc.Client, appID string, resource api.Resource) (api.Resource, error) {
 	body, err := json.Marshal(resource)
 	if err != nil {
 		return api.Resource{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/resources/", appID)
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil {
 		return api.Resource{}, reqErr
 	}
@@ -72,8 +124,13 @@ func Create(c *drycc.Client, appID string, resource api.Resource) (api.Resource,
 
 // Get retrieves information about a resource
 func Get(c *drycc.Client, appID string, name string) (api.Resource, error) {
+	return GetWithContext(context.Background(), c, appID, name)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string, name string) (api.Resource, error) {
 	u := fmt.Sprintf("/v2/apps/%s/resources/%s/", appID, name)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil {
 		return api.Resource{}, reqErr
 	}
@@ -87,8 +144,13 @@ func Get(c *drycc.Client, appID string, name string) (api.Resource, error) {
 
 // Delete delete an app's resource.
 func Delete(c *drycc.Client, appID string, name string) error {
+	return DeleteWithContext(context.Background(), c, appID, name)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, name string) error {
 	u := fmt.Sprintf("/v2/apps/%s/resources/%s/", appID, name)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -97,12 +159,17 @@ func Delete(c *drycc.Client, appID string, name string) error {
 
 // Put update resource
 func Put(c *drycc.Client, appID string, name string, resource api.Resource) (api.Resource, error) {
+	return PutWithContext(context.Background(), c, appID, name, resource)
+}
+
+// PutWithContext is like [Put] but cancels the request when ctx is done.
+func PutWithContext(ctx context.Context, c *drycc.Client, appID string, name string, resource api.Resource) (api.Resource, error) {
 	body, err := json.Marshal(resource)
 	if err != nil {
 		return api.Resource{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/resources/%s/", appID, name)
-	res, reqErr := c.Request("PUT", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PUT", u, body)
 	if reqErr != nil {
 		return api.Resource{}, reqErr
 	}
@@ -116,12 +183,17 @@ func Put(c *drycc.Client, appID string, name string, resource api.Resource) (api
 
 // Binding servicebinding binding with a serviceinstance
 func Binding(c *drycc.Client, appID string, name string, resource api.ResourceBinding) (api.Resource, error) {
+	return BindingWithContext(context.Background(), c, appID, name, resource)
+}
+
+// BindingWithContext is like [Binding] but cancels the request when ctx is done.
+func BindingWithContext(ctx context.Context, c *drycc.Client, appID string, name string, resource api.ResourceBinding) (api.Resource, error) {
 	body, err := json.Marshal(resource)
 	if err != nil {
 		return api.Resource{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/resources/%s/binding/", appID, name)
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil {
 		return api.Resource{}, reqErr
 	}
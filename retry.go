@@ -0,0 +1,205 @@
+package drycc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how [Client.Do] retries requests that fail because of
+// transient errors, such as connection resets or a gateway returning 502/503.
+//
+// Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are retried unless
+// RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than 2 disables retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts computed from the backoff.
+	// A delay requested by the server through Retry-After is not capped.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor applied to the delay after each attempt.
+	Multiplier float64
+
+	// Jitter is the fraction, between 0 and 1, of each delay that is randomized.
+	Jitter float64
+
+	// RetryStatusCodes are the response status codes that are considered transient.
+	RetryStatusCodes []int
+
+	// RetryNonIdempotent allows POST and PATCH requests to be retried.
+	// Only enable this if the requests are safe to replay.
+	RetryNonIdempotent bool
+
+	// OnAttempt, if set, is called after every attempt with its outcome.
+	OnAttempt func(Attempt)
+}
+
+// Attempt describes the outcome of a single attempt of a request.
+type Attempt struct {
+	// Number is the attempt number, starting at 1.
+	Number int
+	// Request is the request that was sent.
+	Request *http.Request
+	// StatusCode is the status code of the response, or 0 if no response was received.
+	StatusCode int
+	// Err is the transport error of the attempt, if any.
+	Err error
+	// Retry is true if the request will be sent again.
+	Retry bool
+	// Delay is the time waited before the next attempt.
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy returns a policy making up to 4 attempts with an exponential backoff
+// starting at 500ms, which covers a controller rollout or a gateway restart.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// send sends req with the HTTP client, retrying it according to the client's retry policy.
+// Redirects are not followed if the request context was created by [WithoutRedirect].
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if noRedirect, _ := req.Context().Value(noRedirectKey{}).(bool); noRedirect {
+		copied := *client
+		copied.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		client = &copied
+	}
+
+	policy := c.Retry
+	if policy == nil || policy.MaxAttempts < 2 {
+		return client.Do(req)
+	}
+
+	for number := 1; ; number++ {
+		if number > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		res, err := client.Do(req)
+		attempt := Attempt{Number: number, Request: req, Err: err}
+		if res != nil {
+			attempt.StatusCode = res.StatusCode
+		}
+		attempt.Retry = number < policy.MaxAttempts && policy.retryable(req, res, err)
+		if attempt.Retry {
+			attempt.Delay = policy.delay(number, res)
+		}
+		if policy.OnAttempt != nil {
+			policy.OnAttempt(attempt)
+		}
+		if !attempt.Retry {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if err := sleep(req.Context(), attempt.Delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *RetryPolicy) retryable(req *http.Request, res *http.Response, err error) bool {
+	if !p.RetryNonIdempotent && !isIdempotent(req.Method) {
+		return false
+	}
+	// A body that can't be rewound can't be sent twice.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil && !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+	return slices.Contains(p.RetryStatusCodes, res.StatusCode)
+}
+
+// delay returns the time to wait after the given attempt number. A Retry-After header
+// sent by the server takes precedence over the backoff.
+func (p *RetryPolicy) delay(number int, res *http.Response) time.Duration {
+	if res != nil {
+		if d, ok := parseRetryAfter(res.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	backoff := float64(p.InitialBackoff)
+	if p.Multiplier > 0 {
+		backoff *= math.Pow(p.Multiplier, float64(number-1))
+	}
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		backoff -= backoff * jitter * rand.Float64()
+	}
+	return time.Duration(backoff)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either
+// a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
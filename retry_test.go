@@ -0,0 +1,194 @@
+package drycc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with status, then succeeds.
+type flakyServer struct {
+	failures   int32
+	status     int
+	retryAfter string
+	calls      atomic.Int32
+}
+
+func (f *flakyServer) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	res.Header().Add("DRYCC_API_VERSION", APIVersion)
+	body, _ := io.ReadAll(req.Body)
+	if f.calls.Add(1) <= f.failures {
+		if f.retryAfter != "" {
+			res.Header().Add("Retry-After", f.retryAfter)
+		}
+		res.WriteHeader(f.status)
+		res.Write(nil)
+		return
+	}
+	res.Write(body)
+}
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryIdempotent(t *testing.T) {
+	t.Parallel()
+
+	handler := &flakyServer{failures: 2, status: http.StatusBadGateway}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.Retry = testRetryPolicy()
+
+	var attempts []Attempt
+	drycc.Retry.OnAttempt = func(a Attempt) {
+		attempts = append(attempts, a)
+	}
+
+	res, err := drycc.Request("PUT", "/retry/", []byte("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// The body must be replayed on every attempt.
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "test" {
+		t.Errorf("Expected %s, Got %s", "test", body)
+	}
+
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 attempts, Got %d", len(attempts))
+	}
+	for i, attempt := range attempts {
+		if attempt.Number != i+1 {
+			t.Errorf("Expected attempt %d, Got %d", i+1, attempt.Number)
+		}
+		if retry := i < 2; attempt.Retry != retry {
+			t.Errorf("Expected retry %v for attempt %d, Got %v", retry, attempt.Number, attempt.Retry)
+		}
+	}
+	if attempts[0].StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected %d, Got %d", http.StatusBadGateway, attempts[0].StatusCode)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	handler := &flakyServer{failures: 10, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.Retry = testRetryPolicy()
+
+	if _, err = drycc.Request("GET", "/retry/", nil); err == nil {
+		t.Error("Expected an error")
+	}
+	if calls := handler.calls.Load(); calls != 4 {
+		t.Errorf("Expected 4 calls, Got %d", calls)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	t.Parallel()
+
+	handler := &flakyServer{failures: 1, status: http.StatusInternalServerError}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.Retry = testRetryPolicy()
+
+	if _, err = drycc.Request("POST", "/retry/", []byte("test")); !errors.Is(err, ErrServerError) {
+		t.Errorf("Expected %v, Got %v", ErrServerError, err)
+	}
+	if calls := handler.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 call, Got %d", calls)
+	}
+
+	drycc.Retry.RetryNonIdempotent = true
+	if _, err = drycc.Request("POST", "/retry/", []byte("test")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	handler := &flakyServer{failures: 1, status: http.StatusTooManyRequests, retryAfter: "1"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.Retry = testRetryPolicy()
+
+	var delay time.Duration
+	drycc.Retry.OnAttempt = func(a Attempt) {
+		if a.Retry {
+			delay = a.Delay
+		}
+	}
+
+	// The Retry-After delay is honored even though it is longer than MaxBackoff,
+	// so a short deadline must interrupt the wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err = drycc.RequestWithContext(ctx, "GET", "/retry/", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, Got %v", context.DeadlineExceeded, err)
+	}
+	if delay != time.Second {
+		t.Errorf("Expected %v, Got %v", time.Second, delay)
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	t.Parallel()
+
+	policy := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	for number, expected := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+	} {
+		if actual := policy.delay(number, nil); actual != expected {
+			t.Errorf("Expected %v for attempt %d, Got %v", expected, number, actual)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		if actual := policy.delay(1, nil); actual < 50*time.Millisecond || actual > 100*time.Millisecond {
+			t.Errorf("Expected a delay between 50ms and 100ms, Got %v", actual)
+		}
+	}
+}
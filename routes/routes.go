@@ -2,9 +2,11 @@
 package routes
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -12,8 +14,13 @@ import (
 
 // List routes registered with an app.
 func List(c *drycc.Client, appID string, results int) (api.Routes, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Routes, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/routes/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Route{}, -1, reqErr
@@ -27,8 +34,23 @@ func List(c *drycc.Client, appID string, results int) (api.Routes, int, error) {
 	return routes, count, reqErr
 }
 
+// All returns an iterator over all routes of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Route, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Route, error] {
+	return drycc.Paginate[api.Route](ctx, c, fmt.Sprintf("/v2/apps/%s/routes/", appID), drycc.DefaultPageSize)
+}
+
 // New adds a route to an app.
 func New(c *drycc.Client, appID, name, kind string, backendRefs ...api.BackendRefRequest) error {
+	return NewWithContext(context.Background(), c, appID, name, kind, backendRefs...)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID, name, kind string, backendRefs ...api.BackendRefRequest) error {
 	u := fmt.Sprintf("/v2/apps/%s/routes/", appID)
 
 	req := api.RouteCreateRequest{
@@ -42,7 +64,7 @@ func New(c *drycc.Client, appID, name, kind string, backendRefs ...api.BackendRe
 		return err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -52,6 +74,11 @@ func New(c *drycc.Client, appID, name, kind string, backendRefs ...api.BackendRe
 
 // AttachGateway route attach a gateway.
 func AttachGateway(c *drycc.Client, appID string, name string, port int, gateway string) error {
+	return AttachGatewayWithContext(context.Background(), c, appID, name, port, gateway)
+}
+
+// AttachGatewayWithContext is like [AttachGateway] but cancels the request when ctx is done.
+func AttachGatewayWithContext(ctx context.Context, c *drycc.Client, appID string, name string, port int, gateway string) error {
 	u := fmt.Sprintf("/v2/apps/%s/routes/%s/attach/", appID, name)
 
 	req := api.RouteAttachRequest{Port: port, Gateway: gateway}
@@ -61,7 +88,7 @@ func AttachGateway(c *drycc.Client, appID string, name string, port int, gateway
 		return err
 	}
 
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -72,6 +99,11 @@ func AttachGateway(c *drycc.Client, appID string, name string, port int, gateway
 
 // DetachGateway route attach a gateway.
 func DetachGateway(c *drycc.Client, appID string, name string, port int, gateway string) error {
+	return DetachGatewayWithContext(context.Background(), c, appID, name, port, gateway)
+}
+
+// DetachGatewayWithContext is like [DetachGateway] but cancels the request when ctx is done.
+func DetachGatewayWithContext(ctx context.Context, c *drycc.Client, appID string, name string, port int, gateway string) error {
 	u := fmt.Sprintf("/v2/apps/%s/routes/%s/detach/", appID, name)
 
 	req := api.RouteDetachRequest{Port: port, Gateway: gateway}
@@ -81,7 +113,7 @@ func DetachGateway(c *drycc.Client, appID string, name string, port int, gateway
 		return err
 	}
 
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -92,25 +124,38 @@ func DetachGateway(c *drycc.Client, appID string, name string, port int, gateway
 
 // GetRule gets info rule of a route from an app.
 func GetRule(c *drycc.Client, appID string, name string) (string, error) {
+	return GetRuleWithContext(context.Background(), c, appID, name)
+}
+
+// GetRuleWithContext is like [GetRule] but cancels the request when ctx is done.
+func GetRuleWithContext(ctx context.Context, c *drycc.Client, appID string, name string) (string, error) {
 	u := fmt.Sprintf("/v2/apps/%s/routes/%s/rules/", appID, name)
-	res, err := c.Request("GET", u, nil)
-	if err != nil {
-		return "", err
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
+	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
+		return "", reqErr
 	}
 	defer res.Body.Close()
 	respBytes, err := io.ReadAll(res.Body)
-	return string(respBytes), err
+	if err != nil {
+		return "", err
+	}
+	return string(respBytes), reqErr
 }
 
 // SetRule set rule of a route.
 func SetRule(c *drycc.Client, appID string, name string, rules string) error {
+	return SetRuleWithContext(context.Background(), c, appID, name, rules)
+}
+
+// SetRuleWithContext is like [SetRule] but cancels the request when ctx is done.
+func SetRuleWithContext(ctx context.Context, c *drycc.Client, appID string, name string, rules string) error {
 	u := fmt.Sprintf("/v2/apps/%s/routes/%s/rules/", appID, name)
 	body, err := json.Marshal(rules)
 	if err != nil {
 		return err
 	}
-	res, err := c.Request("PUT", u, body)
-	if err == nil {
+	res, err := c.RequestWithContext(ctx, "PUT", u, body)
+	if err == nil || drycc.IsErrAPIMismatch(err) {
 		res.Body.Close()
 	}
 	return err
@@ -118,8 +163,13 @@ func SetRule(c *drycc.Client, appID string, name string, rules string) error {
 
 // Delete Delete a route from an app.
 func Delete(c *drycc.Client, appID string, name string) error {
+	return DeleteWithContext(context.Background(), c, appID, name)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, name string) error {
 	u := fmt.Sprintf("/v2/apps/%s/routes/%s/", appID, name)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -2,6 +2,7 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,8 +13,13 @@ import (
 
 // List services registered with an app.
 func List(c *drycc.Client, appID string) (api.Services, error) {
+	return ListWithContext(context.Background(), c, appID)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string) (api.Services, error) {
 	u := fmt.Sprintf("/v2/apps/%s/services/", appID)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Service{}, reqErr
@@ -50,6 +56,11 @@ func List(c *drycc.Client, appID string) (api.Services, error) {
 // for more about Procfile see this https://devcenter.heroku.com/articles/procfile
 // Ptype and pathPattern are mandatory and should have valid values.
 func New(c *drycc.Client, appID string, Ptype string, port int, protocol string, targetPort int) error {
+	return NewWithContext(context.Background(), c, appID, Ptype, port, protocol, targetPort)
+}
+
+// NewWithContext is like [New] but cancels the request when ctx is done.
+func NewWithContext(ctx context.Context, c *drycc.Client, appID string, Ptype string, port int, protocol string, targetPort int) error {
 	u := fmt.Sprintf("/v2/apps/%s/services/", appID)
 
 	req := api.ServiceCreateUpdateRequest{Ptype: Ptype, Port: port, Protocol: protocol, TargetPort: targetPort}
@@ -59,7 +70,7 @@ func New(c *drycc.Client, appID string, Ptype string, port int, protocol string,
 		return err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return reqErr
 	}
@@ -70,6 +81,11 @@ func New(c *drycc.Client, appID string, Ptype string, port int, protocol string,
 // Delete service from app
 // If given service for the app doesn't exists then error returned
 func Delete(c *drycc.Client, appID string, Ptype string, protocol string, port int) error {
+	return DeleteWithContext(context.Background(), c, appID, Ptype, protocol, port)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, Ptype string, protocol string, port int) error {
 	u := fmt.Sprintf("/v2/apps/%s/services/", appID)
 
 	req := api.ServiceDeleteRequest{Ptype: Ptype, Protocol: protocol, Port: port}
@@ -79,7 +95,7 @@ func Delete(c *drycc.Client, appID string, Ptype string, protocol string, port i
 		return err
 	}
 
-	_, err = c.Request("DELETE", u, body)
+	_, err = c.RequestWithContext(ctx, "DELETE", u, body)
 
 	return err
 }
@@ -2,6 +2,7 @@
 package tls
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -11,9 +12,14 @@ import (
 
 // Info displays an app's tls config.
 func Info(c *drycc.Client, app string) (api.TLS, error) {
+	return InfoWithContext(context.Background(), c, app)
+}
+
+// InfoWithContext is like [Info] but cancels the request when ctx is done.
+func InfoWithContext(ctx context.Context, c *drycc.Client, app string) (api.TLS, error) {
 	u := fmt.Sprintf("/v2/apps/%s/tls/", app)
 
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil {
 		return api.TLS{}, reqErr
 	}
@@ -28,7 +34,7 @@ func Info(c *drycc.Client, app string) (api.TLS, error) {
 }
 
 // changeTLS enables the router to enforce https-only requests to the application.
-func changeTLS(c *drycc.Client, app string, httpsEnforced, certsAutoEnabled *bool, issuer *api.Issuer) (api.TLS, error) {
+func changeTLS(ctx context.Context, c *drycc.Client, app string, httpsEnforced, certsAutoEnabled *bool, issuer *api.Issuer) (api.TLS, error) {
 	t := api.NewTLS()
 	t.HTTPSEnforced = httpsEnforced
 	t.CertsAutoEnabled = certsAutoEnabled
@@ -40,7 +46,7 @@ func changeTLS(c *drycc.Client, app string, httpsEnforced, certsAutoEnabled *boo
 
 	u := fmt.Sprintf("/v2/apps/%s/tls/", app)
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil {
 		return api.TLS{}, reqErr
 	}
@@ -56,30 +62,55 @@ func changeTLS(c *drycc.Client, app string, httpsEnforced, certsAutoEnabled *boo
 
 // EnableHTTPSEnforced enables the router to enforce https-only requests to the application.
 func EnableHTTPSEnforced(c *drycc.Client, app string) (api.TLS, error) {
+	return EnableHTTPSEnforcedWithContext(context.Background(), c, app)
+}
+
+// EnableHTTPSEnforcedWithContext is like [EnableHTTPSEnforced] but cancels the request when ctx is done.
+func EnableHTTPSEnforcedWithContext(ctx context.Context, c *drycc.Client, app string) (api.TLS, error) {
 	b := true
-	return changeTLS(c, app, &b, nil, nil)
+	return changeTLS(ctx, c, app, &b, nil, nil)
 }
 
 // DisableHTTPSEnforced disables the router from enforcing https-only requests to the application.
 func DisableHTTPSEnforced(c *drycc.Client, app string) (api.TLS, error) {
+	return DisableHTTPSEnforcedWithContext(context.Background(), c, app)
+}
+
+// DisableHTTPSEnforcedWithContext is like [DisableHTTPSEnforced] but cancels the request when ctx is done.
+func DisableHTTPSEnforcedWithContext(ctx context.Context, c *drycc.Client, app string) (api.TLS, error) {
 	b := false
-	return changeTLS(c, app, &b, nil, nil)
+	return changeTLS(ctx, c, app, &b, nil, nil)
 }
 
 // EnableCertsAutoEnabled enables ACME to automatically generate certificates.
 func EnableCertsAutoEnabled(c *drycc.Client, app string) (api.TLS, error) {
+	return EnableCertsAutoEnabledWithContext(context.Background(), c, app)
+}
+
+// EnableCertsAutoEnabledWithContext is like [EnableCertsAutoEnabled] but cancels the request when ctx is done.
+func EnableCertsAutoEnabledWithContext(ctx context.Context, c *drycc.Client, app string) (api.TLS, error) {
 	b := true
-	return changeTLS(c, app, nil, &b, nil)
+	return changeTLS(ctx, c, app, nil, &b, nil)
 }
 
 // DisableCertsAutoEnabled disables ACME to automatically generate certificates.
 func DisableCertsAutoEnabled(c *drycc.Client, app string) (api.TLS, error) {
+	return DisableCertsAutoEnabledWithContext(context.Background(), c, app)
+}
+
+// DisableCertsAutoEnabledWithContext is like [DisableCertsAutoEnabled] but cancels the request when ctx is done.
+func DisableCertsAutoEnabledWithContext(ctx context.Context, c *drycc.Client, app string) (api.TLS, error) {
 	b := false
-	return changeTLS(c, app, nil, &b, nil)
+	return changeTLS(ctx, c, app, nil, &b, nil)
 }
 
 // AddCertsIssuer disables ACME to automatically generate certificates.
 func AddCertsIssuer(c *drycc.Client, app string, email string, server string, keyID string, keySecret string) (api.TLS, error) {
+	return AddCertsIssuerWithContext(context.Background(), c, app, email, server, keyID, keySecret)
+}
+
+// AddCertsIssuerWithContext is like [AddCertsIssuer] but cancels the request when ctx is done.
+func AddCertsIssuerWithContext(ctx context.Context, c *drycc.Client, app string, email string, server string, keyID string, keySecret string) (api.TLS, error) {
 	issuer := api.Issuer{Email: email, Server: server, KeyID: keyID, KeySecret: keySecret}
-	return changeTLS(c, app, nil, nil, &issuer)
+	return changeTLS(ctx, c, app, nil, nil, &issuer)
 }
@@ -2,8 +2,10 @@
 package tokens
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,7 +13,12 @@ import (
 
 // List tokens.
 func List(c *drycc.Client, results int) ([]api.Token, int, error) {
-	body, count, reqErr := c.LimitedRequest("/v2/tokens/", results)
+	return ListWithContext(context.Background(), c, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, results int) ([]api.Token, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, "/v2/tokens/", results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Token{}, -1, reqErr
@@ -25,10 +32,25 @@ func List(c *drycc.Client, results int) ([]api.Token, int, error) {
 	return tokens, count, reqErr
 }
 
+// All returns an iterator over all tokens of the user, fetching pages lazily.
+func All(c *drycc.Client) iter.Seq2[api.Token, error] {
+	return AllWithContext(context.Background(), c)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client) iter.Seq2[api.Token, error] {
+	return drycc.Paginate[api.Token](ctx, c, "/v2/tokens/", drycc.DefaultPageSize)
+}
+
 // Delete a token
 func Delete(c *drycc.Client, id string) error {
+	return DeleteWithContext(context.Background(), c, id)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, id string) error {
 	u := fmt.Sprintf("/v2/tokens/%s/", id)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
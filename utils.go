@@ -78,22 +78,25 @@ func ParseDryccfile(dryccpath string) (map[string]any, error) {
 }
 
 // CheckAPICompatibility checks if the server and client API versions are compatible.
+// They are compatible if they share the same major version and the server is not older
+// than the client.
 func CheckAPICompatibility(serverAPIVersion, clientAPIVersion string) error {
-	sVersion := strings.Split(serverAPIVersion, ".")
-	aVersion := strings.Split(clientAPIVersion, ".")
-
-	// If API Versions are invalid, return a mismatch error.
-	if len(sVersion) < 2 || len(aVersion) < 2 {
+	sVersion, err := ParseVersion(serverAPIVersion)
+	if err != nil {
+		return ErrAPIMismatch
+	}
+	aVersion, err := ParseVersion(clientAPIVersion)
+	if err != nil {
 		return ErrAPIMismatch
 	}
 
 	// If major versions are different, return a mismatch error.
-	if sVersion[0] != aVersion[0] {
+	if sVersion.Major != aVersion.Major {
 		return ErrAPIMismatch
 	}
 
 	// If server is older than client, return mismatch error.
-	if sVersion[1] < aVersion[1] {
+	if sVersion.Minor < aVersion.Minor {
 		return ErrAPIMismatch
 	}
 
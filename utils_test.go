@@ -19,6 +19,11 @@ func TestCheckAPIVersions(t *testing.T) {
 		{"2.1", "1.2", ErrAPIMismatch},
 		{"2.1", "2.2", ErrAPIMismatch},
 		{"2.3", "2.0", nil},
+		{"2.10", "2.3", nil},
+		{"2.3", "2.10", ErrAPIMismatch},
+		{"2.3.1", "2.3", nil},
+		{"", "2.3", ErrAPIMismatch},
+		{"two.three", "2.3", ErrAPIMismatch},
 	}
 
 	for _, check := range comparisons {
@@ -0,0 +1,182 @@
+package drycc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version, such as the API version of the controller.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses a version in the form major.minor[.patch], with an optional "v" prefix.
+// Pre-release and build suffixes of the patch number, such as "1-rc1", are ignored.
+func ParseVersion(s string) (Version, error) {
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	core, _, _ = strings.Cut(core, "+")
+	parts := strings.Split(core, ".")
+	if len(parts) == 3 {
+		parts[2], _, _ = strings.Cut(parts[2], "-")
+	} else if len(parts) > 3 {
+		// The pre-release suffix may itself contain dots, as in 1.0.2-rc.1.
+		if patch, _, ok := strings.Cut(parts[2], "-"); ok {
+			parts = append(parts[:2], patch)
+		}
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// MustParseVersion is like [ParseVersion] but panics if s is invalid.
+func MustParseVersion(s string) Version {
+	v, err := ParseVersion(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older than, equal to or newer than o.
+func (v Version) Compare(o Version) int {
+	switch {
+	case v.Major != o.Major:
+		return compareInt(v.Major, o.Major)
+	case v.Minor != o.Minor:
+		return compareInt(v.Minor, o.Minor)
+	default:
+		return compareInt(v.Patch, o.Patch)
+	}
+}
+
+// AtLeast returns true if v is equal to or newer than o.
+func (v Version) AtLeast(o Version) bool {
+	return v.Compare(o) >= 0
+}
+
+func (v Version) String() string {
+	if v.Patch == 0 {
+		return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MismatchPolicy determines what a client does when the API version of the controller
+// is not compatible with the SDK.
+type MismatchPolicy int
+
+const (
+	// MismatchError returns ErrAPIMismatch alongside the results of every call. This is the default.
+	MismatchError MismatchPolicy = iota
+	// MismatchWarn calls the client's OnAPIMismatch callback once per controller API version
+	// and returns no error.
+	MismatchWarn
+	// MismatchIgnore silently ignores API version mismatches.
+	MismatchIgnore
+)
+
+// Capabilities describes the controller a client talks to, as reported by its last response.
+type Capabilities struct {
+	// APIVersion is the API version of the controller. It is only meaningful if Known is true.
+	APIVersion Version
+	// PlatformVersion is the version of the drycc platform.
+	PlatformVersion string
+	// Known is true once a valid API version has been received from the controller,
+	// for example with [Client.CheckConnection] or [Client.Healthcheck].
+	Known bool
+}
+
+// Supports returns true if the controller is known to serve at least the given API version.
+func (c Capabilities) Supports(minimum Version) bool {
+	return c.Known && c.APIVersion.AtLeast(minimum)
+}
+
+// ErrUnsupportedAPIVersion is returned by [Client.RequireAPIVersion] when a feature requires a
+// newer controller API.
+type ErrUnsupportedAPIVersion struct {
+	// Feature is the name of the feature that was requested.
+	Feature string
+	// Required is the minimum API version of the feature.
+	Required Version
+	// Server is the API version of the controller.
+	Server Version
+}
+
+func (e ErrUnsupportedAPIVersion) Error() string {
+	return fmt.Sprintf("%s requires controller API >= %s, but the controller API is %s", e.Feature, e.Required, e.Server)
+}
+
+// Capabilities returns the capabilities of the controller recorded from the last response.
+func (c *Client) Capabilities() Capabilities {
+	caps := Capabilities{PlatformVersion: c.ServerVersion()}
+	if v, err := ParseVersion(c.ServerAPIVersion()); err == nil {
+		caps.APIVersion = v
+		caps.Known = true
+	}
+	return caps
+}
+
+// RequireAPIVersion returns an ErrUnsupportedAPIVersion if the controller is known to serve an
+// API older than minimum, which must be a valid version. Feature names the requested
+// functionality in the error. If the controller API version is not known yet, no error is
+// returned and the request is left to the controller.
+//
+// The SDK doesn't gate its own calls; callers can use it to fail fast before using a feature
+// they know requires a newer controller.
+func (c *Client) RequireAPIVersion(feature string, minimum string) error {
+	required := MustParseVersion(minimum)
+	caps := c.Capabilities()
+	if caps.Known && !caps.APIVersion.AtLeast(required) {
+		return ErrUnsupportedAPIVersion{Feature: feature, Required: required, Server: caps.APIVersion}
+	}
+	return nil
+}
+
+// checkAPIVersion applies the client's mismatch policy to the API version sent by the controller.
+func (c *Client) checkAPIVersion(serverAPIVersion string) error {
+	err := CheckAPICompatibility(serverAPIVersion, APIVersion)
+	if err == nil {
+		return nil
+	}
+
+	switch c.APIMismatchPolicy {
+	case MismatchIgnore:
+		return nil
+	case MismatchWarn:
+		c.mu.Lock()
+		warned := c.mismatchWarned && c.warnedAPIVersion == serverAPIVersion
+		c.mismatchWarned, c.warnedAPIVersion = true, serverAPIVersion
+		c.mu.Unlock()
+		if !warned && c.OnAPIMismatch != nil {
+			c.OnAPIMismatch(serverAPIVersion, APIVersion)
+		}
+		return nil
+	default:
+		return err
+	}
+}
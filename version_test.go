@@ -0,0 +1,143 @@
+package drycc
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	valid := map[string]Version{
+		"2.3":        {2, 3, 0},
+		"v2.10":      {2, 10, 0},
+		"2.3.4":      {2, 3, 4},
+		"1.0.2-rc.1": {1, 0, 2},
+		"1.0.2+abc":  {1, 0, 2},
+	}
+	for s, expected := range valid {
+		actual, err := ParseVersion(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if actual != expected {
+			t.Errorf("%s: Expected %v, Got %v", s, expected, actual)
+		}
+	}
+
+	for _, s := range []string{"", "2", "2.x", "2.3.4.5", "-1.2"} {
+		if _, err := ParseVersion(s); err == nil {
+			t.Errorf("%s: Expected an error", s)
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	t.Parallel()
+
+	comparisons := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.10", "2.3", 1},
+		{"2.3", "2.10", -1},
+		{"2.3", "2.3.0", 0},
+		{"3.0", "2.99", 1},
+		{"2.3.1", "2.3.2", -1},
+	}
+	for _, check := range comparisons {
+		if actual := MustParseVersion(check.a).Compare(MustParseVersion(check.b)); actual != check.expected {
+			t.Errorf("%s vs %s: Expected %d, Got %d", check.a, check.b, check.expected, actual)
+		}
+	}
+
+	if s := MustParseVersion("v2.3.1").String(); s != "2.3.1" {
+		t.Errorf("Expected %s, Got %s", "2.3.1", s)
+	}
+}
+
+func TestMismatchPolicy(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{Version: "3.0", PlatformVersion: "v9000"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var warnings []string
+	drycc, err := NewClient(server.URL, WithUserAgent("test"), WithAPIMismatchPolicy(MismatchWarn,
+		func(serverAPIVersion, clientAPIVersion string) {
+			warnings = append(warnings, serverAPIVersion+"/"+clientAPIVersion)
+		},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if err = drycc.Healthcheck(); err != nil {
+			t.Error(err)
+		}
+	}
+	if len(warnings) != 1 || warnings[0] != "3.0/"+APIVersion {
+		t.Errorf("Expected a single warning, Got %v", warnings)
+	}
+
+	drycc.APIMismatchPolicy = MismatchIgnore
+	if err = drycc.CheckConnection(); err != nil {
+		t.Error(err)
+	}
+
+	drycc.APIMismatchPolicy = MismatchError
+	if err = drycc.CheckConnection(); !errors.Is(err, ErrAPIMismatch) {
+		t.Errorf("Expected %v, Got %v", ErrAPIMismatch, err)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	t.Parallel()
+
+	handler := fakeHTTPServer{Version: "2.5", PlatformVersion: "v9000"}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	drycc, err := New(false, server.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	drycc.UserAgent = "test"
+
+	if caps := drycc.Capabilities(); caps.Known {
+		t.Errorf("Expected unknown capabilities, Got %v", caps)
+	}
+	if err = drycc.RequireAPIVersion("testing", "2.9"); err != nil {
+		t.Errorf("Expected no error before the version is known, Got %v", err)
+	}
+
+	if err = drycc.CheckConnection(); err != nil {
+		t.Fatal(err)
+	}
+
+	caps := drycc.Capabilities()
+	if !caps.Known || caps.APIVersion != (Version{2, 5, 0}) || caps.PlatformVersion != "v9000" {
+		t.Errorf("Unexpected capabilities %v", caps)
+	}
+	if !caps.Supports(MustParseVersion("2.4")) || caps.Supports(MustParseVersion("2.6")) {
+		t.Errorf("Unexpected support for %v", caps.APIVersion)
+	}
+
+	if err = drycc.RequireAPIVersion("testing", "2.5"); err != nil {
+		t.Error(err)
+	}
+
+	err = drycc.RequireAPIVersion("testing", "2.10")
+	var unsupported ErrUnsupportedAPIVersion
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Expected ErrUnsupportedAPIVersion, Got %v", err)
+	}
+	expected := "testing requires controller API >= 2.10, but the controller API is 2.5"
+	if err.Error() != expected {
+		t.Errorf("Expected %s, Got %s", expected, err.Error())
+	}
+}
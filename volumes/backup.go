@@ -0,0 +1,509 @@
+package volumes
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// ArchiveFormat is the format of a backup archive.
+type ArchiveFormat string
+
+// The formats of backup archives.
+const (
+	FormatTarGz ArchiveFormat = "tar.gz"
+	FormatZip   ArchiveFormat = "zip"
+)
+
+// The names of the entries of backup archives. The files of the volume are stored under
+// archiveData, and the manifest is the last entry.
+const (
+	archiveData     = "data/"
+	archiveManifest = "manifest.json"
+)
+
+// cleanupTimeout bounds the removal of the temporary file of a failed restore.
+const cleanupTimeout = 30 * time.Second
+
+// Manifest describes the content of a backup archive.
+type Manifest struct {
+	App     string          `json:"app"`
+	Volume  string          `json:"volume"`
+	Created time.Time       `json:"created"`
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry is a file or a directory of a backup archive.
+type ManifestEntry struct {
+	// Path is the path of the entry in the volume, such as "/static/app.js".
+	Path string `json:"path"`
+	// Type is FilerFile or FilerDir.
+	Type string `json:"type"`
+	// Size and SHA256 are the size and the hex-encoded SHA-256 checksum of files.
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	// Modified is when the entry was last modified in the volume.
+	Modified time.Time `json:"modified,omitzero"`
+}
+
+// BackupOptions configures [Filer.Backup].
+type BackupOptions struct {
+	// Format is the format of the archive. Defaults to FormatTarGz.
+	Format ArchiveFormat
+	// Progress, if not nil, is called as the files of the volume are downloaded.
+	Progress func(TransferProgress)
+}
+
+// Backup writes the tree of the volume to w as an archive, with a manifest of its entries,
+// and returns the manifest. The files are streamed to the archive. The filer must be bound
+// for the backup, such as with a [ServeSession].
+func (f *Filer) Backup(ctx context.Context, w io.Writer, opts BackupOptions) (*Manifest, error) {
+	var archive archiveWriter
+	switch opts.Format {
+	case FormatTarGz, "":
+		archive = newTarGzWriter(w)
+	case FormatZip:
+		archive = &zipWriter{w: zip.NewWriter(w)}
+	default:
+		return nil, fmt.Errorf("unsupported archive format %q", opts.Format)
+	}
+
+	m := &Manifest{App: f.appID, Volume: f.name, Created: time.Now().UTC()}
+	var total int64
+	err := f.walk(ctx, "/", func(entry api.FilerDirEntry) error {
+		e := ManifestEntry{Path: entry.Path, Type: entry.Type}
+		e.Modified, _ = time.Parse(time.RFC3339, entry.Timestamp)
+		if entry.Type == FilerDir {
+			m.Entries = append(m.Entries, e)
+			return archive.dir(archiveData+entry.Path[1:]+"/", e.Modified)
+		}
+		e.Size, _ = strconv.ParseInt(entry.Size, 10, 64)
+		dst, err := archive.file(archiveData+entry.Path[1:], e.Size, e.Modified)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		n, err := f.download(ctx, entry.Path, io.MultiWriter(dst, h), entry.Path, opts.Progress, &total)
+		if err != nil {
+			return err
+		}
+		if n != e.Size {
+			return fmt.Errorf("backup %s: size changed from %d to %d bytes during the backup", entry.Path, e.Size, n)
+		}
+		e.SHA256 = hex.EncodeToString(h.Sum(nil))
+		m.Entries = append(m.Entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	dst, err := archive.file(archiveManifest, int64(len(data)), m.Created)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(data); err != nil {
+		return nil, err
+	}
+	if err := archive.close(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// archiveWriter writes the entries of a backup archive.
+type archiveWriter interface {
+	dir(name string, modified time.Time) error
+	file(name string, size int64, modified time.Time) (io.Writer, error)
+	close() error
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzWriter(w io.Writer) *tarGzWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (a *tarGzWriter) dir(name string, modified time.Time) error {
+	return a.tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0o755, ModTime: modified})
+}
+
+func (a *tarGzWriter) file(name string, size int64, modified time.Time) (io.Writer, error) {
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: size, Mode: 0o644, ModTime: modified}
+	return a.tw, a.tw.WriteHeader(hdr)
+}
+
+func (a *tarGzWriter) close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+type zipWriter struct {
+	w *zip.Writer
+}
+
+func (a *zipWriter) dir(name string, modified time.Time) error {
+	_, err := a.w.CreateHeader(&zip.FileHeader{Name: name, Modified: modified})
+	return err
+}
+
+func (a *zipWriter) file(name string, _ int64, modified time.Time) (io.Writer, error) {
+	return a.w.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+}
+
+func (a *zipWriter) close() error {
+	return a.w.Close()
+}
+
+// ConflictPolicy is how [Filer.Restore] handles the files of an archive that exist in the volume.
+type ConflictPolicy int
+
+const (
+	// ConflictFail fails the restore, before anything is uploaded.
+	ConflictFail ConflictPolicy = iota
+	// ConflictSkip keeps the existing files.
+	ConflictSkip
+	// ConflictOverwrite replaces the existing files.
+	ConflictOverwrite
+)
+
+// The actions of a restore.
+const (
+	RestoreCreate    = "create"
+	RestoreOverwrite = "overwrite"
+	RestoreSkip      = "skip"
+)
+
+// RestoreOptions configures [Filer.Restore].
+type RestoreOptions struct {
+	// Conflict is how the files that exist in the volume are handled.
+	Conflict ConflictPolicy
+	// DryRun verifies the archive and plans the restore without changing the volume.
+	DryRun bool
+	// Progress, if not nil, is called as the files of the archive are uploaded.
+	Progress func(TransferProgress)
+}
+
+// RestoredEntry is an entry of an archive, with the action of the restore.
+type RestoredEntry struct {
+	ManifestEntry
+	Action string
+}
+
+// RestoreResult is the result of [Filer.Restore].
+type RestoreResult struct {
+	Manifest *Manifest
+	// Entries are the entries of the archive, in the order of the manifest.
+	Entries []RestoredEntry
+}
+
+// ChecksumError is returned by [Filer.Restore] when the content of a file of an archive doesn't
+// match its manifest.
+type ChecksumError struct {
+	Path     string
+	Expected string
+	Got      string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum of %s: expected sha256 %s, got %s", e.Path, e.Expected, e.Got)
+}
+
+// Restore restores an archive written by [Filer.Backup] to the volume, such as to another
+// volume or app. The existing files of the volume that aren't in the archive are kept.
+//
+// Each file is uploaded to a temporary file next to it, and moved in place once its
+// checksum is verified, so a corrupt file of the archive doesn't replace the existing one.
+// The filer must be bound for the restore, such as with a [ServeSession].
+func (f *Filer) Restore(ctx context.Context, archivePath string, opts RestoreOptions) (*RestoreResult, error) {
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.close()
+	m, err := archive.manifest()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", archivePath, err)
+	}
+
+	existing := map[string]api.FilerDirEntry{}
+	if err := f.walk(ctx, "/", func(entry api.FilerDirEntry) error {
+		existing[entry.Path] = entry
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	result := &RestoreResult{Manifest: m}
+	planned := map[string]*RestoredEntry{}
+	var conflicts []string
+	for _, e := range m.Entries {
+		if e.Path != cleanPath(e.Path) || e.Path == "/" {
+			return nil, fmt.Errorf("%s: invalid path %q in manifest", archivePath, e.Path)
+		}
+		r := RestoredEntry{ManifestEntry: e, Action: RestoreCreate}
+		if current, ok := existing[e.Path]; ok {
+			switch {
+			case e.Type == FilerDir && current.Type == FilerDir:
+				r.Action = RestoreSkip
+			case opts.Conflict == ConflictSkip:
+				r.Action = RestoreSkip
+			case opts.Conflict == ConflictOverwrite && e.Type == FilerFile && current.Type == FilerFile:
+				r.Action = RestoreOverwrite
+			default:
+				conflicts = append(conflicts, e.Path)
+			}
+		}
+		result.Entries = append(result.Entries, r)
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("restore of %s: %d entries exist in the volume: %s", archivePath, len(conflicts), strings.Join(conflicts, ", "))
+	}
+	for i := range result.Entries {
+		planned[result.Entries[i].Path] = &result.Entries[i]
+	}
+
+	var total int64
+	restored := map[string]bool{}
+	err = archive.walk(func(hdr archiveEntry, r io.Reader) error {
+		if hdr.name == archiveManifest {
+			return nil
+		}
+		p := cleanPath(strings.TrimPrefix(strings.TrimSuffix(hdr.name, "/"), archiveData))
+		e, ok := planned[p]
+		if !ok || !strings.HasPrefix(hdr.name, archiveData) || (e.Type == FilerDir) != hdr.dir {
+			return fmt.Errorf("%s: entry %s is not in the manifest", archivePath, hdr.name)
+		}
+		restored[p] = true
+		switch {
+		case e.Type == FilerDir:
+			if opts.DryRun || e.Action == RestoreSkip {
+				return nil
+			}
+			return f.MkdirAll(ctx, p)
+		case e.Action == RestoreSkip:
+			return nil
+		case opts.DryRun:
+			h := sha256.New()
+			n, err := io.Copy(h, r)
+			if err != nil {
+				return err
+			}
+			return verify(e.ManifestEntry, n, h.Sum(nil))
+		}
+		return restoreFile(ctx, f, e.ManifestEntry, r, opts.Progress, &total)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range m.Entries {
+		if !restored[e.Path] {
+			return nil, fmt.Errorf("%s: entry %s of the manifest is missing", archivePath, e.Path)
+		}
+	}
+	return result, nil
+}
+
+// restoreFile uploads a file of an archive to a temporary file, and moves it in place once
+// its checksum is verified.
+func restoreFile(ctx context.Context, f *Filer, e ManifestEntry, r io.Reader, progress func(TransferProgress), total *int64) error {
+	if err := f.MkdirAll(ctx, path.Dir(e.Path)); err != nil {
+		return err
+	}
+	tmp := path.Join(path.Dir(e.Path), "."+path.Base(e.Path)+".restore")
+	h := sha256.New()
+	counter := &countingReader{r: io.TeeReader(r, h)}
+	if err := f.upload(ctx, tmp, counter, e.Size, progress, total); err != nil {
+		return errors.Join(err, removeTemp(ctx, f, tmp))
+	}
+	if err := verify(e, counter.n, h.Sum(nil)); err != nil {
+		return errors.Join(err, removeTemp(ctx, f, tmp))
+	}
+	return f.Move(ctx, tmp, e.Path, true)
+}
+
+// removeTemp deletes the temporary file of a failed restore, even if ctx is done. The file
+// doesn't exist if the upload failed before it was created.
+func removeTemp(ctx context.Context, f *Filer, tmp string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	if err := f.Delete(ctx, tmp); err != nil && !errors.As(err, new(drycc.ErrNotFound)) {
+		return err
+	}
+	return nil
+}
+
+// verify checks the size and the checksum of the content of a file against its manifest.
+func verify(e ManifestEntry, size int64, sum []byte) error {
+	if got := hex.EncodeToString(sum); size != e.Size || got != e.SHA256 {
+		return &ChecksumError{Path: e.Path, Expected: e.SHA256, Got: got}
+	}
+	return nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// archiveEntry is the header of an entry of an archive.
+type archiveEntry struct {
+	name string
+	dir  bool
+}
+
+// archiveReader reads a backup archive.
+type archiveReader interface {
+	manifest() (*Manifest, error)
+	walk(fn func(archiveEntry, io.Reader) error) error
+	close() error
+}
+
+// openArchive opens a backup archive, detecting its format.
+func openArchive(name string) (archiveReader, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	magic, err := bufio.NewReader(file).Peek(4)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	switch {
+	case bytes.Equal(magic, []byte("PK\x03\x04")):
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		r, err := zip.NewReader(file, info.Size())
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return &zipReader{file: file, r: r}, nil
+	case bytes.Equal(magic[:2], []byte{0x1f, 0x8b}):
+		return &tarGzReader{file: file}, nil
+	}
+	file.Close()
+	return nil, fmt.Errorf("%s: unsupported archive format", name)
+}
+
+// tarGzReader reads a tar.gz archive. Its manifest is read with a first pass over the
+// archive, as it's the last entry.
+type tarGzReader struct {
+	file *os.File
+}
+
+func (a *tarGzReader) walk(fn func(archiveEntry, io.Reader) error) error {
+	if _, err := a.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(a.file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+			return fmt.Errorf("unsupported entry %s", hdr.Name)
+		}
+		if err := fn(archiveEntry{name: hdr.Name, dir: hdr.Typeflag == tar.TypeDir}, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (a *tarGzReader) manifest() (*Manifest, error) {
+	var m *Manifest
+	err := a.walk(func(e archiveEntry, r io.Reader) error {
+		if e.name != archiveManifest {
+			return nil
+		}
+		m = new(Manifest)
+		return json.NewDecoder(r).Decode(m)
+	})
+	if err == nil && m == nil {
+		err = errors.New("no manifest in archive")
+	}
+	return m, err
+}
+
+func (a *tarGzReader) close() error {
+	return a.file.Close()
+}
+
+type zipReader struct {
+	file *os.File
+	r    *zip.Reader
+}
+
+func (a *zipReader) walk(fn func(archiveEntry, io.Reader) error) error {
+	for _, zf := range a.r.File {
+		r, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		err = fn(archiveEntry{name: zf.Name, dir: strings.HasSuffix(zf.Name, "/")}, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *zipReader) manifest() (*Manifest, error) {
+	r, err := a.r.Open(archiveManifest)
+	if err != nil {
+		return nil, errors.New("no manifest in archive")
+	}
+	defer r.Close()
+	m := new(Manifest)
+	return m, json.NewDecoder(r).Decode(m)
+}
+
+func (a *zipReader) close() error {
+	return a.file.Close()
+}
@@ -0,0 +1,248 @@
+package volumes
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+// readVolume returns the content of the files of a volume of the server.
+func readVolume(t *testing.T, srv *drycctest.Server, volume string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := fs.WalkDir(NewFS(context.Background(), NewFiler(srv.Client(), "example-go", volume), FSOptions{}), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		file, err := srv.FilerFS("example-go", volume).OpenFile(context.Background(), "/"+p, os.O_RDONLY, 0)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		files[p] = string(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestBackupRestore(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	srv.AddVolume("example-go", api.Volume{Name: "restored", Size: "500G"})
+	ctx := context.Background()
+	client := srv.Client()
+	f := NewFiler(client, "example-go", "myvolume")
+	restored := NewFiler(client, "example-go", "restored")
+	files := map[string]string{
+		"models/model.bin": strings.Repeat("weights", 100),
+		"models/README":    "v1",
+		"config.yaml":      "debug: false",
+	}
+	for name, content := range files {
+		if err := f.MkdirAll(ctx, path.Dir(name)); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Upload(ctx, name, strings.NewReader(content), int64(len(content)), TransferOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Mkdir(ctx, "empty"); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := NewServeSession(ctx, client, "example-go", "myvolume", ServeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	for _, format := range []ArchiveFormat{FormatTarGz, FormatZip} {
+		archive := filepath.Join(t.TempDir(), "backup."+string(format))
+		out, err := os.Create(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := f.Backup(ctx, out, BackupOptions{Format: format})
+		out.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, e := range m.Entries {
+			paths = append(paths, e.Type+" "+e.Path)
+		}
+		expected := []string{"file /config.yaml", "dir /empty", "dir /models", "file /models/README", "file /models/model.bin"}
+		if !reflect.DeepEqual(expected, paths) {
+			t.Errorf("Expected %v, Got %v", expected, paths)
+		}
+		if e := m.Entries[0]; e.Size != 12 || len(e.SHA256) != 64 || e.Modified.IsZero() {
+			t.Errorf("Expected the size, checksum and timestamp of the file, Got %+v", e)
+		}
+
+		result, err := restored.Restore(ctx, archive, RestoreOptions{Conflict: ConflictSkip})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(files, readVolume(t, srv, "restored")) {
+			t.Errorf("%s: Expected %v, Got %v", format, files, readVolume(t, srv, "restored"))
+		}
+		if _, err := srv.FilerFS("example-go", "restored").Stat(ctx, "/empty"); err != nil {
+			t.Errorf("%s: Expected the empty directory to be restored, Got %v", format, err)
+		}
+		if len(result.Entries) != len(m.Entries) {
+			t.Errorf("%s: Expected %d entries, Got %v", format, len(m.Entries), result.Entries)
+		}
+	}
+	if !srv.FilerBound("example-go", "myvolume") {
+		t.Error("Expected the binding of the session to be kept")
+	}
+
+	// The files of the archive now exist in the restored volume.
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	out, _ := os.Create(archive)
+	if _, err := f.Backup(ctx, out, BackupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+	if _, err := restored.Restore(ctx, archive, RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "/config.yaml") {
+		t.Errorf("Expected the existing files to conflict, Got %v", err)
+	}
+	if err := f.Upload(ctx, "config.yaml", strings.NewReader("debug: true"), -1, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := f.Restore(ctx, archive, RestoreOptions{Conflict: ConflictOverwrite, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := result.Entries[0]; e.Path != "/config.yaml" || e.Action != RestoreOverwrite {
+		t.Errorf("Expected the file to be overwritten, Got %+v", e)
+	}
+	if files := readVolume(t, srv, "myvolume"); files["config.yaml"] != "debug: true" {
+		t.Errorf("Expected no change in a dry run, Got %q", files["config.yaml"])
+	}
+	var total int64
+	opts := RestoreOptions{Conflict: ConflictOverwrite, Progress: func(p TransferProgress) { total = p.Total }}
+	if _, err := f.Restore(ctx, archive, opts); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, readVolume(t, srv, "myvolume")) {
+		t.Errorf("Expected %v, Got %v", files, readVolume(t, srv, "myvolume"))
+	}
+	if total != 714 {
+		t.Errorf("Expected 714 bytes to be uploaded, Got %d", total)
+	}
+}
+
+func TestRestoreChecksum(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+	if err := f.Upload(ctx, "data.txt", strings.NewReader("original"), 8, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "corrupt.tar.gz")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "data/data.txt", Size: 9, Mode: 0o644})
+	io.WriteString(tw, "corrupted")
+	manifest, _ := json.Marshal(Manifest{Entries: []ManifestEntry{
+		{Path: "/data.txt", Type: FilerFile, Size: 9, SHA256: strings.Repeat("0", 64)},
+	}})
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifest.json", Size: int64(len(manifest)), Mode: 0o644})
+	tw.Write(manifest)
+	tw.Close()
+	gz.Close()
+	out.Close()
+
+	for _, dryRun := range []bool{true, false} {
+		_, err = f.Restore(ctx, archive, RestoreOptions{Conflict: ConflictOverwrite, DryRun: dryRun})
+		var checksumErr *ChecksumError
+		if !errors.As(err, &checksumErr) || checksumErr.Path != "/data.txt" {
+			t.Errorf("Expected a checksum error, Got %v", err)
+		}
+	}
+	if files := readVolume(t, srv, "myvolume"); !reflect.DeepEqual(files, map[string]string{"data.txt": "original"}) {
+		t.Errorf("Expected the file to be kept, Got %v", files)
+	}
+}
+
+func TestRestoreUploadFailure(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+	// The temporary file left by an upload interrupted midway.
+	if err := f.Upload(ctx, ".data.txt.restore", strings.NewReader("part"), 4, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "data/data.txt", Size: 8, Mode: 0o644})
+	io.WriteString(tw, "restored")
+	sum := sha256.Sum256([]byte("restored"))
+	manifest, _ := json.Marshal(Manifest{Entries: []ManifestEntry{
+		{Path: "/data.txt", Type: FilerFile, Size: 8, SHA256: hex.EncodeToString(sum[:])},
+	}})
+	tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "manifest.json", Size: int64(len(manifest)), Mode: 0o644})
+	tw.Write(manifest)
+	tw.Close()
+	gz.Close()
+	out.Close()
+
+	// The second restore fails before the temporary file is created.
+	srv.Fail(drycctest.Failure{
+		Method:     http.MethodPut,
+		Path:       "/v2/apps/example-go/volumes/myvolume/filer/webdav/.data.txt.restore",
+		StatusCode: http.StatusInsufficientStorage,
+		Times:      2,
+	})
+	for range 2 {
+		_, err = f.Restore(ctx, archive, RestoreOptions{})
+		var apiErr *drycc.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInsufficientStorage || errors.As(err, new(drycc.ErrNotFound)) {
+			t.Errorf("Expected the error of the upload, Got %v", err)
+		}
+		if files := readVolume(t, srv, "myvolume"); len(files) != 0 {
+			t.Errorf("Expected the temporary file to be deleted, Got %v", files)
+		}
+	}
+}
@@ -0,0 +1,448 @@
+package volumes
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// The types of filer entries.
+const (
+	FilerFile = "file"
+	FilerDir  = "dir"
+)
+
+// propfindBody requests the properties of filer entries.
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:resourcetype/><D:getcontentlength/><D:getlastmodified/></D:prop></D:propfind>`
+
+// TransferProgress reports the progress of an upload or a download.
+type TransferProgress struct {
+	// Path is the path of the file being transferred: the remote path for uploads and the
+	// local path for downloads.
+	Path string
+	// Bytes is the number of bytes of the file transferred so far, out of Size. Size is -1
+	// if unknown.
+	Bytes int64
+	Size  int64
+	// Total is the number of bytes of all the files transferred so far.
+	Total int64
+}
+
+// TransferOptions configures the uploads and downloads of a [Filer].
+type TransferOptions struct {
+	// Progress, if not nil, is called as the content of the files is transferred.
+	Progress func(TransferProgress)
+}
+
+// Filer is a client of the WebDAV filer of a volume. The filer must be bound with [Serve]
+// while it's used. Paths are slash-separated and relative to the root of the volume;
+// requests are authenticated like the other requests of the client.
+type Filer struct {
+	c     *drycc.Client
+	appID string
+	name  string
+	base  string
+}
+
+// NewFiler returns a client of the WebDAV filer of an app's volume.
+func NewFiler(c *drycc.Client, appID, name string) *Filer {
+	return &Filer{c: c, appID: appID, name: name, base: fmt.Sprintf("/v2/apps/%s/volumes/%s/filer/webdav", appID, name)}
+}
+
+// url returns the URL of a path of the volume.
+func (f *Filer) url(p string) string {
+	u := *f.c.ControllerURL
+	u.Path = f.base + cleanPath(p)
+	u.RawQuery = ""
+	return u.String()
+}
+
+// cleanPath returns the absolute form of a path of the volume.
+func cleanPath(p string) string {
+	return path.Clean("/" + p)
+}
+
+func (f *Filer) do(ctx context.Context, method, p string, body io.Reader, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, f.url(p), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := f.c.Do(req)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return nil, fmt.Errorf("%s %s: %w", strings.ToLower(method), cleanPath(p), err)
+	}
+	return res, nil
+}
+
+// multistatus is the response of a PROPFIND request.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+				ContentLength string `xml:"DAV: getcontentlength"`
+				LastModified  string `xml:"DAV: getlastmodified"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// propfind returns the entries of a path, and of its children if depth is 1.
+func (f *Filer) propfind(ctx context.Context, p string, depth int) (api.FilerDirEntries, error) {
+	header := http.Header{
+		"Depth":        {strconv.Itoa(depth)},
+		"Content-Type": {"application/xml; charset=utf-8"},
+	}
+	res, err := f.do(ctx, "PROPFIND", p, strings.NewReader(propfindBody), header)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var ms multistatus
+	if err := xml.NewDecoder(res.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("propfind %s: %w", cleanPath(p), err)
+	}
+
+	prefix := (&url.URL{Path: f.base}).EscapedPath()
+	var entries api.FilerDirEntries
+	for _, r := range ms.Responses {
+		href := r.Href
+		if u, err := url.Parse(href); err == nil {
+			href = u.EscapedPath()
+		}
+		name, err := url.PathUnescape(strings.TrimPrefix(href, prefix))
+		if err != nil {
+			return nil, fmt.Errorf("propfind %s: invalid href %s", cleanPath(p), r.Href)
+		}
+		entry := api.FilerDirEntry{Path: cleanPath(name), Type: FilerFile}
+		entry.Name = path.Base(entry.Path)
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			if ps.Prop.ResourceType.Collection != nil {
+				entry.Type = FilerDir
+			}
+			if ps.Prop.ContentLength != "" {
+				entry.Size = ps.Prop.ContentLength
+			}
+			if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+				entry.Timestamp = t.UTC().Format(time.RFC3339)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// List lists the entries of a directory of the volume, sorted by name.
+func (f *Filer) List(ctx context.Context, dir string) (api.FilerDirEntries, error) {
+	entries, err := f.propfind(ctx, dir, 1)
+	if err != nil {
+		return nil, err
+	}
+	dir = cleanPath(dir)
+	children := api.FilerDirEntries{}
+	for _, entry := range entries {
+		if entry.Path != dir {
+			children = append(children, entry)
+		}
+	}
+	sortEntries(children)
+	return children, nil
+}
+
+// sortEntries sorts filer entries by name.
+func sortEntries(entries api.FilerDirEntries) {
+	slices.SortFunc(entries, func(a, b api.FilerDirEntry) int { return strings.Compare(a.Name, b.Name) })
+}
+
+// Stat returns the entry of a file or directory of the volume.
+func (f *Filer) Stat(ctx context.Context, p string) (api.FilerDirEntry, error) {
+	entries, err := f.propfind(ctx, p, 0)
+	if err != nil {
+		return api.FilerDirEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.Path == cleanPath(p) {
+			return entry, nil
+		}
+	}
+	return api.FilerDirEntry{}, fmt.Errorf("propfind %s: no entry in response", cleanPath(p))
+}
+
+// Mkdir creates a directory of the volume, whose parent must exist.
+func (f *Filer) Mkdir(ctx context.Context, dir string) error {
+	res, err := f.do(ctx, "MKCOL", dir, nil, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// MkdirAll creates a directory of the volume and its missing parents.
+func (f *Filer) MkdirAll(ctx context.Context, dir string) error {
+	dir = cleanPath(dir)
+	if dir == "/" {
+		return nil
+	}
+	if entry, err := f.Stat(ctx, dir); err == nil {
+		if entry.Type != FilerDir {
+			return fmt.Errorf("mkdir %s: not a directory", dir)
+		}
+		return nil
+	} else if !errors.As(err, new(drycc.ErrNotFound)) {
+		return err
+	}
+	if err := f.MkdirAll(ctx, path.Dir(dir)); err != nil {
+		return err
+	}
+	err := f.Mkdir(ctx, dir)
+	if errors.Is(err, drycc.ErrMethodNotAllowed) {
+		// The directory was created concurrently.
+		return nil
+	}
+	return err
+}
+
+// Upload writes the content of r to a file of the volume, streaming it. size is the size
+// of the content, or -1 if unknown.
+func (f *Filer) Upload(ctx context.Context, p string, r io.Reader, size int64, opts TransferOptions) error {
+	var total int64
+	return f.upload(ctx, p, r, size, opts.Progress, &total)
+}
+
+func (f *Filer) upload(ctx context.Context, p string, r io.Reader, size int64, progress func(TransferProgress), total *int64) error {
+	body := r
+	if progress != nil {
+		body = &progressReader{r: r, path: cleanPath(p), size: size, total: total, progress: progress}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, f.url(p), io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	res, err := f.c.Do(req)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return fmt.Errorf("put %s: %w", cleanPath(p), err)
+	}
+	return res.Body.Close()
+}
+
+// Open opens a file of the volume for reading, streaming its content. The caller must
+// close the returned reader.
+func (f *Filer) Open(ctx context.Context, p string) (io.ReadCloser, error) {
+	return f.openAt(ctx, p, 0)
+}
+
+// openAt opens a file of the volume for reading from an offset.
+func (f *Filer) openAt(ctx context.Context, p string, offset int64) (io.ReadCloser, error) {
+	var header http.Header
+	if offset > 0 {
+		header = http.Header{"Range": {fmt.Sprintf("bytes=%d-", offset)}}
+	}
+	res, err := f.do(ctx, http.MethodGet, p, nil, header)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 && res.StatusCode != http.StatusPartialContent {
+		// The range was ignored, skip to the offset.
+		if _, err := io.CopyN(io.Discard, res.Body, offset); err != nil {
+			res.Body.Close()
+			return nil, fmt.Errorf("get %s: %w", cleanPath(p), err)
+		}
+	}
+	return res.Body, nil
+}
+
+// Download writes the content of a file of the volume to w, and returns the number of
+// bytes written.
+func (f *Filer) Download(ctx context.Context, p string, w io.Writer, opts TransferOptions) (int64, error) {
+	var total int64
+	return f.download(ctx, p, w, cleanPath(p), opts.Progress, &total)
+}
+
+func (f *Filer) download(ctx context.Context, p string, w io.Writer, name string, progress func(TransferProgress), total *int64) (int64, error) {
+	res, err := f.do(ctx, http.MethodGet, p, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	var body io.Reader = res.Body
+	if progress != nil {
+		body = &progressReader{r: res.Body, path: name, size: res.ContentLength, total: total, progress: progress}
+	}
+	return io.Copy(w, body)
+}
+
+// Delete deletes a file or a directory of the volume, with its content.
+func (f *Filer) Delete(ctx context.Context, p string) error {
+	res, err := f.do(ctx, http.MethodDelete, p, nil, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Move moves a file or a directory of the volume. If overwrite is false, moving to an
+// existing path fails.
+func (f *Filer) Move(ctx context.Context, from, to string, overwrite bool) error {
+	header := http.Header{"Destination": {f.url(to)}, "Overwrite": {"F"}}
+	if overwrite {
+		header.Set("Overwrite", "T")
+	}
+	res, err := f.do(ctx, "MOVE", from, nil, header)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// UploadDir uploads a local directory tree to a directory of the volume, which is created
+// if needed. Existing files are overwritten; symbolic links and special files are skipped.
+func (f *Filer) UploadDir(ctx context.Context, localDir, dir string, opts TransferOptions) error {
+	if err := f.MkdirAll(ctx, dir); err != nil {
+		return err
+	}
+	var total int64
+	return filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		remote := path.Join(cleanPath(dir), filepath.ToSlash(rel))
+		switch {
+		case d.IsDir():
+			err := f.Mkdir(ctx, remote)
+			if errors.Is(err, drycc.ErrMethodNotAllowed) {
+				return nil
+			}
+			return err
+		case !d.Type().IsRegular():
+			return nil
+		}
+		return f.uploadFile(ctx, p, remote, opts.Progress, &total)
+	})
+}
+
+// DownloadDir downloads a directory tree of the volume to a local directory, which is
+// created if needed. Existing files are overwritten.
+func (f *Filer) DownloadDir(ctx context.Context, dir, localDir string, opts TransferOptions) error {
+	var total int64
+	return f.downloadDir(ctx, cleanPath(dir), localDir, opts.Progress, &total)
+}
+
+func (f *Filer) downloadDir(ctx context.Context, dir, localDir string, progress func(TransferProgress), total *int64) error {
+	if err := os.MkdirAll(localDir, 0o755); err != nil {
+		return err
+	}
+	entries, err := f.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !filepath.IsLocal(entry.Name) {
+			return fmt.Errorf("download %s: invalid name %q", dir, entry.Name)
+		}
+		local := filepath.Join(localDir, entry.Name)
+		if entry.Type == FilerDir {
+			err = f.downloadDir(ctx, entry.Path, local, progress, total)
+		} else {
+			err = f.downloadFile(ctx, entry.Path, local, progress, total)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Filer) downloadFile(ctx context.Context, p, local string, progress func(TransferProgress), total *int64) error {
+	file, err := os.Create(local)
+	if err != nil {
+		return err
+	}
+	_, err = f.download(ctx, p, file, local, progress, total)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// walk calls fn for each entry of the tree of a directory of the volume, depth-first and in
+// the order of their names. If fn returns fs.SkipDir for a directory, its entries are
+// skipped.
+func (f *Filer) walk(ctx context.Context, dir string, fn func(api.FilerDirEntry) error) error {
+	entries, err := f.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err := fn(entry)
+		if err == fs.SkipDir && entry.Type == FilerDir {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if entry.Type == FilerDir {
+			if err := f.walk(ctx, entry.Path, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// progressReader reports the progress of the transfer of a file as it's read.
+type progressReader struct {
+	r        io.Reader
+	path     string
+	size     int64
+	read     int64
+	total    *int64
+	progress func(TransferProgress)
+}
+
+func (p *progressReader) Read(data []byte) (int, error) {
+	n, err := p.r.Read(data)
+	if n > 0 {
+		p.read += int64(n)
+		*p.total += int64(n)
+		p.progress(TransferProgress{Path: p.path, Bytes: p.read, Size: p.size, Total: *p.total})
+	}
+	return n, err
+}
@@ -0,0 +1,148 @@
+package volumes
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+	"github.com/drycc/controller-sdk-go/drycctest"
+)
+
+func newFilerServer() *drycctest.Server {
+	srv := drycctest.NewServer()
+	srv.AddApp(api.App{ID: "example-go"})
+	srv.AddVolume("example-go", api.Volume{Name: "myvolume", Size: "500G"})
+	return srv
+}
+
+func TestFiler(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+
+	if err := f.MkdirAll(ctx, "data/logs"); err != nil {
+		t.Fatal(err)
+	}
+	var progress []TransferProgress
+	opts := TransferOptions{Progress: func(p TransferProgress) { progress = append(progress, p) }}
+	content := "hello world"
+	if err := f.Upload(ctx, "data/hello world.txt", strings.NewReader(content), int64(len(content)), opts); err != nil {
+		t.Fatal(err)
+	}
+	if last := progress[len(progress)-1]; last != (TransferProgress{Path: "/data/hello world.txt", Bytes: 11, Size: 11, Total: 11}) {
+		t.Errorf("Expected the upload to be complete, Got %+v", last)
+	}
+
+	entries, err := f.List(ctx, "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Type+" "+e.Path+" "+e.Name+" "+e.Size)
+		if e.Timestamp == "" {
+			t.Errorf("Expected a timestamp for %s", e.Path)
+		}
+	}
+	expected := []string{"file /data/hello world.txt hello world.txt 11", "dir /data/logs logs "}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Expected %v, Got %v", expected, got)
+	}
+
+	entry, err := f.Stat(ctx, "data/hello world.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Type != FilerFile || entry.Size != "11" {
+		t.Errorf("Expected a file of 11 bytes, Got %+v", entry)
+	}
+
+	if err := f.Move(ctx, "data/hello world.txt", "data/logs/hello.txt", false); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := f.Download(ctx, "data/logs/hello.txt", &buf, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != content {
+		t.Errorf("Expected %q, Got %q", content, buf.String())
+	}
+
+	if err := f.Delete(ctx, "data/logs"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Stat(ctx, "data/logs/hello.txt")
+	if !errors.As(err, new(drycc.ErrNotFound)) {
+		t.Errorf("Expected a not found error, Got %v", err)
+	}
+	if err := f.Mkdir(ctx, "missing/dir"); err == nil {
+		t.Error("Expected an error for a missing parent")
+	}
+}
+
+func TestFilerDirs(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+
+	src := t.TempDir()
+	files := map[string]string{
+		"index.html":         "<html></html>",
+		"static/app.js":      "console.log(1)",
+		"static/css/app.css": "body {}",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var total int64
+	opts := TransferOptions{Progress: func(p TransferProgress) { total = p.Total }}
+	if err := f.UploadDir(ctx, src, "/www", opts); err != nil {
+		t.Fatal(err)
+	}
+	if total != 34 {
+		t.Errorf("Expected 34 bytes to be uploaded, Got %d", total)
+	}
+	file, err := srv.FilerFS("example-go", "myvolume").OpenFile(ctx, "/www/static/css/app.css", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(file)
+	file.Close()
+	if string(data) != "body {}" {
+		t.Errorf("Expected %q, Got %q", "body {}", data)
+	}
+
+	dst := t.TempDir()
+	if err := f.DownloadDir(ctx, "/www", dst, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("Expected %q, Got %q", content, data)
+		}
+	}
+}
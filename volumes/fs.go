@@ -0,0 +1,396 @@
+package volumes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+var errNotDir = errors.New("not a directory")
+
+// Check that FS implements the optional interfaces of the fs package.
+var (
+	_ fs.ReadDirFS = (*FS)(nil)
+	_ fs.StatFS    = (*FS)(nil)
+)
+
+// FSOptions configures a [FS].
+type FSOptions struct {
+	// CacheTTL is how long the listings of directories are cached. Defaults to 30s; a
+	// negative value disables the cache.
+	CacheTTL time.Duration
+}
+
+// FS is a read-only [fs.FS] of a volume, served by its filer, such as for [fs.WalkDir],
+// template.ParseFS or http.FileServerFS. The content of files is only requested when
+// they are read.
+//
+// Listings of directories are cached, and used to stat their entries. Changes made to the
+// volume, such as with a [Filer], are seen once the listings expire or are invalidated
+// with [FS.Invalidate].
+//
+// Errors are [*fs.PathError]s. Not found errors of the filer match [fs.ErrNotExist], and
+// authorization errors match [fs.ErrPermission]; the [*drycc.APIError] is kept in the chain.
+type FS struct {
+	ctx   context.Context
+	filer *Filer
+	ttl   time.Duration
+
+	mu   sync.Mutex
+	dirs map[string]cachedDir
+}
+
+// cachedDir is the listing of a directory.
+type cachedDir struct {
+	self    api.FilerDirEntry
+	entries api.FilerDirEntries
+	expires time.Time
+}
+
+// NewFS returns a [FS] of the volume of a filer. ctx is used for the requests to the filer.
+func NewFS(ctx context.Context, f *Filer, opts FSOptions) *FS {
+	if opts.CacheTTL == 0 {
+		opts.CacheTTL = 30 * time.Second
+	}
+	return &FS{ctx: ctx, filer: f, ttl: opts.CacheTTL, dirs: make(map[string]cachedDir)}
+}
+
+// Invalidate drops the cached listings of a directory and of the directories below it.
+// Invalidate(".") drops all the listings.
+func (fsys *FS) Invalidate(dir string) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	for name := range fsys.dirs {
+		if dir == "." || name == dir || strings.HasPrefix(name, dir+"/") {
+			delete(fsys.dirs, name)
+		}
+	}
+}
+
+// cached returns the listing of a directory, if cached.
+func (fsys *FS) cached(dir string) (cachedDir, bool) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	d, ok := fsys.dirs[dir]
+	if ok && time.Now().After(d.expires) {
+		delete(fsys.dirs, dir)
+		return cachedDir{}, false
+	}
+	return d, ok
+}
+
+// list returns the listing of a directory, from the cache if possible.
+func (fsys *FS) list(op, dir string) (cachedDir, error) {
+	if d, ok := fsys.cached(dir); ok {
+		return d, nil
+	}
+	entries, err := fsys.filer.propfind(fsys.ctx, dir, 1)
+	if err != nil {
+		return cachedDir{}, pathError(op, dir, err)
+	}
+	var d cachedDir
+	for _, entry := range entries {
+		if entry.Path == cleanPath(dir) {
+			d.self = entry
+		} else {
+			d.entries = append(d.entries, entry)
+		}
+	}
+	sortEntries(d.entries)
+	if d.self.Type != FilerDir {
+		return cachedDir{}, &fs.PathError{Op: op, Path: dir, Err: errNotDir}
+	}
+	if fsys.ttl > 0 {
+		d.expires = time.Now().Add(fsys.ttl)
+		fsys.mu.Lock()
+		fsys.dirs[dir] = d
+		fsys.mu.Unlock()
+	}
+	return d, nil
+}
+
+// stat returns the entry of a file or directory, from the cached listings if possible.
+func (fsys *FS) stat(op, name string) (fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return fileInfo{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if d, ok := fsys.cached(name); ok {
+		return newFileInfo(name, d.self), nil
+	}
+	if name != "." {
+		if d, ok := fsys.cached(path.Dir(name)); ok {
+			for _, entry := range d.entries {
+				if entry.Name == path.Base(name) {
+					return newFileInfo(name, entry), nil
+				}
+			}
+			return fileInfo{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	entry, err := fsys.filer.Stat(fsys.ctx, name)
+	if err != nil {
+		return fileInfo{}, pathError(op, name, err)
+	}
+	return newFileInfo(name, entry), nil
+}
+
+// Open opens a file or a directory of the volume. Directories implement [fs.ReadDirFile]
+// and files implement [io.Seeker].
+func (fsys *FS) Open(name string) (fs.File, error) {
+	info, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &dirFile{fsys: fsys, name: name, info: info}, nil
+	}
+	return &file{fsys: fsys, name: name, info: info}, nil
+}
+
+// Stat returns the [fs.FileInfo] of a file or directory of the volume. Its Sys method
+// returns the [api.FilerDirEntry].
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	info, err := fsys.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ReadDir reads a directory of the volume, and returns its entries sorted by name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	d, err := fsys.list("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, len(d.entries))
+	for i, entry := range d.entries {
+		entries[i] = fs.FileInfoToDirEntry(newFileInfo(path.Join(name, entry.Name), entry))
+	}
+	return entries, nil
+}
+
+// fileError is an error of the filer matching an error of the fs package.
+type fileError struct {
+	kind error
+	err  error
+}
+
+func (e *fileError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fileError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// pathError returns an error of the filer as a [*fs.PathError].
+func pathError(op, name string, err error) error {
+	var apiErr *drycc.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case 404, 410:
+			err = &fileError{kind: fs.ErrNotExist, err: err}
+		case 401, 403:
+			err = &fileError{kind: fs.ErrPermission, err: err}
+		case 400:
+			err = &fileError{kind: fs.ErrInvalid, err: err}
+		}
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// fileInfo is the [fs.FileInfo] of an entry of the filer.
+type fileInfo struct {
+	name    string
+	entry   api.FilerDirEntry
+	size    int64
+	modTime time.Time
+}
+
+func newFileInfo(name string, entry api.FilerDirEntry) fileInfo {
+	info := fileInfo{name: path.Base(name), entry: entry}
+	info.size, _ = strconv.ParseInt(entry.Size, 10, 64)
+	info.modTime, _ = time.Parse(time.RFC3339, entry.Timestamp)
+	return info
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.entry.Type == FilerDir }
+func (i fileInfo) Sys() any           { return i.entry }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.IsDir() {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+// file is a file of a [FS]. Its content is requested on the first read, and again from the
+// new offset after a seek.
+type file struct {
+	fsys   *FS
+	name   string
+	info   fileInfo
+	body   io.ReadCloser
+	offset int64
+	closed bool
+}
+
+// Stat returns the info of the file. If the filer didn't report its size, the content is
+// read to learn it, so that [net/http.FileServerFS] serves the whole file.
+func (f *file) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.name, Err: fs.ErrClosed}
+	}
+	if _, err := f.size(); err != nil {
+		return nil, pathError("stat", f.name, err)
+	}
+	return f.info, nil
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrClosed}
+	}
+	if f.body == nil {
+		// The size is unknown if the filer didn't report it.
+		if f.info.entry.Size != "" && f.offset >= f.info.size {
+			return 0, io.EOF
+		}
+		body, err := f.fsys.filer.openAt(f.fsys.ctx, f.name, f.offset)
+		if err != nil {
+			return 0, pathError("read", f.name, err)
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	if err != nil && err != io.EOF {
+		err = &fs.PathError{Op: "read", Path: f.name, Err: err}
+	}
+	return n, err
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		size, err := f.size()
+		if err != nil {
+			return 0, pathError("seek", f.name, err)
+		}
+		offset += size
+	case io.SeekStart:
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// size returns the size of the file. If the filer didn't report it, the content is read
+// to learn it.
+func (f *file) size() (int64, error) {
+	if f.info.entry.Size != "" {
+		return f.info.size, nil
+	}
+	body, err := f.fsys.filer.openAt(f.fsys.ctx, f.name, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	n, err := io.Copy(io.Discard, body)
+	if err != nil {
+		return 0, err
+	}
+	f.info.size, f.info.entry.Size = n, strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+func (f *file) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.body != nil {
+		return f.body.Close()
+	}
+	return nil
+}
+
+// dirFile is a directory of a [FS]. It's listed on the first call to ReadDir.
+type dirFile struct {
+	fsys    *FS
+	name    string
+	info    fileInfo
+	entries []fs.DirEntry
+	read    bool
+	closed  bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: fs.ErrClosed}
+	}
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (d *dirFile) Close() error {
+	if d.closed {
+		return &fs.PathError{Op: "close", Path: d.name, Err: fs.ErrClosed}
+	}
+	d.closed = true
+	return nil
+}
@@ -0,0 +1,170 @@
+package volumes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	drycc "github.com/drycc/controller-sdk-go"
+)
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+	for name, content := range map[string]string{
+		"index.html":         "<h1>hello</h1>",
+		"static/app.js":      "console.log(1)",
+		"static/css/app.css": "body {}",
+	} {
+		if err := f.MkdirAll(ctx, path.Dir(name)); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Upload(ctx, name, strings.NewReader(content), int64(len(content)), TransferOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fsys := NewFS(ctx, f, FSOptions{})
+	if err := fstest.TestFS(fsys, "index.html", "static/app.js", "static/css/app.css"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(fsys, "static/css/app.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "body {}" {
+		t.Errorf("Expected %q, Got %q", "body {}", data)
+	}
+
+	// Range requests seek in the file.
+	handler := http.FileServerFS(fsys)
+	req := httptest.NewRequest(http.MethodGet, "/static/app.js", nil)
+	req.Header.Set("Range", "bytes=8-")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "log(1)" {
+		t.Errorf("Expected %q, Got %d %q", "log(1)", w.Code, w.Body.String())
+	}
+
+	_, err = fsys.Stat("missing.txt")
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Path != "missing.txt" || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected a not exist error, Got %v", err)
+	}
+	if _, err := fsys.ReadDir("index.html"); err == nil {
+		t.Error("Expected an error reading a file as a directory")
+	}
+	if _, err := fsys.Open("../index.html"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("Expected an invalid path error, Got %v", err)
+	}
+}
+
+func TestFSCache(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+	fsys := NewFS(ctx, f, FSOptions{})
+
+	if entries, err := fsys.ReadDir("."); err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty volume, Got %v, %v", entries, err)
+	}
+	if err := f.Upload(ctx, "new.txt", strings.NewReader("new"), 3, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fsys.Stat("new.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the cached listing to be used, Got %v", err)
+	}
+	fsys.Invalidate(".")
+	if _, err := fsys.Stat("new.txt"); err != nil {
+		t.Errorf("Expected the file after invalidation, Got %v", err)
+	}
+
+	// Errors of the filer are mapped.
+	if err := srv.FilerFS("example-go", "myvolume").RemoveAll(ctx, "/new.txt"); err != nil {
+		t.Fatal(err)
+	}
+	fsys = NewFS(ctx, f, FSOptions{CacheTTL: -1})
+	_, err := fsys.Open("new.txt")
+	if !errors.Is(err, fs.ErrNotExist) || !errors.As(err, new(drycc.ErrNotFound)) {
+		t.Errorf("Expected a not exist error, Got %v", err)
+	}
+
+	file, err := srv.FilerFS("example-go", "myvolume").OpenFile(ctx, "/late.txt", os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(file, "late")
+	file.Close()
+	if _, err := fsys.Stat("late.txt"); err != nil {
+		t.Errorf("Expected no caching, Got %v", err)
+	}
+}
+
+func TestFSUnknownSize(t *testing.T) {
+	t.Parallel()
+
+	// The filer doesn't report the content length of the file.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("DRYCC_API_VERSION", drycc.APIVersion)
+		switch req.Method {
+		case "PROPFIND":
+			w.WriteHeader(http.StatusMultiStatus)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?>
+<D:multistatus xmlns:D="DAV:"><D:response>
+<D:href>/v2/apps/example-go/volumes/myvolume/filer/webdav/stream.log</D:href>
+<D:propstat><D:prop><D:resourcetype/></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>
+</D:response></D:multistatus>`)
+		case http.MethodGet:
+			// The content is chunked.
+			io.WriteString(w, "stream")
+			w.(http.Flusher).Flush()
+			io.WriteString(w, "ed")
+		}
+	}))
+	defer server.Close()
+	client, err := drycc.New(false, server.URL, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := NewFS(context.Background(), NewFiler(client, "example-go", "myvolume"), FSOptions{})
+	data, err := fs.ReadFile(fsys, "stream.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "streamed" {
+		t.Errorf("Expected %q, Got %q", "streamed", data)
+	}
+
+	f, err := fsys.Open("stream.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if size, err := f.(io.Seeker).Seek(0, io.SeekEnd); err != nil || size != 8 {
+		t.Errorf("Expected %d, Got %d %v", 8, size, err)
+	}
+
+	// The file server serves as many bytes as the size of the file.
+	w := httptest.NewRecorder()
+	http.FileServerFS(fsys).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream.log", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "streamed" {
+		t.Errorf("Expected %q, Got %d %q", "streamed", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,201 @@
+package volumes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+)
+
+// ErrSessionClosed is the error of a [ServeSession] ended by [ServeSession.Close].
+var ErrSessionClosed = errors.New("serve session closed")
+
+// ServeOptions configures a [ServeSession].
+type ServeOptions struct {
+	// KeepAlive is the interval between the pings of the filer. Defaults to 30s.
+	KeepAlive time.Duration
+	// MaxRetries is the number of consecutive failed attempts to bind the filer again, after
+	// a transient failure, before the session ends. Defaults to 3.
+	MaxRetries int
+	// OnRebind, if not nil, is called when the filer is bound again, with the error of the
+	// ping that failed.
+	OnRebind func(err error)
+}
+
+// ServeSession serves the filer of an app's volume, such as for a [Filer]. It pings the
+// filer to keep it bound, and binds it again if the ping fails with a transient error,
+// such as a server error or an expired binding. The session ends when the parent context
+// is done, when the filer can't be bound, or when it's closed.
+type ServeSession struct {
+	c        *drycc.Client
+	appID    string
+	name     string
+	basePath string
+	opts     ServeOptions
+
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+
+	mu    sync.Mutex
+	filer map[string]string
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewServeSession binds the filer of an app's volume, and serves it until the session is
+// closed or ctx is done.
+func NewServeSession(ctx context.Context, c *drycc.Client, appID, name string, opts ServeOptions) (*ServeSession, error) {
+	if opts.KeepAlive <= 0 {
+		opts.KeepAlive = 30 * time.Second
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 3
+	}
+	s := &ServeSession{
+		c:        c,
+		appID:    appID,
+		name:     name,
+		basePath: fmt.Sprintf("/v2/apps/%s/volumes/%s/filer", appID, name),
+		opts:     opts,
+		parent:   ctx,
+		done:     make(chan struct{}),
+	}
+	if err := s.bind(ctx); err != nil {
+		return nil, err
+	}
+	s.ctx, s.cancel = context.WithCancelCause(ctx)
+	go s.run()
+	return s, nil
+}
+
+// Filer returns the endpoint of the WebDAV filer, and the credentials of the binding.
+func (s *ServeSession) Filer() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.filer)
+}
+
+// Context returns a context that's canceled when the session ends. Its cause is the error
+// of the session.
+func (s *ServeSession) Context() context.Context {
+	return s.ctx
+}
+
+// Done returns a channel that's closed when the session has ended.
+func (s *ServeSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns nil while the session is serving, and why it ended after that:
+// [ErrSessionClosed] if it was closed, the error of the parent context if it's done, or
+// the error of the last attempt to bind the filer.
+func (s *ServeSession) Err() error {
+	select {
+	case <-s.done:
+		return context.Cause(s.ctx)
+	default:
+		return nil
+	}
+}
+
+// Close ends the session, if it's serving, and unbinds the filer. The filer is unbound even
+// if the parent context is done.
+func (s *ServeSession) Close() error {
+	s.closeOnce.Do(func() {
+		s.cancel(ErrSessionClosed)
+		<-s.done
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(s.parent), 30*time.Second)
+		defer cancel()
+		res, err := s.c.RequestWithContext(ctx, "POST", s.basePath+"/_/unbind", nil)
+		if err != nil && !drycc.IsErrAPIMismatch(err) {
+			s.closeErr = fmt.Errorf("unbinding volume %s of %s: %w", s.name, s.appID, err)
+			return
+		}
+		s.closeErr = res.Body.Close()
+	})
+	return s.closeErr
+}
+
+func (s *ServeSession) bind(ctx context.Context) error {
+	res, err := s.c.RequestWithContext(ctx, "POST", s.basePath+"/_/bind", nil)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return err
+	}
+	defer res.Body.Close()
+	var filer map[string]string
+	if err := json.NewDecoder(res.Body).Decode(&filer); err != nil {
+		return err
+	}
+	filer["endpoint"] = fmt.Sprintf("%s%s/webdav/", s.c.ControllerURL, s.basePath)
+	s.mu.Lock()
+	s.filer = filer
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *ServeSession) ping(ctx context.Context) error {
+	res, err := s.c.RequestWithContext(ctx, "GET", s.basePath+"/_/ping", nil)
+	if err != nil && !drycc.IsErrAPIMismatch(err) {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// run pings the filer until the session ends, binding it again after transient failures.
+func (s *ServeSession) run() {
+	defer close(s.done)
+	timer := time.NewTimer(s.opts.KeepAlive)
+	defer timer.Stop()
+	failures := 0
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-timer.C:
+		}
+		err := s.ping(s.ctx)
+		if err != nil && s.ctx.Err() == nil && !errors.Is(err, drycc.ErrUnauthorized) && !errors.Is(err, drycc.ErrForbidden) {
+			// The binding may have expired, or the filer restarted.
+			pingErr := err
+			if err = s.bind(s.ctx); err == nil && s.opts.OnRebind != nil {
+				s.opts.OnRebind(pingErr)
+			}
+		}
+		switch {
+		case s.ctx.Err() != nil:
+			return
+		case err == nil:
+			failures = 0
+			timer.Reset(s.opts.KeepAlive)
+		case permanent(err) || failures >= s.opts.MaxRetries:
+			s.cancel(fmt.Errorf("serving volume %s of %s: %w", s.name, s.appID, err))
+			return
+		default:
+			failures++
+			timer.Reset(min(s.opts.KeepAlive, time.Second<<failures))
+		}
+	}
+}
+
+// permanent reports whether binding the filer can't succeed by trying again, such as when
+// the volume doesn't exist or the token isn't valid.
+func permanent(err error) bool {
+	var apiErr *drycc.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode < 500
+}
@@ -0,0 +1,107 @@
+package volumes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+func TestServeSession(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	rebound := make(chan error, 1)
+	s, err := NewServeSession(context.Background(), srv.Client(), "example-go", "myvolume", ServeOptions{
+		KeepAlive: time.Millisecond,
+		OnRebind:  func(err error) { rebound <- err },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filer := s.Filer(); filer["username"] != "myvolume" || filer["endpoint"] != srv.URL+"/v2/apps/example-go/volumes/myvolume/filer/webdav/" {
+		t.Errorf("Expected a bound filer, Got %v", filer)
+	}
+	if !srv.FilerBound("example-go", "myvolume") {
+		t.Error("Expected the filer to be bound")
+	}
+
+	// The binding expires.
+	srv.UnbindFiler("example-go", "myvolume")
+	select {
+	case err := <-rebound:
+		if !errors.As(err, new(drycc.ErrNotFound)) {
+			t.Errorf("Expected the ping to fail with a not found error, Got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the filer to be bound again")
+	}
+	if !srv.FilerBound("example-go", "myvolume") || s.Err() != nil {
+		t.Errorf("Expected the session to keep serving, Got %v", s.Err())
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(s.Err(), ErrSessionClosed) || s.Context().Err() == nil {
+		t.Errorf("Expected the session to be closed, Got %v", s.Err())
+	}
+	if srv.FilerBound("example-go", "myvolume") {
+		t.Error("Expected the filer to be unbound")
+	}
+}
+
+func TestServeSessionEnd(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	client := srv.Client()
+	s, err := NewServeSession(context.Background(), client, "example-go", "myvolume", ServeOptions{KeepAlive: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := Delete(client, "example-go", "myvolume"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the session to end")
+	}
+	if !errors.As(s.Err(), new(drycc.ErrNotFound)) {
+		t.Errorf("Expected the session to end with a not found error, Got %v", s.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv.AddVolume("example-go", api.Volume{Name: "myvolume", Size: "500G"})
+	s, err = NewServeSession(ctx, client, "example-go", "myvolume", ServeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-s.Done()
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("Expected %v, Got %v", context.Canceled, s.Err())
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewServeSession(context.Background(), client, "example-go", "missing", ServeOptions{}); err == nil {
+		t.Error("Expected an error binding a missing volume")
+	}
+
+	// The filer of an older controller is served too.
+	srv.SetAPIVersion("2.2")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if _, filer, err := Serve(ctx, client, "example-go", "myvolume"); err != nil || filer["endpoint"] == "" {
+		t.Errorf("Expected the filer to be served, Got %v, %v", filer, err)
+	}
+}
@@ -0,0 +1,297 @@
+package volumes
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	drycc "github.com/drycc/controller-sdk-go"
+	"github.com/drycc/controller-sdk-go/api"
+)
+
+// The actions of a sync.
+const (
+	SyncMkdir  = "mkdir"
+	SyncUpload = "upload"
+	SyncDelete = "delete"
+)
+
+// SyncOptions configures [Filer.Sync].
+type SyncOptions struct {
+	// Checksum compares the SHA-256 checksums of the files of the same size, instead of
+	// their timestamps. The remote files are downloaded to compute their checksums.
+	Checksum bool
+	// Delete deletes the remote files and directories that don't exist locally.
+	Delete bool
+	// Exclude are patterns, in the syntax of [path.Match], of the files and directories
+	// that aren't synced or deleted. A pattern matches the slash-separated path relative to
+	// the synced directories, or the base name.
+	Exclude []string
+	// DryRun plans the sync without changing the volume.
+	DryRun bool
+	// Progress, if not nil, is called as the files are uploaded.
+	Progress func(TransferProgress)
+}
+
+// SyncOp is an operation of a sync.
+type SyncOp struct {
+	Action string
+	// Path is the remote path.
+	Path string
+	// Local is the local path of the uploaded file, and Size its size.
+	Local string
+	Size  int64
+	// Reason is why the operation is needed: "missing", "size", "modified", "checksum",
+	// "type" if a file replaces a directory or the other way around, or "extraneous" for
+	// deletions of the remote entries that don't exist locally.
+	Reason string
+}
+
+// SyncPlan is the list of the operations of a sync, in order.
+type SyncPlan struct {
+	Ops []SyncOp
+}
+
+// Bytes returns the number of bytes uploaded by the plan.
+func (p *SyncPlan) Bytes() int64 {
+	var n int64
+	for _, op := range p.Ops {
+		if op.Action == SyncUpload {
+			n += op.Size
+		}
+	}
+	return n
+}
+
+// String returns the plan with an operation per line, such as "upload /static/app.js (size)".
+func (p *SyncPlan) String() string {
+	var b strings.Builder
+	for _, op := range p.Ops {
+		fmt.Fprintf(&b, "%s %s (%s)\n", op.Action, op.Path, op.Reason)
+	}
+	return b.String()
+}
+
+// Sync makes a directory of the volume match a local directory tree, like rsync. The
+// local files are uploaded if they're missing from the volume, if their size differs, or
+// if they were modified after the remote files; with Checksum, files of the same size are
+// compared by checksum instead. Symbolic links and special files are skipped.
+//
+// The plan of the sync is returned, even if the sync fails; its operations are applied in
+// order unless DryRun is set.
+func (f *Filer) Sync(ctx context.Context, localDir, dir string, opts SyncOptions) (*SyncPlan, error) {
+	for _, pattern := range opts.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+	excluded := func(rel string) bool {
+		for _, pattern := range opts.Exclude {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+		return false
+	}
+	dir = cleanPath(dir)
+	remotePath := func(rel string) string {
+		return path.Join(dir, rel)
+	}
+
+	remote := map[string]api.FilerDirEntry{}
+	var remoteOrder []string
+	err := f.walk(ctx, dir, func(entry api.FilerDirEntry) error {
+		rel := strings.TrimPrefix(strings.TrimPrefix(entry.Path, dir), "/")
+		switch {
+		case excluded(rel) && entry.Type == FilerDir:
+			return fs.SkipDir
+		case excluded(rel):
+			return nil
+		}
+		remote[rel] = entry
+		remoteOrder = append(remoteOrder, rel)
+		return nil
+	})
+	exists := true
+	if errors.As(err, new(drycc.ErrNotFound)) {
+		exists, err = false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{}
+	// deleted are the remote directories deleted by the plan.
+	var deleted []string
+	if !exists {
+		plan.Ops = append(plan.Ops, SyncOp{Action: SyncMkdir, Path: dir, Reason: "missing"})
+	}
+	seen := map[string]bool{}
+	err = filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if excluded(rel) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil
+		}
+		seen[rel] = true
+		entry, ok := remote[rel]
+		if d.IsDir() {
+			switch {
+			case !ok:
+				plan.Ops = append(plan.Ops, SyncOp{Action: SyncMkdir, Path: remotePath(rel), Reason: "missing"})
+			case entry.Type != FilerDir:
+				plan.Ops = append(plan.Ops,
+					SyncOp{Action: SyncDelete, Path: entry.Path, Reason: "type"},
+					SyncOp{Action: SyncMkdir, Path: entry.Path, Reason: "type"},
+				)
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		op := SyncOp{Action: SyncUpload, Path: remotePath(rel), Local: p, Size: info.Size()}
+		switch {
+		case !ok:
+			op.Reason = "missing"
+		case entry.Type == FilerDir:
+			op.Reason = "type"
+			deleted = append(deleted, rel)
+			plan.Ops = append(plan.Ops, SyncOp{Action: SyncDelete, Path: entry.Path, Reason: "type"})
+		default:
+			if op.Reason, err = f.compare(ctx, entry, p, info, opts.Checksum); err != nil {
+				return err
+			}
+		}
+		if op.Reason != "" {
+			plan.Ops = append(plan.Ops, op)
+		}
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	if opts.Delete {
+		for _, rel := range remoteOrder {
+			if seen[rel] || underAny(rel, deleted) {
+				continue
+			}
+			deleted = append(deleted, rel)
+			plan.Ops = append(plan.Ops, SyncOp{Action: SyncDelete, Path: remote[rel].Path, Reason: "extraneous"})
+		}
+	}
+	if opts.DryRun {
+		return plan, nil
+	}
+	return plan, f.apply(ctx, plan, opts.Progress)
+}
+
+// compare returns why a local file differs from a remote file, or "" if it doesn't.
+func (f *Filer) compare(ctx context.Context, entry api.FilerDirEntry, local string, info fs.FileInfo, checksum bool) (string, error) {
+	if size, _ := strconv.ParseInt(entry.Size, 10, 64); size != info.Size() {
+		return "size", nil
+	}
+	if !checksum {
+		modified, _ := time.Parse(time.RFC3339, entry.Timestamp)
+		if info.ModTime().Truncate(time.Second).After(modified) {
+			return "modified", nil
+		}
+		return "", nil
+	}
+	localSum, err := sumFile(local)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	var total int64
+	if _, err := f.download(ctx, entry.Path, h, entry.Path, nil, &total); err != nil {
+		return "", err
+	}
+	if string(h.Sum(nil)) != string(localSum) {
+		return "checksum", nil
+	}
+	return "", nil
+}
+
+// apply applies the operations of a plan.
+func (f *Filer) apply(ctx context.Context, plan *SyncPlan, progress func(TransferProgress)) error {
+	var total int64
+	for _, op := range plan.Ops {
+		var err error
+		switch op.Action {
+		case SyncMkdir:
+			err = f.MkdirAll(ctx, op.Path)
+		case SyncDelete:
+			err = f.Delete(ctx, op.Path)
+		case SyncUpload:
+			err = f.uploadFile(ctx, op.Local, op.Path, progress, &total)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Filer) uploadFile(ctx context.Context, local, p string, progress func(TransferProgress), total *int64) error {
+	file, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return f.upload(ctx, p, file, info.Size(), progress, total)
+}
+
+// sumFile returns the SHA-256 checksum of a local file.
+func sumFile(name string) ([]byte, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// underAny reports whether a slash-separated path is below one of dirs.
+func underAny(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package volumes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+
+	local := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	writeFiles(t, local, map[string]string{
+		"index.html":      "<h1>v1</h1>",
+		"static/app.js":   "console.log(1)",
+		"static/app.map":  "{}",
+		"models/big.bin":  strings.Repeat("0", 1000),
+		"node_modules/x":  "x",
+		"static/logo.svg": "<svg/>",
+	})
+	for _, name := range []string{"index.html", "static/app.js", "static/logo.svg", "models/big.bin"} {
+		if err := os.Chtimes(filepath.Join(local, filepath.FromSlash(name)), past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := SyncOptions{Exclude: []string{"*.map", "node_modules"}, Delete: true}
+	plan, err := f.Sync(ctx, local, "/www", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `mkdir /www (missing)
+upload /www/index.html (missing)
+mkdir /www/models (missing)
+upload /www/models/big.bin (missing)
+mkdir /www/static (missing)
+upload /www/static/app.js (missing)
+upload /www/static/logo.svg (missing)
+`
+	if plan.String() != expected {
+		t.Errorf("Expected %q, Got %q", expected, plan.String())
+	}
+	if plan.Bytes() != 1031 {
+		t.Errorf("Expected 1031 bytes to be uploaded, Got %d", plan.Bytes())
+	}
+
+	// Nothing changed.
+	if plan, err = f.Sync(ctx, local, "/www", opts); err != nil || len(plan.Ops) != 0 {
+		t.Errorf("Expected an empty plan, Got %q, %v", plan, err)
+	}
+
+	writeFiles(t, local, map[string]string{"index.html": "<h1>v2</h1>", "static/app.js": "console.log(22)"})
+	// Timestamps of the filer have a resolution of a second, so the edit is dated later.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(local, "index.html"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(local, "static", "logo.svg"))
+	os.RemoveAll(filepath.Join(local, "models"))
+	if err := f.Upload(ctx, "www/extra.txt", strings.NewReader("extra"), 5, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Upload(ctx, "www/static/app.map", strings.NewReader("{}"), 2, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	dryRun := opts
+	dryRun.DryRun = true
+	if plan, err = f.Sync(ctx, local, "/www", dryRun); err != nil {
+		t.Fatal(err)
+	}
+	expected = `upload /www/index.html (modified)
+upload /www/static/app.js (size)
+delete /www/extra.txt (extraneous)
+delete /www/models (extraneous)
+delete /www/static/logo.svg (extraneous)
+`
+	if plan.String() != expected {
+		t.Errorf("Expected %q, Got %q", expected, plan.String())
+	}
+	if _, err := f.Stat(ctx, "www/extra.txt"); err != nil {
+		t.Errorf("Expected no change in a dry run, Got %v", err)
+	}
+
+	var uploaded []string
+	opts.Progress = func(p TransferProgress) {
+		if p.Bytes == p.Size {
+			uploaded = append(uploaded, p.Path)
+		}
+	}
+	if _, err = f.Sync(ctx, local, "/www", opts); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"/www/index.html", "/www/static/app.js"}; !reflect.DeepEqual(expected, uploaded) {
+		t.Errorf("Expected %v, Got %v", expected, uploaded)
+	}
+	var files []string
+	for name, content := range readVolume(t, srv, "myvolume") {
+		files = append(files, name+"="+content)
+	}
+	if len(files) != 3 || !strings.Contains(strings.Join(files, " "), "www/index.html=<h1>v2</h1>") {
+		t.Errorf("Expected the volume to match the local directory, Got %v", files)
+	}
+}
+
+func TestSyncChecksum(t *testing.T) {
+	t.Parallel()
+
+	srv := newFilerServer()
+	defer srv.Close()
+	ctx := context.Background()
+	f := NewFiler(srv.Client(), "example-go", "myvolume")
+	if err := f.Upload(ctx, "model.bin", strings.NewReader("aaaa"), 4, TransferOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Mkdir(ctx, "config"); err != nil {
+		t.Fatal(err)
+	}
+
+	local := t.TempDir()
+	writeFiles(t, local, map[string]string{"model.bin": "bbbb", "config": "debug: true"})
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(local, "model.bin"), past, past)
+
+	plan, err := f.Sync(ctx, local, "/", SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "delete /config (type)\nupload /config (type)\n"
+	if plan.String() != expected {
+		t.Errorf("Expected %q, Got %q", expected, plan.String())
+	}
+	if plan, err = f.Sync(ctx, local, "/", SyncOptions{Checksum: true}); err != nil {
+		t.Fatal(err)
+	}
+	expected = "delete /config (type)\nupload /config (type)\nupload /model.bin (checksum)\n"
+	if plan.String() != expected {
+		t.Errorf("Expected %q, Got %q", expected, plan.String())
+	}
+	if files := readVolume(t, srv, "myvolume"); !reflect.DeepEqual(files, map[string]string{"model.bin": "bbbb", "config": "debug: true"}) {
+		t.Errorf("Expected the files to be uploaded, Got %v", files)
+	}
+}
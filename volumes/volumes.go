@@ -1,11 +1,16 @@
 // Package volumes provides methods for managing volumes of apps.
+//
+// The files of a volume are managed through a [Filer], whose methods include the transfers
+// of files and directories, and [Filer.Backup] and [Filer.Restore] to archive the whole
+// volume. They are methods of the filer rather than functions of the package, as they share
+// its binding to the app and the volume.
 package volumes
 
 import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -13,8 +18,13 @@ import (
 
 // List list an app's volumes.
 func List(c *drycc.Client, appID string, results int) (api.Volumes, int, error) {
+	return ListWithContext(context.Background(), c, appID, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, appID string, results int) (api.Volumes, int, error) {
 	u := fmt.Sprintf("/v2/apps/%s/volumes/", appID)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Volume{}, -1, reqErr
 	}
@@ -25,10 +35,25 @@ func List(c *drycc.Client, appID string, results int) (api.Volumes, int, error)
 	return volumes, count, reqErr
 }
 
+// All returns an iterator over all volumes of an app, fetching pages lazily.
+func All(c *drycc.Client, appID string) iter.Seq2[api.Volume, error] {
+	return AllWithContext(context.Background(), c, appID)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, appID string) iter.Seq2[api.Volume, error] {
+	return drycc.Paginate[api.Volume](ctx, c, fmt.Sprintf("/v2/apps/%s/volumes/", appID), drycc.DefaultPageSize)
+}
+
 // Get an app's volume.
 func Get(c *drycc.Client, appID string, name string) (api.Volume, error) {
+	return GetWithContext(context.Background(), c, appID, name)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, appID string, name string) (api.Volume, error) {
 	u := fmt.Sprintf("/v2/apps/%s/volumes/%s/", appID, name)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Volume{}, reqErr
 	}
@@ -44,12 +69,17 @@ func Get(c *drycc.Client, appID string, name string) (api.Volume, error) {
 
 // Create create an app's Volume.
 func Create(c *drycc.Client, appID string, volume api.Volume) (api.Volume, error) {
+	return CreateWithContext(context.Background(), c, appID, volume)
+}
+
+// CreateWithContext is like [Create] but cancels the request when ctx is done.
+func CreateWithContext(ctx context.Context, c *drycc.Client, appID string, volume api.Volume) (api.Volume, error) {
 	body, err := json.Marshal(volume)
 	if err != nil {
 		return api.Volume{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/volumes/", appID)
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil {
 		return api.Volume{}, reqErr
 	}
@@ -63,12 +93,17 @@ func Create(c *drycc.Client, appID string, volume api.Volume) (api.Volume, error
 
 // Expand create an app's Volume.
 func Expand(c *drycc.Client, appID string, volume api.Volume) (api.Volume, error) {
+	return ExpandWithContext(context.Background(), c, appID, volume)
+}
+
+// ExpandWithContext is like [Expand] but cancels the request when ctx is done.
+func ExpandWithContext(ctx context.Context, c *drycc.Client, appID string, volume api.Volume) (api.Volume, error) {
 	body, err := json.Marshal(volume)
 	if err != nil {
 		return api.Volume{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/volumes/%s/", appID, volume.Name)
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil {
 		return api.Volume{}, reqErr
 	}
@@ -80,45 +115,27 @@ func Expand(c *drycc.Client, appID string, volume api.Volume) (api.Volume, error
 	return newVolume, reqErr
 }
 
-// Serve serves an app's volume.
+// Serve serves an app's volume. It returns the endpoint of the filer with the credentials
+// of the binding, and a context that's canceled when serving ends.
+//
+// Deprecated: Use [NewServeSession], which reports why serving ended and unbinds the filer.
 func Serve(parent context.Context, c *drycc.Client, appID, name string) (context.Context, map[string]string, error) {
-	basePath := fmt.Sprintf("/v2/apps/%s/volumes/%s/filer", appID, name)
-	res, err := c.Request("POST", fmt.Sprintf("%s/_/bind", basePath), nil)
+	s, err := NewServeSession(parent, c, appID, name, ServeOptions{})
 	if err != nil {
 		return nil, nil, err
 	}
-	defer res.Body.Close()
-	var filer map[string]string
-	if err = json.NewDecoder(res.Body).Decode(&filer); err != nil {
-		return nil, nil, err
-	}
-	ctx, cancel := context.WithCancel(parent)
-	go func() {
-		defer cancel()
-		for {
-			select {
-			case <-parent.Done():
-				return
-			default:
-				res, err := c.Request("GET", fmt.Sprintf("%s/_/ping", basePath), nil)
-				if err != nil {
-					return
-				}
-				if err := res.Body.Close(); err != nil {
-					return
-				}
-				time.Sleep(time.Second * 30)
-			}
-		}
-	}()
-	filer["endpoint"] = fmt.Sprintf("%s%s/webdav/", c.ControllerURL, basePath)
-	return ctx, filer, nil
+	return s.Context(), s.Filer(), nil
 }
 
 // Delete delete an app's Volume.
 func Delete(c *drycc.Client, appID string, name string) error {
+	return DeleteWithContext(context.Background(), c, appID, name)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, appID string, name string) error {
 	u := fmt.Sprintf("/v2/apps/%s/volumes/%s/", appID, name)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -136,12 +153,17 @@ func Delete(c *drycc.Client, appID string, name string) error {
 // Calling Mount() with an empty api.Volume will return a drycc.ErrConflict.
 // Trying to Unmount a key that does not exist returns a drycc.ErrUnprocessable.
 func Mount(c *drycc.Client, appID string, name string, volume api.Volume) (api.Volume, error) {
+	return MountWithContext(context.Background(), c, appID, name, volume)
+}
+
+// MountWithContext is like [Mount] but cancels the request when ctx is done.
+func MountWithContext(ctx context.Context, c *drycc.Client, appID string, name string, volume api.Volume) (api.Volume, error) {
 	body, err := json.Marshal(volume)
 	if err != nil {
 		return api.Volume{}, err
 	}
 	u := fmt.Sprintf("/v2/apps/%s/volumes/%s/path/", appID, name)
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil {
 		return api.Volume{}, reqErr
 	}
@@ -0,0 +1,78 @@
+package drycc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebsocketDialer is implemented by transports that open websocket connections
+// themselves. If the transport of a client's HTTPClient is a WebsocketDialer,
+// [Client.DialWebsocket] uses it instead of dialing the controller directly.
+type WebsocketDialer interface {
+	DialWebsocket(ctx context.Context, config *websocket.Config) (*websocket.Conn, error)
+}
+
+// DialWebsocket opens a websocket connection to the given path relative to the controller URL.
+// The connection is authenticated the same way as requests sent with [Client.Do], and the
+// handshake goes through the client's interceptors.
+// The provided ctx only bounds the opening handshake, not the lifetime of the connection.
+func (c *Client) DialWebsocket(ctx context.Context, path string) (*websocket.Conn, error) {
+	scheme := "ws"
+	if c.ControllerURL.Scheme == "https" {
+		scheme = "wss"
+	}
+	endpoint := url.URL{Scheme: scheme, Host: c.ControllerURL.Host, Path: path}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = http.Header{
+		"User-Agent":          {c.UserAgent},
+		"Authorization":       {"token " + c.Token},
+		"X-Drycc-Service-Key": {c.ServiceKey},
+	}
+
+	var conn *websocket.Conn
+	_, err = c.intercept(req, func(req *http.Request) (*http.Response, error) {
+		config, err := websocket.NewConfig(req.URL.String(), c.ControllerURL.String())
+		if err != nil {
+			return nil, err
+		}
+		config.Header = req.Header
+		if transport, ok := c.HTTPClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			config.TlsConfig = transport.TLSClientConfig.Clone()
+		}
+		if dialer, ok := c.HTTPClient.Transport.(WebsocketDialer); ok {
+			conn, err = dialer.DialWebsocket(req.Context(), config)
+		} else {
+			conn, err = config.DialContext(req.Context())
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:     "101 Switching Protocols",
+			StatusCode: http.StatusSwitchingProtocols,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, err
+	}
+	if conn == nil {
+		return nil, errors.New("websocket handshake was not performed by the interceptors")
+	}
+	return conn, nil
+}
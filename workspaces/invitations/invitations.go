@@ -2,8 +2,10 @@
 package invitations
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // List lists pending invitations in a workspace.
 func List(c *drycc.Client, workspace string, results int) (api.WorkspaceInvitations, int, error) {
+	return ListWithContext(context.Background(), c, workspace, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, workspace string, results int) (api.WorkspaceInvitations, int, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/invitations", workspace)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.WorkspaceInvitation{}, -1, reqErr
@@ -26,8 +33,23 @@ func List(c *drycc.Client, workspace string, results int) (api.WorkspaceInvitati
 	return invitations, count, reqErr
 }
 
+// All returns an iterator over all invitations of a workspace, fetching pages lazily.
+func All(c *drycc.Client, workspace string) iter.Seq2[api.WorkspaceInvitation, error] {
+	return AllWithContext(context.Background(), c, workspace)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, workspace string) iter.Seq2[api.WorkspaceInvitation, error] {
+	return drycc.Paginate[api.WorkspaceInvitation](ctx, c, fmt.Sprintf("/v2/workspaces/%s/invitations", workspace), drycc.DefaultPageSize)
+}
+
 // Create creates a workspace invitation.
 func Create(c *drycc.Client, workspace, email string) (api.WorkspaceInvitation, error) {
+	return CreateWithContext(context.Background(), c, workspace, email)
+}
+
+// CreateWithContext is like [Create] but cancels the request when ctx is done.
+func CreateWithContext(ctx context.Context, c *drycc.Client, workspace, email string) (api.WorkspaceInvitation, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/invitations", workspace)
 	req := api.WorkspaceInvitationCreateRequest{Email: email}
 	body, err := json.Marshal(req)
@@ -35,7 +57,7 @@ func Create(c *drycc.Client, workspace, email string) (api.WorkspaceInvitation,
 		return api.WorkspaceInvitation{}, err
 	}
 
-	res, reqErr := c.Request("POST", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.WorkspaceInvitation{}, reqErr
 	}
@@ -51,8 +73,13 @@ func Create(c *drycc.Client, workspace, email string) (api.WorkspaceInvitation,
 
 // Get fetches an invitation by uid token.
 func Get(c *drycc.Client, workspace, uid string) (api.WorkspaceInvitation, error) {
+	return GetWithContext(context.Background(), c, workspace, uid)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, workspace, uid string) (api.WorkspaceInvitation, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/invitations/%s", workspace, uid)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.WorkspaceInvitation{}, reqErr
 	}
@@ -68,8 +95,13 @@ func Get(c *drycc.Client, workspace, uid string) (api.WorkspaceInvitation, error
 
 // Delete revokes an invitation.
 func Delete(c *drycc.Client, workspace, uid string) error {
+	return DeleteWithContext(context.Background(), c, workspace, uid)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, workspace, uid string) error {
 	u := fmt.Sprintf("/v2/workspaces/%s/invitations/%s", workspace, uid)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
@@ -2,8 +2,10 @@
 package members
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,8 +13,13 @@ import (
 
 // List lists members in a workspace.
 func List(c *drycc.Client, workspace string, results int) (api.WorkspaceMembers, int, error) {
+	return ListWithContext(context.Background(), c, workspace, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, workspace string, results int) (api.WorkspaceMembers, int, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/members", workspace)
-	body, count, reqErr := c.LimitedRequest(u, results)
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, u, results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.WorkspaceMember{}, -1, reqErr
@@ -26,10 +33,25 @@ func List(c *drycc.Client, workspace string, results int) (api.WorkspaceMembers,
 	return members, count, reqErr
 }
 
+// All returns an iterator over all members of a workspace, fetching pages lazily.
+func All(c *drycc.Client, workspace string) iter.Seq2[api.WorkspaceMember, error] {
+	return AllWithContext(context.Background(), c, workspace)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client, workspace string) iter.Seq2[api.WorkspaceMember, error] {
+	return drycc.Paginate[api.WorkspaceMember](ctx, c, fmt.Sprintf("/v2/workspaces/%s/members", workspace), drycc.DefaultPageSize)
+}
+
 // Get fetches a workspace member by username.
 func Get(c *drycc.Client, workspace, user string) (api.WorkspaceMember, error) {
+	return GetWithContext(context.Background(), c, workspace, user)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, workspace, user string) (api.WorkspaceMember, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/members/%s", workspace, user)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.WorkspaceMember{}, reqErr
 	}
@@ -45,6 +67,11 @@ func Get(c *drycc.Client, workspace, user string) (api.WorkspaceMember, error) {
 
 // Update updates a workspace member role/alerts.
 func Update(c *drycc.Client, workspace, user, role string, alerts *bool) (api.WorkspaceMember, error) {
+	return UpdateWithContext(context.Background(), c, workspace, user, role, alerts)
+}
+
+// UpdateWithContext is like [Update] but cancels the request when ctx is done.
+func UpdateWithContext(ctx context.Context, c *drycc.Client, workspace, user, role string, alerts *bool) (api.WorkspaceMember, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s/members/%s", workspace, user)
 	req := api.WorkspaceMemberUpdateRequest{Role: role, Alerts: alerts}
 	body, err := json.Marshal(req)
@@ -52,7 +79,7 @@ func Update(c *drycc.Client, workspace, user, role string, alerts *bool) (api.Wo
 		return api.WorkspaceMember{}, err
 	}
 
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.WorkspaceMember{}, reqErr
 	}
@@ -68,8 +95,13 @@ func Update(c *drycc.Client, workspace, user, role string, alerts *bool) (api.Wo
 
 // Delete removes a workspace member.
 func Delete(c *drycc.Client, workspace, user string) error {
+	return DeleteWithContext(context.Background(), c, workspace, user)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, workspace, user string) error {
 	u := fmt.Sprintf("/v2/workspaces/%s/members/%s", workspace, user)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}
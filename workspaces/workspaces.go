@@ -2,8 +2,10 @@
 package workspaces
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"iter"
 
 	drycc "github.com/drycc/controller-sdk-go"
 	"github.com/drycc/controller-sdk-go/api"
@@ -11,7 +13,12 @@ import (
 
 // List lists workspaces visible to the current user.
 func List(c *drycc.Client, results int) (api.Workspaces, int, error) {
-	body, count, reqErr := c.LimitedRequest("/v2/workspaces", results)
+	return ListWithContext(context.Background(), c, results)
+}
+
+// ListWithContext is like [List] but cancels the request when ctx is done.
+func ListWithContext(ctx context.Context, c *drycc.Client, results int) (api.Workspaces, int, error) {
+	body, count, reqErr := c.LimitedRequestWithContext(ctx, "/v2/workspaces", results)
 
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return []api.Workspace{}, -1, reqErr
@@ -25,15 +32,30 @@ func List(c *drycc.Client, results int) (api.Workspaces, int, error) {
 	return workspaces, count, reqErr
 }
 
+// All returns an iterator over all workspaces of the user, fetching pages lazily.
+func All(c *drycc.Client) iter.Seq2[api.Workspace, error] {
+	return AllWithContext(context.Background(), c)
+}
+
+// AllWithContext is like [All] but cancels the requests when ctx is done.
+func AllWithContext(ctx context.Context, c *drycc.Client) iter.Seq2[api.Workspace, error] {
+	return drycc.Paginate[api.Workspace](ctx, c, "/v2/workspaces", drycc.DefaultPageSize)
+}
+
 // Create creates a workspace.
 func Create(c *drycc.Client, name, email string) (api.Workspace, error) {
+	return CreateWithContext(context.Background(), c, name, email)
+}
+
+// CreateWithContext is like [Create] but cancels the request when ctx is done.
+func CreateWithContext(ctx context.Context, c *drycc.Client, name, email string) (api.Workspace, error) {
 	req := api.WorkspaceCreateRequest{Name: name, Email: email}
 	body, err := json.Marshal(req)
 	if err != nil {
 		return api.Workspace{}, err
 	}
 
-	res, reqErr := c.Request("POST", "/v2/workspaces", body)
+	res, reqErr := c.RequestWithContext(ctx, "POST", "/v2/workspaces", body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Workspace{}, reqErr
 	}
@@ -49,8 +71,13 @@ func Create(c *drycc.Client, name, email string) (api.Workspace, error) {
 
 // Get fetches a workspace by name.
 func Get(c *drycc.Client, name string) (api.Workspace, error) {
+	return GetWithContext(context.Background(), c, name)
+}
+
+// GetWithContext is like [Get] but cancels the request when ctx is done.
+func GetWithContext(ctx context.Context, c *drycc.Client, name string) (api.Workspace, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s", name)
-	res, reqErr := c.Request("GET", u, nil)
+	res, reqErr := c.RequestWithContext(ctx, "GET", u, nil)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Workspace{}, reqErr
 	}
@@ -66,6 +93,11 @@ func Get(c *drycc.Client, name string) (api.Workspace, error) {
 
 // Update updates workspace attributes.
 func Update(c *drycc.Client, name, email string) (api.Workspace, error) {
+	return UpdateWithContext(context.Background(), c, name, email)
+}
+
+// UpdateWithContext is like [Update] but cancels the request when ctx is done.
+func UpdateWithContext(ctx context.Context, c *drycc.Client, name, email string) (api.Workspace, error) {
 	u := fmt.Sprintf("/v2/workspaces/%s", name)
 	req := api.WorkspaceUpdateRequest{Email: email}
 	body, err := json.Marshal(req)
@@ -73,7 +105,7 @@ func Update(c *drycc.Client, name, email string) (api.Workspace, error) {
 		return api.Workspace{}, err
 	}
 
-	res, reqErr := c.Request("PATCH", u, body)
+	res, reqErr := c.RequestWithContext(ctx, "PATCH", u, body)
 	if reqErr != nil && !drycc.IsErrAPIMismatch(reqErr) {
 		return api.Workspace{}, reqErr
 	}
@@ -89,8 +121,13 @@ func Update(c *drycc.Client, name, email string) (api.Workspace, error) {
 
 // Delete removes a workspace.
 func Delete(c *drycc.Client, name string) error {
+	return DeleteWithContext(context.Background(), c, name)
+}
+
+// DeleteWithContext is like [Delete] but cancels the request when ctx is done.
+func DeleteWithContext(ctx context.Context, c *drycc.Client, name string) error {
 	u := fmt.Sprintf("/v2/workspaces/%s", name)
-	res, err := c.Request("DELETE", u, nil)
+	res, err := c.RequestWithContext(ctx, "DELETE", u, nil)
 	if err == nil {
 		res.Body.Close()
 	}